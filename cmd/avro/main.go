@@ -2,10 +2,20 @@ package main
 
 import (
 	"avro_cli/internal/app/executor"
+	"avro_cli/internal/app/favorites"
+	"avro_cli/internal/app/history"
+	"avro_cli/internal/app/plugins"
+	"avro_cli/internal/app/registry"
+	"avro_cli/internal/app/usercmds"
 	"avro_cli/internal/cli"
+	"avro_cli/internal/config"
+	"avro_cli/internal/domain"
+	"avro_cli/internal/infra/cassette"
 	"avro_cli/internal/infra/fs"
+	"avro_cli/internal/infra/logging"
 	"avro_cli/internal/infra/net"
 	"avro_cli/internal/infra/shell"
+	"errors"
 	"fmt"
 	"os"
 
@@ -14,11 +24,95 @@ import (
 )
 
 func main() {
-	exec := executor.New(shell.New(), fs.New(), net.New())
-	root := cli.NewRootCommand(exec)
+	// Invalid settings fall back to defaults; say so rather than refuse to start.
+	cfg, cfgErr := config.Load()
+	level, _ := logging.ParseLevel(cfg.LogLevel)
+	logs, err := logging.Setup(level)
 
-	if err := root.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	warn := func(err error) {
+		fmt.Fprintln(os.Stderr, "avro: warning:", err)
+		logs.Logger.Warn("startup", "error", err)
+	}
+	if err != nil {
+		warn(fmt.Errorf("log file disabled: %w", err))
+	}
+	if cfgErr != nil {
+		warn(cfgErr)
+	}
+
+	// AVRO_FS swaps the file system commands see through ctx.FS for e.g. a
+	// read-only or copy-on-write view, to try commands that write files
+	// without touching them. Processes would reach the disk directly, so
+	// everything run through ctx.Shell (git, plugins, command files) is
+	// refused under any view but the local disk.
+	files, err := fs.Parse(os.Getenv("AVRO_FS"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "avro: AVRO_FS:", err)
+		os.Exit(1)
+	}
+
+	// Plugins and command files register before the cobra tree and TUI read the registry.
+	errs := plugins.Load(registry.Global())
+	errs = append(errs, usercmds.Load(registry.Global(), files)...)
+	for _, err := range errs {
+		warn(err)
+	}
+
+	var runner domain.ShellRunner = shell.New(logs.Logger)
+	if !files.Local() {
+		runner = shell.Refusing{Reason: fmt.Errorf("AVRO_FS=%s does not apply to other processes", os.Getenv("AVRO_FS"))}
+	}
+	runner, client, err := withCassette(runner, net.New(logs.Logger), files.Local())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "avro:", err)
 		os.Exit(1)
 	}
+
+	exec := executor.New(runner, files, client)
+	exec.DefaultTimeout = cfg.Timeout
+	exec.Log = logs.Logger
+	exec.History = history.Default(files)
+	exec.Favorites = favorites.Default(files)
+	root, errs := cli.NewRootCommand(exec, cfg, logs)
+	for _, err := range errs {
+		warn(err)
+	}
+
+	err = root.Execute()
+	logs.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// withCassette wraps the shell runner and HTTP client so their interactions
+// are recorded to the cassette named by AVRO_RECORD, appending if it exists,
+// or served from the one named by AVRO_REPLAY without running anything.
+// AVRO_REPLAY_MATCH relaxes how calls are matched; see cassette.ParseMatching.
+func withCassette(runner domain.ShellRunner, client domain.HTTPClient, localFS bool) (domain.ShellRunner, domain.HTTPClient, error) {
+	record, replay := os.Getenv("AVRO_RECORD"), os.Getenv("AVRO_REPLAY")
+	switch {
+	case record != "" && replay != "":
+		return nil, nil, errors.New("AVRO_RECORD and AVRO_REPLAY cannot both be set")
+	case record != "" && !localFS:
+		// Commands would be recorded against files that are not on disk.
+		return nil, nil, errors.New("AVRO_RECORD cannot be combined with AVRO_FS")
+	case record != "":
+		c, err := cassette.Open(record)
+		if err != nil {
+			return nil, nil, fmt.Errorf("AVRO_RECORD: %w", err)
+		}
+		return cassette.NewShellRecorder(c, runner), cassette.NewHTTPRecorder(c, client), nil
+	case replay != "":
+		c, err := cassette.Load(replay)
+		if err != nil {
+			return nil, nil, fmt.Errorf("AVRO_REPLAY: %w", err)
+		}
+		if c.Match, err = cassette.ParseMatching(os.Getenv("AVRO_REPLAY_MATCH")); err != nil {
+			return nil, nil, fmt.Errorf("AVRO_REPLAY_MATCH: %w", err)
+		}
+		return cassette.NewShellReplayer(c), cassette.NewHTTPReplayer(c), nil
+	}
+	return runner, client, nil
 }
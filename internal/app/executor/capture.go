@@ -0,0 +1,46 @@
+package executor
+
+import (
+	"avro_cli/internal/app/history"
+	"avro_cli/internal/domain"
+	"strings"
+	"sync"
+)
+
+// captureSink forwards output to next while keeping the first
+// history.MaxOutput bytes for the history entry.
+type captureSink struct {
+	next domain.OutputSink
+
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (c *captureSink) WriteLine(line domain.OutputLine) {
+	if c.next != nil {
+		c.next.WriteLine(line)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.buf.Len() <= history.MaxOutput {
+		c.buf.WriteString(line.Text)
+		c.buf.WriteByte('\n')
+	}
+}
+
+func (c *captureSink) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return strings.TrimSuffix(c.buf.String(), "\n")
+}
+
+// joinOutput appends the result summary to streamed output, as the TUI shows them.
+func joinOutput(streamed, summary string) string {
+	switch {
+	case streamed == "":
+		return summary
+	case summary == "":
+		return streamed
+	}
+	return streamed + "\n\n" + summary
+}
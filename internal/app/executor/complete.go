@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"avro_cli/internal/domain"
+	"context"
+	"strings"
+	"time"
+)
+
+// completionTimeout bounds value providers so a slow one cannot hang the shell.
+const completionTimeout = 2 * time.Second
+
+// Complete returns value suggestions for def, an arg or flag of cmd. args are the
+// positional values typed so far; they are passed through raw, without validation.
+// Without a provider, a def with Choices suggests those matching toComplete.
+func (e *Executor) Complete(parent context.Context, cmd domain.CommandDescriptor, def domain.ArgDef, args []string, toComplete string) []string {
+	if def.Complete == nil {
+		var out []string
+		for _, c := range def.Choices {
+			if strings.HasPrefix(c, toComplete) {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(e.context(parent), completionTimeout)
+	defer cancel()
+
+	typed := make(map[string]string)
+	for i, a := range cmd.Args {
+		if i < len(args) {
+			typed[a.Name] = args[i]
+		}
+	}
+
+	return def.Complete(domain.CommandContext{
+		Context: ctx,
+		Output:  domain.NewOutput(nil),
+		Args:    typed,
+		Flags:   map[string]string{},
+		Shell:   e.Shell,
+		FS:      e.FS,
+		HTTP:    e.HTTP,
+	}, toComplete)
+}
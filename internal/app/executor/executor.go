@@ -1,8 +1,16 @@
 package executor
 
 import (
+	"avro_cli/internal/app/favorites"
+	"avro_cli/internal/app/history"
 	"avro_cli/internal/domain"
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
 )
 
 // Executor validates arguments and runs a command action.
@@ -10,6 +18,21 @@ type Executor struct {
 	Shell domain.ShellRunner
 	FS    domain.FileSystem
 	HTTP  domain.HTTPClient
+
+	// Timeout, when non-zero, overrides every CommandDescriptor.Timeout.
+	Timeout time.Duration
+	// DefaultTimeout applies to commands that declare no Timeout of their own.
+	// Interactive commands are exempt, since they wait on the user.
+	DefaultTimeout time.Duration
+
+	// Log receives a record for every execution; nil disables logging.
+	Log *slog.Logger
+
+	// History, when set, persists every execution not marked NoHistory.
+	// Commands reach it through history.FromContext.
+	History *history.Store
+	// Favorites, when set, holds the commands pinned in the TUI.
+	Favorites *favorites.Store
 }
 
 // New creates an executor with the given infrastructure dependencies.
@@ -18,54 +41,245 @@ func New(shell domain.ShellRunner, fs domain.FileSystem, http domain.HTTPClient)
 }
 
 // Run validates the provided args/flags against the command descriptor and executes the action.
-func (e *Executor) Run(cmd domain.CommandDescriptor, args []string, flags map[string]string) domain.Result[string] {
-	resolved, err := e.resolveArgs(cmd, args)
+// Values are coerced per ArgDef.Type; a malformed value yields a *domain.ValidationError.
+// The action receives a child of parent that is cancelled when Run returns or the
+// command's timeout elapses. Streamed output is discarded; see RunStream.
+func (e *Executor) Run(parent context.Context, cmd domain.CommandDescriptor, args []string, flags map[string]string) domain.Result[string] {
+	return e.RunStream(parent, cmd, args, flags, nil)
+}
+
+// RunStream is like Run but forwards the action's streamed output to sink as it
+// is produced. Partial lines are flushed before RunStream returns.
+func (e *Executor) RunStream(parent context.Context, cmd domain.CommandDescriptor, args []string, flags map[string]string, sink domain.OutputSink) domain.Result[string] {
+	result := e.RunRecords(parent, cmd, args, flags, sink)
+	if !result.IsOk() {
+		return domain.Fail[string](result.Err())
+	}
+	return domain.Ok(result.Value().Text())
+}
+
+// RunRecords is like RunStream but returns the structured result. Commands
+// implemented with a plain CommandAction yield domain.TextRecords.
+func (e *Executor) RunRecords(parent context.Context, cmd domain.CommandDescriptor, args []string, flags map[string]string, sink domain.OutputSink) domain.Result[domain.Records] {
+	log := e.logger().With("command", cmd.FullName())
+	// Values can carry credentials (-H Authorization) and payloads; they are
+	// kept out of the history too.
+	logArgs, logFlags := cmd.Redact(args, flags)
+	log.Debug("command start", "args", logArgs, "flags", logFlags)
+	start := time.Now()
+
+	var captured *captureSink
+	if e.History != nil && !cmd.NoHistory {
+		captured = &captureSink{next: sink}
+		sink = captured
+	}
+
+	result := e.run(parent, cmd, args, flags, sink)
+	duration := time.Since(start)
+	if result.IsOk() {
+		log.Debug("command finished", "duration", duration)
+	} else {
+		// Failures are kept at the default level so they leave a trace.
+		log.Warn("command failed", "duration", duration, "error", result.Err())
+	}
+
+	if captured != nil {
+		entry := history.Entry{
+			Time:     start,
+			Command:  cmd.FullName(),
+			Args:     logArgs,
+			Flags:    logFlags,
+			Duration: duration,
+			Success:  result.IsOk(),
+			Redacted: !slices.Equal(logArgs, args) || !maps.Equal(logFlags, flags),
+		}
+		if !cmd.NoHistoryOutput {
+			entry.Output = captured.String()
+			if result.IsOk() {
+				entry.Output = joinOutput(entry.Output, result.Value().Text())
+			}
+		}
+		if !result.IsOk() {
+			entry.Error = result.Err().Error()
+		}
+		if _, err := e.History.Append(entry); err != nil {
+			log.Warn("history not saved", "error", err)
+		}
+	}
+	return result
+}
+
+func (e *Executor) run(parent context.Context, cmd domain.CommandDescriptor, args []string, flags map[string]string, sink domain.OutputSink) domain.Result[domain.Records] {
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	resolved, extra, err := e.resolveArgs(cmd, args)
 	if err != nil {
-		return domain.Fail[string](err)
+		return domain.Fail[domain.Records](err)
 	}
 
-	resolvedFlags := e.resolveFlags(cmd, flags)
+	resolvedFlags, err := e.resolveFlags(cmd, flags)
+	if err != nil {
+		return domain.Fail[domain.Records](err)
+	}
+
+	timeout := e.timeoutFor(cmd)
+	runCtx, cancel := withTimeout(e.context(parent), timeout)
+	defer cancel()
 
+	output := domain.NewOutput(sink)
 	ctx := domain.CommandContext{
-		Args:  resolved,
-		Flags: resolvedFlags,
-		Shell: e.Shell,
-		FS:    e.FS,
-		HTTP:  e.HTTP,
+		Context: runCtx,
+		Output:  output,
+		Args:    resolved,
+		Flags:   resolvedFlags,
+		Extra:   extra,
+		Shell:   e.Shell,
+		FS:      e.FS,
+		HTTP:    e.HTTP,
+	}
+	ctx.Run = func(target domain.CommandDescriptor, args []string, flags map[string]string) domain.Result[domain.Records] {
+		output.Flush()
+		return e.RunRecords(runCtx, target, args, flags, sink)
+	}
+
+	result := invoke(cmd, ctx)
+	output.Flush()
+	if result.IsOk() {
+		return result
+	}
+
+	// Report interruption rather than whatever error the killed process produced.
+	switch {
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded) && parent.Err() == nil:
+		return domain.Fail[domain.Records](&domain.TimeoutError{Command: cmd.FullName(), Timeout: timeout})
+	case runCtx.Err() != nil:
+		return domain.Fail[domain.Records](&domain.ExecutionError{Command: cmd.FullName(), Cause: runCtx.Err()})
 	}
+	return result
+}
+
+// invoke calls whichever action the descriptor defines, normalizing to records.
+func invoke(cmd domain.CommandDescriptor, ctx domain.CommandContext) domain.Result[domain.Records] {
+	if cmd.Data != nil {
+		return cmd.Data(ctx)
+	}
+	if cmd.Action == nil {
+		return domain.Fail[domain.Records](&domain.ExecutionError{
+			Command: cmd.FullName(),
+			Cause:   errors.New("command has no action"),
+		})
+	}
+	result := cmd.Action(ctx)
+	if !result.IsOk() {
+		return domain.Fail[domain.Records](result.Err())
+	}
+	return domain.Ok(domain.TextRecords(result.Value()))
+}
+
+// context returns parent carrying the executor's history store, if any.
+func (e *Executor) context(parent context.Context) context.Context {
+	if e.History == nil {
+		return parent
+	}
+	return history.NewContext(parent, e.History)
+}
+
+func (e *Executor) logger() *slog.Logger {
+	if e.Log == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return e.Log
+}
 
-	return cmd.Action(ctx)
+func (e *Executor) timeoutFor(cmd domain.CommandDescriptor) time.Duration {
+	switch {
+	case e.Timeout > 0:
+		return e.Timeout
+	case cmd.Timeout > 0 || cmd.Interactive:
+		return cmd.Timeout
+	}
+	return e.DefaultTimeout
+}
+
+func withTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(parent, timeout)
+	}
+	return context.WithCancel(parent)
 }
 
-func (e *Executor) resolveArgs(cmd domain.CommandDescriptor, provided []string) (map[string]string, error) {
+func (e *Executor) resolveArgs(cmd domain.CommandDescriptor, provided []string) (map[string]string, []string, error) {
+	if err := cmd.CheckArgs(); err != nil {
+		return nil, nil, err
+	}
 	resolved := make(map[string]string)
 
 	for i, def := range cmd.Args {
+		raw := ""
 		if i < len(provided) {
-			resolved[def.Name] = provided[i]
-		} else if def.Required {
-			return nil, &domain.ValidationError{
-				Field:   def.Name,
-				Message: fmt.Sprintf("required argument %q is missing", def.Name),
+			raw = provided[i]
+		}
+		// A repeatable last arg takes every remaining value.
+		last := i == len(cmd.Args)-1
+		if def.Repeatable && last && i < len(provided) {
+			raw = domain.JoinValues(provided[i:])
+		}
+		if raw == "" {
+			if def.Required {
+				return nil, nil, &domain.ValidationError{
+					Field:   def.Name,
+					Message: fmt.Sprintf("required argument %q is missing", def.Name),
+				}
 			}
-		} else if def.Default != "" {
-			resolved[def.Name] = def.Default
+			raw = def.Default
+		}
+		if raw == "" {
+			continue
 		}
+
+		val, err := resolveValue(def, raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		resolved[def.Name] = val
 	}
 
-	return resolved, nil
+	if len(provided) <= len(cmd.Args) || (len(cmd.Args) > 0 && cmd.Args[len(cmd.Args)-1].Repeatable) {
+		return resolved, nil, nil
+	}
+	if !cmd.ExtraArgs {
+		return nil, nil, &domain.ValidationError{
+			Field:   cmd.FullName(),
+			Message: fmt.Sprintf("accepts at most %d argument(s), received %d", len(cmd.Args), len(provided)),
+		}
+	}
+	return resolved, provided[len(cmd.Args):], nil
 }
 
-func (e *Executor) resolveFlags(cmd domain.CommandDescriptor, provided map[string]string) map[string]string {
+func (e *Executor) resolveFlags(cmd domain.CommandDescriptor, provided map[string]string) (map[string]string, error) {
 	resolved := make(map[string]string)
 
 	for _, def := range cmd.Flags {
-		if val, ok := provided[def.Name]; ok {
-			resolved[def.Name] = val
-		} else if def.Default != "" {
-			resolved[def.Name] = def.Default
+		raw, ok := provided[def.Name]
+		if !ok {
+			raw = def.Default
+		}
+		if raw == "" {
+			// An explicitly empty value (--flag=) clears the default.
+			if ok {
+				resolved[def.Name] = ""
+			}
+			continue
 		}
+
+		val, err := resolveValue(def, raw)
+		if err != nil {
+			return nil, err
+		}
+		resolved[def.Name] = val
 	}
 
-	return resolved
+	return resolved, nil
 }
@@ -0,0 +1,111 @@
+package executor_test
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/testkit"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitCmd blocks until its context ends, then fails the way a killed
+// process would, with an error unrelated to the context.
+var waitCmd = domain.CommandDescriptor{
+	Category: domain.Category{Name: "test"},
+	Name:     "wait",
+	Action: func(ctx domain.CommandContext) domain.Result[string] {
+		<-ctx.Context.Done()
+		return domain.Fail[string](errors.New("signal: killed"))
+	},
+}
+
+func TestTimeoutErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		cmdTimeout  time.Duration
+		override    time.Duration
+		defaultTime time.Duration
+		want        time.Duration
+	}{
+		{"command timeout", 20 * time.Millisecond, 0, time.Hour, 20 * time.Millisecond},
+		{"--timeout overrides it", time.Hour, 30 * time.Millisecond, 0, 30 * time.Millisecond},
+		{"config default for commands without one", 0, 0, 40 * time.Millisecond, 40 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		env := testkit.New(t)
+		env.Executor.Timeout = tt.override
+		env.Executor.DefaultTimeout = tt.defaultTime
+		cmd := waitCmd
+		cmd.Timeout = tt.cmdTimeout
+
+		var terr *domain.TimeoutError
+		env.Run(cmd, nil, nil).FailsAs(&terr)
+		if terr.Timeout != tt.want || terr.Command != "test wait" {
+			t.Errorf("%s: %+v, want a %s timeout", tt.name, terr, tt.want)
+		}
+		if !errors.Is(terr, context.DeadlineExceeded) {
+			t.Errorf("%s: timeout does not unwrap to DeadlineExceeded", tt.name)
+		}
+	}
+}
+
+func TestCancelIsNotATimeout(t *testing.T) {
+	env := testkit.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	cmd := waitCmd
+	cmd.Timeout = time.Hour
+	result := env.Executor.RunRecords(ctx, cmd, nil, nil, nil)
+
+	var eerr *domain.ExecutionError
+	if !errors.As(result.Err(), &eerr) || !errors.Is(result.Err(), context.Canceled) {
+		t.Errorf("cancelled command = %v, want an ExecutionError wrapping context.Canceled", result.Err())
+	}
+}
+
+func TestParentDeadlineIsNotACommandTimeout(t *testing.T) {
+	// The caller's deadline ran out, not the command's own timeout.
+	env := testkit.New(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	result := env.Executor.RunRecords(ctx, waitCmd, nil, nil, nil)
+	var terr *domain.TimeoutError
+	if errors.As(result.Err(), &terr) || !errors.Is(result.Err(), context.DeadlineExceeded) {
+		t.Errorf("result = %v, want the parent's DeadlineExceeded", result.Err())
+	}
+}
+
+func TestFailureBeforeTheDeadlineIsKept(t *testing.T) {
+	env := testkit.New(t)
+	cmd := domain.CommandDescriptor{
+		Name:    "fail",
+		Timeout: time.Hour,
+		Action: func(domain.CommandContext) domain.Result[string] {
+			return domain.Fail[string](errors.New("exit status 2"))
+		},
+	}
+	env.Run(cmd, nil, nil).Fails("exit status 2")
+}
+
+func TestEmptyFlagClearsDefault(t *testing.T) {
+	var seen map[string]string
+	cmd := domain.CommandDescriptor{
+		Name:  "show",
+		Flags: []domain.ArgDef{{Name: "format", Default: "short"}, {Name: "color", Default: "auto"}},
+		Action: func(ctx domain.CommandContext) domain.Result[string] {
+			seen = ctx.Flags
+			return domain.Ok("")
+		},
+	}
+	env := testkit.New(t)
+	env.Run(cmd, nil, map[string]string{"format": ""}).OK()
+	if v, ok := seen["format"]; !ok || v != "" {
+		t.Errorf("format = %q, %v; want set and empty", v, ok)
+	}
+	if seen["color"] != "auto" {
+		t.Errorf("color = %q, want its default", seen["color"])
+	}
+}
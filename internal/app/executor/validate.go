@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"avro_cli/internal/domain"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// resolveValue coerces raw and checks it against def's constraints. The
+// values of a Repeatable def are split, checked one by one and joined again.
+func resolveValue(def domain.ArgDef, raw string) (string, error) {
+	if !def.Repeatable {
+		val, err := coerce(def, raw)
+		if err != nil {
+			return "", err
+		}
+		return val, def.Check(val)
+	}
+
+	values := domain.SplitValues(raw)
+	for i, v := range values {
+		val, err := coerce(def, v)
+		if err != nil {
+			return "", err
+		}
+		if err := def.Check(val); err != nil {
+			return "", err
+		}
+		values[i] = val
+	}
+	return domain.JoinValues(values), nil
+}
+
+// coerce parses raw according to def.Type and returns its canonical string form.
+// Bools normalize to "true"/"false" and ints to base-10 without sign noise.
+func coerce(def domain.ArgDef, raw string) (string, error) {
+	switch def.Type {
+	case domain.ArgBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", &domain.ValidationError{
+				Field:   def.Name,
+				Message: fmt.Sprintf("expected a boolean (true/false), got %q", raw),
+			}
+		}
+		return strconv.FormatBool(b), nil
+
+	case domain.ArgInt:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			if errors.Is(err, strconv.ErrRange) {
+				return "", &domain.ValidationError{
+					Field:   def.Name,
+					Message: fmt.Sprintf("integer %s is out of range", raw),
+				}
+			}
+			return "", &domain.ValidationError{
+				Field:   def.Name,
+				Message: fmt.Sprintf("expected an integer, got %q", raw),
+			}
+		}
+		return strconv.Itoa(n), nil
+
+	default:
+		return raw, nil
+	}
+}
@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"avro_cli/internal/domain"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCoerce(t *testing.T) {
+	tests := []struct {
+		typ     domain.ArgType
+		raw     string
+		want    string
+		wantErr string
+	}{
+		{domain.ArgString, " as is ", " as is ", ""},
+		{domain.ArgBool, "true", "true", ""},
+		{domain.ArgBool, "1", "true", ""},
+		{domain.ArgBool, "F", "false", ""},
+		{domain.ArgBool, "yes", "", "expected a boolean"},
+		{domain.ArgInt, "42", "42", ""},
+		{domain.ArgInt, "+007", "7", ""},
+		{domain.ArgInt, "-3", "-3", ""},
+		{domain.ArgInt, "4.2", "", "expected an integer"},
+		{domain.ArgInt, "99999999999999999999", "", "out of range"},
+	}
+	for _, tt := range tests {
+		got, err := coerce(domain.ArgDef{Name: "n", Type: tt.typ}, tt.raw)
+		if tt.wantErr != "" {
+			var verr *domain.ValidationError
+			if !errors.As(err, &verr) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("coerce(%v, %q) error = %v, want ValidationError containing %q", tt.typ, tt.raw, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("coerce(%v, %q) = %q, %v; want %q", tt.typ, tt.raw, got, err, tt.want)
+		}
+	}
+}
+
+func TestResolveValue(t *testing.T) {
+	port := domain.ArgDef{Name: "port", Type: domain.ArgInt, Min: "1", Max: "65535"}
+	if got, err := resolveValue(port, "080"); err != nil || got != "80" {
+		t.Errorf("resolveValue(080) = %q, %v; want 80", got, err)
+	}
+	if _, err := resolveValue(port, "0"); err == nil {
+		t.Error("resolveValue(0) passed Min")
+	}
+
+	ports := port
+	ports.Repeatable = true
+	got, err := resolveValue(ports, domain.JoinValues([]string{"01", "443"}))
+	if err != nil || got != domain.JoinValues([]string{"1", "443"}) {
+		t.Errorf("repeatable resolveValue = %q, %v", got, err)
+	}
+	if _, err := resolveValue(ports, domain.JoinValues([]string{"80", "70000"})); err == nil {
+		t.Error("repeatable resolveValue passed a value above Max")
+	}
+
+	// Line breaks survive inside a repeatable value.
+	lines := domain.ArgDef{Name: "line", Repeatable: true}
+	raw := domain.JoinValues([]string{"a\nb", "c"})
+	if got, err := resolveValue(lines, raw); err != nil || got != raw {
+		t.Errorf("resolveValue(%q) = %q, %v", raw, got, err)
+	}
+}
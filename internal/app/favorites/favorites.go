@@ -0,0 +1,90 @@
+package favorites
+
+import (
+	"avro_cli/internal/config"
+	"avro_cli/internal/domain"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Path returns the default favorites file (~/.avro/favorites.yaml), a YAML
+// list of full command names such as "git status".
+func Path() string {
+	return filepath.Join(config.Dir(), "favorites.yaml")
+}
+
+// Store persists pinned commands in the order they were pinned.
+type Store struct {
+	fs   domain.FileSystem
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore returns a store backed by the file at path on fs, created on
+// first write.
+func NewStore(fs domain.FileSystem, path string) *Store {
+	return &Store{fs: fs, path: path}
+}
+
+// Default returns a store at Path() on fs.
+func Default(fs domain.FileSystem) *Store {
+	return NewStore(fs, Path())
+}
+
+// List returns the pinned command names.
+func (s *Store) List() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// Toggle pins name, or unpins it if already pinned, and reports whether it
+// is now pinned.
+func (s *Store) Toggle(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names, err := s.read()
+	if err != nil {
+		return false, err
+	}
+	pinned := !slices.Contains(names, name)
+	if pinned {
+		names = append(names, name)
+	} else {
+		names = slices.DeleteFunc(names, func(n string) bool { return n == name })
+	}
+	return pinned, s.write(names)
+}
+
+func (s *Store) read() ([]string, error) {
+	data, err := s.fs.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := yaml.Unmarshal(data, &names); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return names, nil
+}
+
+func (s *Store) write(names []string) error {
+	data, err := yaml.Marshal(names)
+	if err != nil {
+		return err
+	}
+	if err := s.fs.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	return s.fs.WriteFileAtomic(s.path, data, 0o600)
+}
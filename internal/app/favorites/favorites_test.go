@@ -0,0 +1,71 @@
+package favorites
+
+import (
+	"avro_cli/internal/infra/fs"
+	iofs "io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testPath = "/home/.avro/favorites.yaml"
+
+func TestToggle(t *testing.T) {
+	s := NewStore(fs.NewMemory(), testPath)
+	if names, err := s.List(); err != nil || names != nil {
+		t.Fatalf("List before any pin = %q, %v; want none", names, err)
+	}
+
+	steps := []struct {
+		name   string
+		pinned bool
+		want   []string
+	}{
+		{"git status", true, []string{"git status"}},
+		{"http get", true, []string{"git status", "http get"}},
+		{"git log", true, []string{"git status", "http get", "git log"}},
+		{"http get", false, []string{"git status", "git log"}},
+		{"http get", true, []string{"git status", "git log", "http get"}},
+	}
+	for _, st := range steps {
+		pinned, err := s.Toggle(st.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names, err := s.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pinned != st.pinned || !slices.Equal(names, st.want) {
+			t.Errorf("Toggle(%q) = %v, List = %q; want %v, %q", st.name, pinned, names, st.pinned, st.want)
+		}
+	}
+}
+
+func TestCorruptFile(t *testing.T) {
+	files := fs.NewMemory()
+	if err := files.WriteFile(testPath, []byte("pinned: {"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := NewStore(files, testPath)
+	if _, err := s.List(); err == nil || !strings.Contains(err.Error(), testPath) {
+		t.Errorf("List of a corrupt file = %v, want an error naming it", err)
+	}
+	if _, err := s.Toggle("git status"); err == nil {
+		t.Error("Toggle overwrote a corrupt file")
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	files := fs.NewMemory()
+	s := NewStore(files, testPath)
+	if _, err := s.Toggle("git status"); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]iofs.FileMode{testPath: 0o600, filepath.Dir(testPath): 0o700} {
+		if info, err := files.Stat(path); err != nil || info.Mode().Perm() != want {
+			t.Errorf("%s: mode %v, %v; want %v", path, info.Mode().Perm(), err, want)
+		}
+	}
+}
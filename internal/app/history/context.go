@@ -0,0 +1,26 @@
+package history
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrDisabled is returned by commands reading the history when the executor
+// records none.
+var ErrDisabled = errors.New("history is disabled")
+
+type storeKey struct{}
+
+// NewContext returns a copy of ctx carrying s, the store the executor records
+// to, for commands that read the history.
+func NewContext(ctx context.Context, s *Store) context.Context {
+	return context.WithValue(ctx, storeKey{}, s)
+}
+
+// FromContext returns the store carried by ctx, or ErrDisabled.
+func FromContext(ctx context.Context) (*Store, error) {
+	if s, ok := ctx.Value(storeKey{}).(*Store); ok && s != nil {
+		return s, nil
+	}
+	return nil, ErrDisabled
+}
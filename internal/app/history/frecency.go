@@ -0,0 +1,47 @@
+package history
+
+import "time"
+
+// Frecency scores each command (by full name) on how often and how recently
+// it ran: every execution adds a weight that decays with its age.
+func Frecency(entries []Entry, now time.Time) map[string]float64 {
+	scores := make(map[string]float64)
+	for _, e := range entries {
+		scores[e.Command] += ageWeight(now.Sub(e.Time))
+	}
+	return scores
+}
+
+func ageWeight(age time.Duration) float64 {
+	switch {
+	case age < 4*time.Hour:
+		return 100
+	case age < 24*time.Hour:
+		return 80
+	case age < 7*24*time.Hour:
+		return 60
+	case age < 30*24*time.Hour:
+		return 40
+	case age < 90*24*time.Hour:
+		return 20
+	default:
+		return 10
+	}
+}
+
+// Recent returns up to n distinct commands from entries (newest first, as
+// returned by List), most recently run first.
+func Recent(entries []Entry, n int) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, e := range entries {
+		if len(out) == n {
+			break
+		}
+		if !seen[e.Command] {
+			seen[e.Command] = true
+			out = append(out, e.Command)
+		}
+	}
+	return out
+}
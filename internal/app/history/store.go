@@ -0,0 +1,348 @@
+package history
+
+import (
+	"avro_cli/internal/config"
+	"avro_cli/internal/domain"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxEntries is how many executions the store keeps; older ones are
+	// dropped, trimSlack at a time.
+	MaxEntries = 1000
+	// MaxOutput caps the output kept per execution, in bytes.
+	MaxOutput = 4096
+
+	// trimSlack is how many entries beyond MaxEntries may pile up before the
+	// file is rewritten without the oldest.
+	trimSlack = MaxEntries / 10
+
+	// lockWait bounds how long a write waits for another process's lock;
+	// lockStale is the age at which a lock is considered abandoned.
+	lockWait  = 2 * time.Second
+	lockStale = 10 * time.Second
+)
+
+// Entry is one recorded command execution.
+type Entry struct {
+	ID        int               `json:"id"`
+	Time      time.Time         `json:"time"`
+	Command   string            `json:"command"` // full name, "category name"
+	Args      []string          `json:"args,omitempty"`
+	Flags     map[string]string `json:"flags,omitempty"`
+	Duration  time.Duration     `json:"duration"`
+	Success   bool              `json:"success"`
+	Error     string            `json:"error,omitempty"`
+	Output    string            `json:"output,omitempty"`
+	Truncated bool              `json:"truncated,omitempty"`
+	// Redacted is set when secrets in Args or Flags were replaced by
+	// domain.Redacted, so the entry cannot be run again as recorded.
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// Category returns the category part of Command.
+func (e Entry) Category() string {
+	category, _, _ := strings.Cut(e.Command, " ")
+	return category
+}
+
+// Name returns the command name part of Command.
+func (e Entry) Name() string {
+	_, name, _ := strings.Cut(e.Command, " ")
+	return name
+}
+
+// CommandLine renders the entry as the command line that produced it.
+func (e Entry) CommandLine() string {
+	parts := []string{e.Command}
+	for _, a := range e.Args {
+		parts = append(parts, quoteArg(a))
+	}
+	names := make([]string, 0, len(e.Flags))
+	for name := range e.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range domain.SplitValues(e.Flags[name]) {
+			parts = append(parts, "--"+name+"="+quoteArg(v))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func quoteArg(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"'") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// Path returns the default history file (~/.avro/history.jsonl).
+func Path() string {
+	return filepath.Join(config.Dir(), "history.jsonl")
+}
+
+// Store persists entries as JSON lines, oldest first.
+type Store struct {
+	fs   domain.FileSystem
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore returns a store backed by the file at path on fs, created on
+// first write.
+func NewStore(fs domain.FileSystem, path string) *Store {
+	return &Store{fs: fs, path: path}
+}
+
+// Default returns a store at Path() on fs.
+func Default(fs domain.FileSystem) *Store {
+	return NewStore(fs, Path())
+}
+
+// Append assigns e the next ID, truncates its output and saves it, returning
+// the stored entry. Other processes appending to the same file wait for it.
+//
+// Only the first and last lines are read to number the entry. The oldest
+// entries are dropped once MaxEntries+trimSlack have piled up, so the file
+// is rewritten once every trimSlack executions rather than on each one.
+func (s *Store) Append(e Entry) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return Entry{}, err
+	}
+	defer unlock()
+
+	if len(e.Output) > MaxOutput {
+		e.Output = strings.ToValidUTF8(e.Output[:MaxOutput], "")
+		e.Truncated = true
+	}
+
+	first, last, err := s.bounds()
+	if err != nil || last-first+1 >= MaxEntries+trimSlack {
+		// Corrupt ends or too many entries: rewrite the file.
+		entries, err := s.read()
+		if err != nil {
+			return Entry{}, err
+		}
+		e.ID = 1
+		if n := len(entries); n > 0 {
+			e.ID = entries[n-1].ID + 1
+		}
+		entries = append(entries, e)
+		if n := len(entries); n > MaxEntries {
+			entries = entries[n-MaxEntries:]
+		}
+		return e, s.write(entries)
+	}
+	e.ID = last + 1
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return Entry{}, err
+	}
+	f, err := s.fs.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer f.Close()
+	// Tighten files created with wider permissions by older versions.
+	if err := s.fs.Chmod(s.path, 0o600); err != nil {
+		return Entry{}, err
+	}
+	_, err = f.Write(append(line, '\n'))
+	return e, err
+}
+
+// List returns every entry, most recent first.
+func (s *Store) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, err
+}
+
+// Get returns the entry with the given ID.
+func (s *Store) Get(id int) (Entry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, e := range entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return Entry{}, fmt.Errorf("no history entry #%d", id)
+}
+
+// Clear deletes every entry.
+func (s *Store) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.fs.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// read loads entries oldest first, skipping lines it cannot parse so one
+// corrupt write does not hide the rest of the history.
+func (s *Store) read() ([]Entry, error) {
+	data, err := s.fs.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		var e Entry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
+
+// bounds returns the IDs of the first and last entries without reading the
+// lines in between, both 0 when there are none. IDs are consecutive, so the
+// file holds last-first+1 entries. It fails when either line does not parse.
+func (s *Store) bounds() (first, last int, err error) {
+	f, err := s.fs.OpenFile(s.path, os.O_RDONLY, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return 0, 0, err
+	}
+	head, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, 0, err
+	}
+	tail, err := lastLine(f, info.Size())
+	if err != nil {
+		return 0, 0, err
+	}
+	if first, err = entryID(head); err != nil {
+		return 0, 0, err
+	}
+	if last, err = entryID(tail); err != nil {
+		return 0, 0, err
+	}
+	return first, last, nil
+}
+
+// lastLine returns the last line of the size bytes of f, reading backwards
+// from the end in blocks.
+func lastLine(f io.ReaderAt, size int64) ([]byte, error) {
+	const block = 8 * 1024
+	var buf []byte
+	for off := size; off > 0; {
+		n := min(block, off)
+		off -= n
+		chunk := make([]byte, n)
+		if _, err := f.ReadAt(chunk, off); err != nil {
+			return nil, err
+		}
+		buf = append(chunk, buf...)
+		if i := bytes.LastIndexByte(bytes.TrimRight(buf, "\n"), '\n'); i >= 0 {
+			return buf[i+1:], nil
+		}
+	}
+	return buf, nil
+}
+
+// entryID parses the ID of an encoded entry.
+func entryID(line []byte) (int, error) {
+	var e struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(line, &e); err != nil {
+		return 0, err
+	}
+	if e.ID <= 0 {
+		return 0, errors.New("entry without an ID")
+	}
+	return e.ID, nil
+}
+
+// write replaces the file with entries atomically.
+func (s *Store) write(entries []Entry) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	if err := s.fs.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	return s.fs.WriteFileAtomic(s.path, buf.Bytes(), 0o600)
+}
+
+// lock takes the lock file next to the history file, which serializes
+// writers across processes, and returns the function releasing it. A lock
+// older than lockStale is taken to be left over by a crashed process.
+func (s *Store) lock() (func(), error) {
+	if err := s.fs.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return nil, err
+	}
+	path := s.path + ".lock"
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := s.fs.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			return func() { s.fs.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if info, err := s.fs.Stat(path); err == nil && time.Since(info.ModTime()) > lockStale {
+			s.fs.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("history is locked by another avro process (delete %s if none is running)", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,186 @@
+package history
+
+import (
+	"avro_cli/internal/infra/fs"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testPath = "/home/.avro/history.jsonl"
+
+func TestAppendListGet(t *testing.T) {
+	s := NewStore(fs.NewMemory(), testPath)
+	for _, cmd := range []string{"git status", "git log", "http get"} {
+		if _, err := s.Append(Entry{Command: cmd, Success: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].ID != 3 || entries[0].Command != "http get" || entries[2].ID != 1 {
+		t.Errorf("List = %+v, want IDs 3, 2, 1", entries)
+	}
+	if e, err := s.Get(2); err != nil || e.Command != "git log" {
+		t.Errorf("Get(2) = %+v, %v", e, err)
+	}
+	if _, err := s.Get(9); err == nil {
+		t.Error("Get(9) found an entry")
+	}
+
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := s.List(); len(entries) != 0 {
+		t.Errorf("List after Clear = %+v", entries)
+	}
+	// Numbering starts over.
+	if e, _ := s.Append(Entry{Command: "git status"}); e.ID != 1 {
+		t.Errorf("ID after Clear = %d, want 1", e.ID)
+	}
+}
+
+func TestAppendTruncatesOutput(t *testing.T) {
+	s := NewStore(fs.NewMemory(), testPath)
+	// A multi-byte rune straddles the limit and must not be cut in half.
+	out := strings.Repeat("a", MaxOutput-1) + "é"
+	e, err := s.Append(Entry{Command: "system env", Output: out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Truncated || e.Output != strings.Repeat("a", MaxOutput-1) {
+		t.Errorf("Append kept %d bytes, truncated %v", len(e.Output), e.Truncated)
+	}
+}
+
+func TestAppendPermissions(t *testing.T) {
+	files := fs.NewMemory()
+	files.WriteFile(testPath, nil, 0o644)
+	s := NewStore(files, testPath)
+	if _, err := s.Append(Entry{Command: "git status"}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := files.Stat(testPath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("history file mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+}
+
+func TestAppendTrims(t *testing.T) {
+	s := NewStore(fs.NewMemory(), testPath)
+	for range MaxEntries + trimSlack {
+		if _, err := s.Append(Entry{Command: "git status"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The file is only rewritten once the slack is used up.
+	if entries, _ := s.List(); len(entries) != MaxEntries+trimSlack {
+		t.Fatalf("kept %d entries before trimming, want %d", len(entries), MaxEntries+trimSlack)
+	}
+
+	e, err := s.Append(Entry{Command: "git log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := s.List()
+	if len(entries) != MaxEntries || entries[0].ID != e.ID || entries[MaxEntries-1].ID != e.ID-MaxEntries+1 {
+		t.Errorf("after trimming: %d entries, newest #%d, oldest #%d", len(entries), entries[0].ID, entries[len(entries)-1].ID)
+	}
+	if e.ID != MaxEntries+trimSlack+1 {
+		t.Errorf("ID = %d, want %d", e.ID, MaxEntries+trimSlack+1)
+	}
+}
+
+func TestAppendRecoversFromCorruptEnds(t *testing.T) {
+	files := fs.NewMemory()
+	files.WriteFile(testPath, []byte(`{"id":4,"command":"git status"}`+"\n"+`{"id":5,"comm`), 0o600)
+	s := NewStore(files, testPath)
+
+	e, err := s.Append(Entry{Command: "git log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := s.List()
+	if e.ID != 5 || len(entries) != 2 || entries[1].ID != 4 {
+		t.Errorf("appended #%d, entries %+v", e.ID, entries)
+	}
+}
+
+func TestAppendLongLastLine(t *testing.T) {
+	s := NewStore(fs.NewMemory(), testPath)
+	s.Append(Entry{Command: "git status"})
+	// The last line spans several of the blocks bounds reads backwards.
+	s.Append(Entry{Command: "system env", Output: strings.Repeat("x\n", MaxOutput/2)})
+	if e, err := s.Append(Entry{Command: "git log"}); err != nil || e.ID != 3 {
+		t.Errorf("Append = #%d, %v; want #3", e.ID, err)
+	}
+}
+
+func TestConcurrentStoresShareTheLock(t *testing.T) {
+	files := fs.NewMemory()
+	var wg sync.WaitGroup
+	for range 4 {
+		// One store per "process": only the lock file serializes them.
+		s := NewStore(files, testPath)
+		wg.Go(func() {
+			for range 25 {
+				if _, err := s.Append(Entry{Command: "git status"}); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	entries, _ := NewStore(files, testPath).List()
+	if len(entries) != 100 {
+		t.Fatalf("got %d entries, want 100", len(entries))
+	}
+	for i, e := range entries {
+		if e.ID != 100-i {
+			t.Fatalf("entry %d has ID %d, want %d", i, e.ID, 100-i)
+		}
+	}
+}
+
+func TestStaleLockIsBroken(t *testing.T) {
+	files := fs.NewMemory()
+	files.WriteFile(testPath+".lock", nil, 0o600)
+	old := time.Now().Add(-2 * lockStale)
+	files.Afero().Chtimes(testPath+".lock", old, old)
+
+	if _, err := NewStore(files, testPath).Append(Entry{Command: "git status"}); err != nil {
+		t.Errorf("Append with a stale lock: %v", err)
+	}
+	if files.Exists(testPath + ".lock") {
+		t.Error("lock left behind")
+	}
+}
+
+func TestHeldLockTimesOut(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for the lock")
+	}
+	files := fs.NewMemory()
+	files.WriteFile(testPath+".lock", nil, 0o600)
+
+	_, err := NewStore(files, testPath).Append(Entry{Command: "git status"})
+	if err == nil || !strings.Contains(err.Error(), "locked by another avro process") {
+		t.Errorf("Append with a held lock = %v", err)
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	e := Entry{
+		Command: "http get",
+		Args:    []string{"https://api.test/", "two words"},
+		Flags:   map[string]string{"query": "a=1\nb=2", "include": "true"},
+	}
+	want := `http get https://api.test/ "two words" --include=true --query=a=1 --query=b=2`
+	if got := e.CommandLine(); got != want {
+		t.Errorf("CommandLine = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,220 @@
+package manifest
+
+import (
+	"avro_cli/internal/domain"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ArgSpec is the YAML form of a domain.ArgDef, shared by plugin manifests and
+// declarative command files.
+//
+//	args:
+//	  - {name: env, choices: [staging, prod], default: staging}
+//	  - {name: replicas, type: int, min: 1, max: 10}
+//	  - {name: tag, pattern: "v[0-9.]+", repeatable: true}
+//	  - {name: api-token, sensitive: true}   # kept out of logs and history
+type ArgSpec struct {
+	Name        string   `yaml:"name"`
+	Short       string   `yaml:"short"`
+	Description string   `yaml:"description"`
+	Required    bool     `yaml:"required"`
+	Default     string   `yaml:"default"`
+	Type        string   `yaml:"type"` // string (default), bool or int
+	Choices     []string `yaml:"choices"`
+	Pattern     string   `yaml:"pattern"`
+	Min         string   `yaml:"min"`
+	Max         string   `yaml:"max"`
+	Repeatable  bool     `yaml:"repeatable"`
+	Sensitive   bool     `yaml:"sensitive"`
+}
+
+// namePattern restricts command, category and arg names to shell- and flag-safe words.
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateName checks that a category, command or arg name is usable on the command line.
+func ValidateName(field, name string) error {
+	if !namePattern.MatchString(name) {
+		return &domain.ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("%q must be lowercase letters, digits, '-' or '_'", name),
+		}
+	}
+	return nil
+}
+
+// ParseType maps a YAML type name to a domain.ArgType. Empty means string.
+func ParseType(s string) (domain.ArgType, error) {
+	switch strings.ToLower(s) {
+	case "", "string":
+		return domain.ArgString, nil
+	case "bool", "boolean":
+		return domain.ArgBool, nil
+	case "int", "integer":
+		return domain.ArgInt, nil
+	}
+	return domain.ArgString, fmt.Errorf("unknown type %q (expected string, bool or int)", s)
+}
+
+// ArgDef converts the spec, validating its name, shorthand, type, constraints
+// and default.
+func (s ArgSpec) ArgDef() (domain.ArgDef, error) {
+	if err := ValidateName("name", s.Name); err != nil {
+		return domain.ArgDef{}, err
+	}
+	if len(s.Short) > 1 {
+		return domain.ArgDef{}, &domain.ValidationError{
+			Field:   s.Name,
+			Message: fmt.Sprintf("short %q must be a single character", s.Short),
+		}
+	}
+
+	typ, err := ParseType(s.Type)
+	if err != nil {
+		return domain.ArgDef{}, &domain.ValidationError{Field: s.Name, Message: err.Error()}
+	}
+	if err := checkConstraints(typ, s); err != nil {
+		return domain.ArgDef{}, &domain.ValidationError{Field: s.Name, Message: err.Error()}
+	}
+
+	def := domain.ArgDef{
+		Name:        s.Name,
+		Short:       s.Short,
+		Description: s.Description,
+		Required:    s.Required,
+		Default:     s.Default,
+		Type:        typ,
+		Choices:     s.Choices,
+		Pattern:     s.Pattern,
+		Min:         s.Min,
+		Max:         s.Max,
+		Repeatable:  s.Repeatable,
+		Sensitive:   s.Sensitive,
+	}
+	// Every choice must pass the other constraints, or it could never be used.
+	free := def
+	free.Choices = nil
+	for _, c := range s.Choices {
+		if err := checkValue(typ, "choice", c); err != nil {
+			return domain.ArgDef{}, &domain.ValidationError{Field: s.Name, Message: err.Error()}
+		}
+		if err := free.Check(c); err != nil {
+			return domain.ArgDef{}, err
+		}
+	}
+	if s.Default != "" {
+		if err := checkValue(typ, "default", s.Default); err != nil {
+			return domain.ArgDef{}, &domain.ValidationError{Field: s.Name, Message: err.Error()}
+		}
+		if err := def.Check(s.Default); err != nil {
+			return domain.ArgDef{}, err
+		}
+	}
+	return def, nil
+}
+
+// checkConstraints rejects a pattern that does not compile, and bounds that
+// are not integers, are set on a non-int spec or leave no value between them.
+func checkConstraints(typ domain.ArgType, s ArgSpec) error {
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("pattern %q: %v", s.Pattern, err)
+		}
+	}
+	for _, bound := range []struct{ name, value string }{{"min", s.Min}, {"max", s.Max}} {
+		if bound.value == "" {
+			continue
+		}
+		if typ != domain.ArgInt {
+			return fmt.Errorf("%s applies only to int values", bound.name)
+		}
+		if _, err := strconv.Atoi(bound.value); err != nil {
+			return fmt.Errorf("%s %q is not an integer", bound.name, bound.value)
+		}
+	}
+	if s.Min != "" && s.Max != "" {
+		lo, _ := strconv.Atoi(s.Min)
+		hi, _ := strconv.Atoi(s.Max)
+		if lo > hi {
+			return fmt.Errorf("min %d is greater than max %d", lo, hi)
+		}
+	}
+	return nil
+}
+
+// ArgDefs converts a list of specs, rejecting duplicate names and shorthands.
+func ArgDefs(specs []ArgSpec) ([]domain.ArgDef, error) {
+	defs := make([]domain.ArgDef, 0, len(specs))
+	names := make(map[string]bool)
+	shorts := make(map[string]bool)
+	for _, s := range specs {
+		def, err := s.ArgDef()
+		if err != nil {
+			return nil, err
+		}
+		if names[def.Name] {
+			return nil, &domain.ValidationError{Field: def.Name, Message: "declared more than once"}
+		}
+		if def.Short != "" && shorts[def.Short] {
+			return nil, &domain.ValidationError{Field: def.Name, Message: fmt.Sprintf("short %q already used", def.Short)}
+		}
+		names[def.Name] = true
+		if def.Short != "" {
+			shorts[def.Short] = true
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+// PositionalDefs converts a list of positional arg specs like ArgDefs, also
+// rejecting a repeatable arg before the last, or a repeatable last arg when
+// the command takes extraArgs (see domain.CommandDescriptor.CheckArgs).
+func PositionalDefs(specs []ArgSpec, extraArgs bool) ([]domain.ArgDef, error) {
+	defs, err := ArgDefs(specs)
+	if err != nil {
+		return nil, err
+	}
+	if err := (domain.CommandDescriptor{Args: defs, ExtraArgs: extraArgs}).CheckArgs(); err != nil {
+		return nil, err
+	}
+	return defs, nil
+}
+
+// FlagDefs converts a list of flag specs like ArgDefs, also rejecting the
+// names and shorthands of the global flags in domain.ReservedFlags.
+func FlagDefs(specs []ArgSpec) ([]domain.ArgDef, error) {
+	defs, err := ArgDefs(specs)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range defs {
+		for _, r := range domain.ReservedFlags {
+			if def.Name == r.Name {
+				return nil, &domain.ValidationError{Field: def.Name, Message: fmt.Sprintf("--%s is a global flag", r.Name)}
+			}
+			if def.Short != "" && def.Short == r.Short {
+				return nil, &domain.ValidationError{Field: def.Name, Message: fmt.Sprintf("short %q is taken by the global --%s", def.Short, r.Name)}
+			}
+		}
+	}
+	return defs, nil
+}
+
+// checkValue checks that value, a default or choice as named by what, is of
+// type typ.
+func checkValue(typ domain.ArgType, what, value string) error {
+	switch typ {
+	case domain.ArgBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s %q is not a boolean", what, value)
+		}
+	case domain.ArgInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s %q is not an integer", what, value)
+		}
+	}
+	return nil
+}
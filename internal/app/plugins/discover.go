@@ -0,0 +1,300 @@
+package plugins
+
+import (
+	"avro_cli/internal/app/manifest"
+	"avro_cli/internal/domain"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// execPrefix marks executables on $PATH as avro plugins: avro-<category>-<name>.
+const execPrefix = "avro-"
+
+// manifestFile is the name of the manifest inside each plugin directory.
+const manifestFile = "plugin.yaml"
+
+// Manifest describes a plugin directory under ~/.avro/plugins/<plugin>/plugin.yaml.
+//
+//	category: team
+//	category_description: Team tools
+//	name: deploy
+//	aliases: [d]
+//	description: Deploy a service
+//	tags: [release, k8s]
+//	exec: ./deploy.sh   # relative to the plugin directory
+//	input: env          # env (default) or json
+//	timeout: 5m
+//	extra_args: false   # accept positional args beyond args
+//	no_history: false   # keep executions out of the history
+//	args:
+//	  - {name: service, description: Service to deploy, required: true}
+//	flags:
+//	  - {name: dry-run, short: n, type: bool}
+type Manifest struct {
+	Category            string             `yaml:"category"`
+	CategoryDescription string             `yaml:"category_description"`
+	Name                string             `yaml:"name"`
+	Aliases             []string           `yaml:"aliases"`
+	Description         string             `yaml:"description"`
+	Tags                []string           `yaml:"tags"`
+	Exec                string             `yaml:"exec"`
+	Input               string             `yaml:"input"`
+	Timeout             string             `yaml:"timeout"`
+	ExtraArgs           bool               `yaml:"extra_args"`
+	NoHistory           bool               `yaml:"no_history"`
+	Args                []manifest.ArgSpec `yaml:"args"`
+	Flags               []manifest.ArgSpec `yaml:"flags"`
+}
+
+// Discover finds manifest plugins in pluginDir, then avro-<category>-<name>
+// executables on pathEnv. The first plugin found for a given full name wins.
+//
+// Listing every $PATH directory on each invocation, --help and completion
+// included, adds up; when cacheFile is set, the avro-* names found in each
+// directory are kept there and reused until the directory's modification
+// time changes.
+func Discover(pluginDir, pathEnv, cacheFile string) ([]Plugin, []error) {
+	var (
+		found []Plugin
+		errs  []error
+		seen  = make(map[string]bool)
+	)
+	add := func(p Plugin) {
+		if name := p.Command.FullName(); !seen[name] {
+			seen[name] = true
+			found = append(found, p)
+		}
+	}
+
+	entries, _ := os.ReadDir(pluginDir)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		path := filepath.Join(pluginDir, e.Name(), manifestFile)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		p, err := loadManifest(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", path, err))
+			continue
+		}
+		add(p)
+	}
+
+	cache := loadPathCache(cacheFile)
+	for _, dir := range filepath.SplitList(pathEnv) {
+		if dir == "" {
+			continue
+		}
+		for _, name := range cache.candidates(dir) {
+			if p, ok := pathPlugin(dir, name); ok {
+				add(p)
+			}
+		}
+	}
+	cache.save()
+
+	return found, errs
+}
+
+// pathCache records the avro-* entries of $PATH directories, see Discover.
+type pathCache struct {
+	file  string
+	Dirs  map[string]cachedDir `json:"dirs"`
+	dirty bool
+}
+
+type cachedDir struct {
+	ModTime time.Time `json:"mod_time"`
+	Names   []string  `json:"names"`
+}
+
+// loadPathCache reads file, starting empty when it is missing or unreadable.
+// An empty file name gives a cache that remembers nothing.
+func loadPathCache(file string) *pathCache {
+	c := &pathCache{file: file}
+	if file != "" {
+		if data, err := os.ReadFile(file); err == nil {
+			_ = json.Unmarshal(data, c)
+		}
+	}
+	if c.Dirs == nil {
+		c.Dirs = make(map[string]cachedDir)
+	}
+	return c
+}
+
+// candidates returns the names in dir that may be plugins, listing the
+// directory only when it changed since it was cached.
+func (c *pathCache) candidates(dir string) []string {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+	if cached, ok := c.Dirs[dir]; ok && c.file != "" && cached.ModTime.Equal(info.ModTime()) {
+		return cached.Names
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if strings.HasPrefix(strings.ToLower(e.Name()), execPrefix) {
+			names = append(names, e.Name())
+		}
+	}
+	c.Dirs[dir] = cachedDir{ModTime: info.ModTime(), Names: names}
+	c.dirty = true
+	return names
+}
+
+// save writes the cache back if a directory was listed. Failures only cost
+// a rescan next time.
+func (c *pathCache) save() {
+	if c.file == "" || !c.dirty {
+		return
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.file), 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(c.file, data, 0o600)
+}
+
+// loadManifest parses and validates a plugin manifest.
+func loadManifest(path string) (Plugin, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Plugin{}, err
+	}
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return Plugin{}, err
+	}
+
+	if err := manifest.ValidateName("category", m.Category); err != nil {
+		return Plugin{}, err
+	}
+	if err := manifest.ValidateName("name", m.Name); err != nil {
+		return Plugin{}, err
+	}
+	if m.Exec == "" {
+		return Plugin{}, &domain.ValidationError{Field: "exec", Message: "executable path is required"}
+	}
+
+	exe := m.Exec
+	if !filepath.IsAbs(exe) {
+		exe = filepath.Join(filepath.Dir(path), exe)
+	}
+	if !isExecutable(exe) {
+		return Plugin{}, &domain.ValidationError{Field: "exec", Message: fmt.Sprintf("%s is not an executable file", exe)}
+	}
+
+	input := Input(strings.ToLower(m.Input))
+	switch input {
+	case "":
+		input = InputEnv
+	case InputEnv, InputJSON:
+	default:
+		return Plugin{}, &domain.ValidationError{Field: "input", Message: fmt.Sprintf("unknown input %q (expected env or json)", m.Input)}
+	}
+
+	var timeout time.Duration
+	if m.Timeout != "" {
+		if timeout, err = time.ParseDuration(m.Timeout); err != nil {
+			return Plugin{}, &domain.ValidationError{Field: "timeout", Message: err.Error()}
+		}
+	}
+
+	args, err := manifest.PositionalDefs(m.Args, m.ExtraArgs)
+	if err != nil {
+		return Plugin{}, err
+	}
+	flags, err := manifest.FlagDefs(m.Flags)
+	if err != nil {
+		return Plugin{}, err
+	}
+
+	description := m.Description
+	if description == "" {
+		description = "Plugin " + m.Name
+	}
+	categoryDescription := m.CategoryDescription
+	if categoryDescription == "" {
+		categoryDescription = "Plugin commands"
+	}
+
+	p := Plugin{Path: exe, Source: path, Input: input}
+	p.Command = domain.CommandDescriptor{
+		Category:    domain.Category{Name: m.Category, Description: categoryDescription},
+		Name:        m.Name,
+		Aliases:     m.Aliases,
+		Description: description,
+		Tags:        m.Tags,
+		Args:        args,
+		ExtraArgs:   m.ExtraArgs,
+		Flags:       flags,
+		Timeout:     timeout,
+		NoHistory:   m.NoHistory,
+	}
+	// Bound after Command is set so the action sees the repeatable defs.
+	p.Command.Action = p.action
+	return p, nil
+}
+
+// pathPlugin recognizes an avro-<category>-<name> executable named file in
+// dir. Such plugins declare nothing, so every argument is passed straight
+// through.
+func pathPlugin(dir, file string) (Plugin, bool) {
+	name := file
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	}
+	rest, ok := strings.CutPrefix(name, execPrefix)
+	if !ok {
+		return Plugin{}, false
+	}
+	category, cmdName, ok := strings.Cut(rest, "-")
+	if !ok || manifest.ValidateName("category", category) != nil || manifest.ValidateName("name", cmdName) != nil {
+		return Plugin{}, false
+	}
+
+	path := filepath.Join(dir, file)
+	if !isExecutable(path) {
+		return Plugin{}, false
+	}
+
+	p := Plugin{Path: path, Source: path, Input: InputEnv}
+	p.Command = domain.CommandDescriptor{
+		Category:    domain.Category{Name: category, Description: "Plugin commands"},
+		Name:        cmdName,
+		Description: "Plugin " + path,
+		ExtraArgs:   true,
+		Action:      p.action,
+	}
+	return p, true
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode()&0o111 != 0
+}
@@ -0,0 +1,130 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeExecutable(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeManifest(t *testing.T, pluginDir, plugin, manifest string) {
+	t.Helper()
+	dir := filepath.Join(pluginDir, plugin)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeExecutable(t, dir, "run.sh")
+	if err := os.WriteFile(filepath.Join(dir, manifestFile), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func names(plugins []Plugin) []string {
+	var out []string
+	for _, p := range plugins {
+		out = append(out, p.Command.FullName())
+	}
+	return out
+}
+
+func TestDiscover(t *testing.T) {
+	pluginDir := t.TempDir()
+	writeManifest(t, pluginDir, "deploy", "category: team\nname: deploy\nexec: ./run.sh\ninput: json\ntimeout: 5m\nargs:\n  - {name: service, required: true}\n")
+	writeManifest(t, pluginDir, "noexec", "category: team\nname: broken\n")
+	writeManifest(t, pluginDir, "badinput", "category: team\nname: odd\nexec: ./run.sh\ninput: xml\n")
+	writeManifest(t, pluginDir, "badname", "category: Team Tools\nname: x\nexec: ./run.sh\n")
+	os.WriteFile(filepath.Join(pluginDir, "stray.yaml"), []byte("category: team\n"), 0o644)
+
+	bin1, bin2 := t.TempDir(), t.TempDir()
+	writeExecutable(t, bin1, "avro-team-lint")
+	writeExecutable(t, bin1, "avro-team-deploy") // shadowed by the manifest
+	writeExecutable(t, bin1, "avro-nodash")
+	writeExecutable(t, bin1, "other-tool")
+	os.WriteFile(filepath.Join(bin1, "avro-team-notes"), []byte("text"), 0o644)
+	writeExecutable(t, bin2, "avro-team-lint") // shadowed by bin1
+	writeExecutable(t, bin2, "avro-db-dump")
+
+	pathEnv := strings.Join([]string{bin1, "", filepath.Join(bin1, "missing"), bin2}, string(os.PathListSeparator))
+	found, errs := Discover(pluginDir, pathEnv, "")
+
+	if got, want := names(found), []string{"team deploy", "team lint", "db dump"}; !slices.Equal(got, want) {
+		t.Errorf("found %q, want %q", got, want)
+	}
+	for _, p := range found {
+		switch p.Command.FullName() {
+		case "team deploy":
+			if p.Input != InputJSON || p.Command.Timeout != 5*time.Minute || p.Path != filepath.Join(pluginDir, "deploy", "run.sh") {
+				t.Errorf("manifest plugin = %+v", p)
+			}
+		case "team lint":
+			if filepath.Dir(p.Path) != bin1 {
+				t.Errorf("team lint from %s, want the first PATH entry", p.Path)
+			}
+		}
+	}
+
+	var msgs []string
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	slices.Sort(msgs)
+	// Sorted by manifest path: badinput, badname, noexec.
+	want := []string{`unknown input "xml"`, `validation error for "category"`, "executable path is required"}
+	if len(msgs) != len(want) {
+		t.Fatalf("errors %q, want %d", msgs, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(msgs[i], w) {
+			t.Errorf("error %q does not contain %q", msgs[i], w)
+		}
+	}
+}
+
+func TestDiscoverPathCache(t *testing.T) {
+	bin := t.TempDir()
+	cache := filepath.Join(t.TempDir(), "cache.json")
+	writeExecutable(t, bin, "avro-team-lint")
+	listed, _ := os.Stat(bin)
+
+	found, _ := Discover("", bin, cache)
+	if got := names(found); !slices.Equal(got, []string{"team lint"}) {
+		t.Fatalf("found %q", got)
+	}
+	if info, err := os.Stat(cache); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("cache file: %v", err)
+	}
+
+	// A new file behind an unchanged modification time stays unseen...
+	writeExecutable(t, bin, "avro-team-fmt")
+	os.Chtimes(bin, listed.ModTime(), listed.ModTime())
+	found, _ = Discover("", bin, cache)
+	if got := names(found); !slices.Equal(got, []string{"team lint"}) {
+		t.Errorf("found %q with a fresh cache, want the cached names only", got)
+	}
+
+	// ...until the directory changes again.
+	later := listed.ModTime().Add(time.Second)
+	os.Chtimes(bin, later, later)
+	found, _ = Discover("", bin, cache)
+	if got := names(found); !slices.Equal(got, []string{"team fmt", "team lint"}) {
+		t.Errorf("found %q after the dir changed", got)
+	}
+
+	// Without a cache file every call lists the directory.
+	writeExecutable(t, bin, "avro-team-vet")
+	os.Chtimes(bin, listed.ModTime(), listed.ModTime())
+	if found, _ := Discover("", bin, ""); len(found) != 3 {
+		t.Errorf("found %q without a cache", names(found))
+	}
+}
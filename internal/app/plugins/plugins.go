@@ -0,0 +1,139 @@
+package plugins
+
+import (
+	"avro_cli/internal/app/registry"
+	"avro_cli/internal/config"
+	"avro_cli/internal/domain"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Input selects how a plugin receives its resolved args and flags.
+type Input string
+
+const (
+	// InputEnv passes values as AVRO_ARG_<NAME> and AVRO_FLAG_<NAME> environment
+	// variables. The values of a repeatable arg or flag are newline-separated,
+	// so plugins taking values with line breaks should use InputJSON.
+	InputEnv Input = "env"
+	// InputJSON writes {"args": {...}, "flags": {...}, "extra": [...]} to the
+	// plugin's stdin. Repeatable args and flags are arrays of strings.
+	InputJSON Input = "json"
+)
+
+// Plugin is an external executable exposed as an avro command.
+type Plugin struct {
+	Path    string // absolute path of the executable
+	Source  string // manifest file or PATH entry it was found through
+	Input   Input
+	Command domain.CommandDescriptor
+}
+
+// Dir returns the directory scanned for manifest plugins (~/.avro/plugins).
+func Dir() string {
+	return filepath.Join(config.Dir(), "plugins")
+}
+
+// cacheFile keeps the $PATH scan between invocations; see Discover.
+func cacheFile() string {
+	return filepath.Join(config.Dir(), "plugin_path_cache.json")
+}
+
+// Load discovers plugins in Dir() and on $PATH and registers them into reg.
+// Returned errors describe plugins that were skipped; the rest still load.
+func Load(reg *registry.Registry) []error {
+	found, errs := Discover(Dir(), os.Getenv("PATH"), cacheFile())
+	return append(errs, Register(reg, found)...)
+}
+
+// Register adds plugins to reg. A plugin whose name or alias collides with an
+// existing command is skipped so plugins can never shadow built-ins.
+func Register(reg *registry.Registry, plugins []Plugin) []error {
+	var errs []error
+	for _, p := range plugins {
+		if err := reg.RegisterUnique(p.Command); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", p.Source, err))
+		}
+	}
+	return errs
+}
+
+// action runs the plugin executable, streaming its output.
+func (p Plugin) action(ctx domain.CommandContext) domain.Result[string] {
+	opts := domain.StreamOptions{
+		Stdout: ctx.Output.Stdout,
+		Stderr: ctx.Output.Stderr,
+	}
+
+	switch p.Input {
+	case InputJSON:
+		extra := ctx.Extra
+		if extra == nil {
+			extra = []string{}
+		}
+		payload, err := json.Marshal(struct {
+			Args  map[string]any `json:"args"`
+			Flags map[string]any `json:"flags"`
+			Extra []string       `json:"extra"`
+		}{jsonValues(ctx.Args, p.Command.Args), jsonValues(ctx.Flags, p.Command.Flags), extra})
+		if err != nil {
+			return domain.Fail[string](err)
+		}
+		opts.Stdin = bytes.NewReader(payload)
+	default:
+		opts.Env = p.envVars(ctx)
+	}
+
+	if err := ctx.Shell.RunStream(ctx.Context, opts, p.Path, ctx.Extra...); err != nil {
+		return domain.Fail[string](err)
+	}
+	return domain.Ok("")
+}
+
+// jsonValues returns values for the JSON payload, with those of repeatable
+// defs split into arrays.
+func jsonValues(values map[string]string, defs []domain.ArgDef) map[string]any {
+	out := make(map[string]any, len(values))
+	for name, val := range values {
+		out[name] = val
+	}
+	for _, def := range defs {
+		if val, ok := values[def.Name]; ok && def.Repeatable {
+			out[def.Name] = append([]string{}, domain.SplitValues(val)...)
+		}
+	}
+	return out
+}
+
+// envVars encodes resolved values as AVRO_ARG_*/AVRO_FLAG_* variables.
+func (p Plugin) envVars(ctx domain.CommandContext) []string {
+	var env []string
+	for name, val := range envValues(ctx.Args, p.Command.Args) {
+		env = append(env, "AVRO_ARG_"+envName(name)+"="+val)
+	}
+	for name, val := range envValues(ctx.Flags, p.Command.Flags) {
+		env = append(env, "AVRO_FLAG_"+envName(name)+"="+val)
+	}
+	return env
+}
+
+// envValues returns values for the environment, with those of repeatable
+// defs decoded and joined by plain line breaks.
+func envValues(values map[string]string, defs []domain.ArgDef) map[string]string {
+	out := maps.Clone(values)
+	for _, def := range defs {
+		if val, ok := values[def.Name]; ok && def.Repeatable {
+			out[def.Name] = strings.Join(domain.SplitValues(val), "\n")
+		}
+	}
+	return out
+}
+
+func envName(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
@@ -0,0 +1,110 @@
+package plugins
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/testkit"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// deployPlugin returns a plugin with a required arg, a repeatable last arg,
+// a bool flag and a repeatable flag, reading its values through input.
+func deployPlugin(input Input) Plugin {
+	p := Plugin{Path: "/opt/deploy/run", Source: "/opt/deploy/plugin.yaml", Input: input}
+	p.Command = domain.CommandDescriptor{
+		Category: domain.Category{Name: "team"},
+		Name:     "deploy",
+		Args: []domain.ArgDef{
+			{Name: "service", Required: true},
+			{Name: "hosts", Repeatable: true},
+		},
+		Flags: []domain.ArgDef{
+			{Name: "dry-run", Type: domain.ArgBool},
+			{Name: "label", Repeatable: true},
+		},
+	}
+	p.Command.Action = p.action
+	return p
+}
+
+var deployFlags = map[string]string{"dry-run": "true", "label": domain.JoinValues([]string{"a=1", "b=2"})}
+
+func TestEnvInput(t *testing.T) {
+	env := testkit.New(t)
+	env.Shell.Expect("/opt/deploy/run").Stdout("deployed\n")
+
+	out := env.Run(deployPlugin(InputEnv).Command, []string{"api", "web1", "web2"}, deployFlags).OK()
+	if out.Stdout() != "deployed" {
+		t.Errorf("stdout = %q, want the plugin's output", out.Stdout())
+	}
+
+	got := env.Shell.Calls()[0].Env
+	slices.Sort(got)
+	want := []string{
+		"AVRO_ARG_HOSTS=web1\nweb2",
+		"AVRO_ARG_SERVICE=api",
+		"AVRO_FLAG_DRY_RUN=true",
+		"AVRO_FLAG_LABEL=a=1\nb=2",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("env = %q, want %q", got, want)
+	}
+	if stdin := env.Shell.Calls()[0].Stdin; stdin != "" {
+		t.Errorf("env plugin got stdin %q", stdin)
+	}
+}
+
+func TestJSONInput(t *testing.T) {
+	env := testkit.New(t)
+	env.Shell.Expect("/opt/deploy/run")
+
+	env.Run(deployPlugin(InputJSON).Command, []string{"api", "web1", "web2"}, deployFlags).OK()
+
+	call := env.Shell.Calls()[0]
+	if len(call.Env) != 0 {
+		t.Errorf("json plugin got env %q", call.Env)
+	}
+	var payload struct {
+		Args  map[string]any `json:"args"`
+		Flags map[string]any `json:"flags"`
+		Extra []string       `json:"extra"`
+	}
+	if err := json.Unmarshal([]byte(call.Stdin), &payload); err != nil {
+		t.Fatalf("stdin %q: %v", call.Stdin, err)
+	}
+	if payload.Args["service"] != "api" || payload.Flags["dry-run"] != "true" {
+		t.Errorf("payload = %+v", payload)
+	}
+	for key, want := range map[string][]string{"hosts": {"web1", "web2"}, "label": {"a=1", "b=2"}} {
+		values := payload.Args[key]
+		if values == nil {
+			values = payload.Flags[key]
+		}
+		list, _ := values.([]any)
+		if len(list) != len(want) || list[0] != want[0] || list[1] != want[1] {
+			t.Errorf("%s = %#v, want the array %q", key, values, want)
+		}
+	}
+	if payload.Extra == nil || len(payload.Extra) != 0 {
+		t.Errorf("extra = %#v, want an empty array", payload.Extra)
+	}
+}
+
+func TestPathPluginPassesArgsThrough(t *testing.T) {
+	p, ok := pathPlugin(t.TempDir(), "avro-team-lint")
+	if ok {
+		t.Fatalf("a missing file was taken for a plugin: %+v", p)
+	}
+
+	dir := t.TempDir()
+	writeExecutable(t, dir, "avro-team-lint")
+	p, ok = pathPlugin(dir, "avro-team-lint")
+	if !ok || p.Command.FullName() != "team lint" || !p.Command.ExtraArgs {
+		t.Fatalf("pathPlugin = %+v, %v", p.Command, ok)
+	}
+
+	env := testkit.New(t)
+	env.Shell.Expect(p.Path, "--fix", "./...")
+	env.Run(p.Command, []string{"--fix", "./..."}, nil).OK()
+}
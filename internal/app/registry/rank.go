@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"math"
+	"sort"
+)
+
+// recentLimit is how many recently run commands lead an empty-query ranking.
+const recentLimit = 5
+
+// Usage describes how the user works with commands, keyed by full name, so
+// rankings can favor what they actually run.
+type Usage struct {
+	Frecency map[string]float64 // see history.Frecency
+	Recent   []string           // most recently run first
+	Pinned   []string           // in pin order
+}
+
+// RankedSearch is Search biased by usage. Pinned matches always come
+// first, in pin order; the rest are ordered by search score plus a bonus that
+// grows logarithmically with frecency, so heavy use lifts a command past
+// slightly better matches without burying exact ones.
+//
+// With an empty query the result is the pinned commands, then up to
+// recentLimit recent ones, then everything else in registration order, with
+// Pinned and Recent set so callers can label the sections.
+func (r *Registry) RankedSearch(query string, u Usage) []SearchResult {
+	results := r.Search(query)
+
+	pinOrder := make(map[string]int, len(u.Pinned))
+	for i, name := range u.Pinned {
+		pinOrder[name] = i
+	}
+	recentOrder := make(map[string]int, recentLimit)
+	if query == "" {
+		for _, name := range u.Recent {
+			if _, pinned := pinOrder[name]; !pinned && len(recentOrder) < recentLimit {
+				recentOrder[name] = len(recentOrder)
+			}
+		}
+	}
+
+	rank := make([]float64, len(results))
+	for i := range results {
+		name := results[i].Command.FullName()
+		_, results[i].Pinned = pinOrder[name]
+		_, results[i].Recent = recentOrder[name]
+		if query != "" {
+			rank[i] = float64(results[i].Score) + 5*math.Log1p(u.Frecency[name])
+		}
+	}
+
+	idx := make([]int, len(results))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		x, y := results[idx[a]], results[idx[b]]
+		if x.Pinned != y.Pinned {
+			return x.Pinned
+		}
+		if x.Pinned {
+			return pinOrder[x.Command.FullName()] < pinOrder[y.Command.FullName()]
+		}
+		if x.Recent != y.Recent {
+			return x.Recent
+		}
+		if x.Recent {
+			return recentOrder[x.Command.FullName()] < recentOrder[y.Command.FullName()]
+		}
+		return rank[idx[a]] > rank[idx[b]]
+	})
+
+	out := make([]SearchResult, len(results))
+	for i, j := range idx {
+		out[i] = results[j]
+	}
+	return out
+}
@@ -2,11 +2,9 @@ package registry
 
 import (
 	"avro_cli/internal/domain"
+	"fmt"
 	"sort"
-	"strings"
 	"sync"
-
-	"github.com/sahilm/fuzzy"
 )
 
 var (
@@ -40,6 +38,36 @@ func (r *Registry) Register(cmds ...domain.CommandDescriptor) {
 	r.commands = append(r.commands, cmds...)
 }
 
+// RegisterUnique adds an externally defined command (plugin, config file),
+// refusing it if its name or an alias is already taken in its category so it
+// can never shadow a built-in. A command joining an existing category adopts
+// that category's metadata.
+func (r *Registry) RegisterUnique(cmd domain.CommandDescriptor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := append([]string{cmd.Name}, cmd.Aliases...)
+	for _, c := range r.commands {
+		if c.Category.Name != cmd.Category.Name {
+			continue
+		}
+		for _, n := range names {
+			if c.Name == n || containsAlias(c.Aliases, n) {
+				return fmt.Errorf("%q conflicts with existing command %q", cmd.FullName(), c.FullName())
+			}
+		}
+	}
+	for _, c := range r.commands {
+		if c.Category.Name == cmd.Category.Name {
+			cmd.Category = c.Category
+			break
+		}
+	}
+
+	r.commands = append(r.commands, cmd)
+	return nil
+}
+
 // All returns every registered command.
 func (r *Registry) All() []domain.CommandDescriptor {
 	r.mu.RLock()
@@ -99,62 +127,6 @@ func (r *Registry) Find(category, name string) (domain.CommandDescriptor, bool)
 	return domain.CommandDescriptor{}, false
 }
 
-// Search returns commands whose full name or description matches the query (case-insensitive).
-func (r *Registry) Search(query string) []domain.CommandDescriptor {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	q := strings.ToLower(query)
-	var out []domain.CommandDescriptor
-	for _, c := range r.commands {
-		if strings.Contains(strings.ToLower(c.FullName()), q) ||
-			strings.Contains(strings.ToLower(c.Description), q) {
-			out = append(out, c)
-		}
-	}
-	return out
-}
-
-// commandSource implements fuzzy.Source over a slice of CommandDescriptors.
-type commandSource []domain.CommandDescriptor
-
-func (s commandSource) String(i int) string { return s[i].FullName() }
-func (s commandSource) Len() int            { return len(s) }
-
-// FuzzySearch returns commands ranked by fuzzy match quality.
-func (r *Registry) FuzzySearch(query string) []FuzzyResult {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	if query == "" {
-		out := make([]FuzzyResult, len(r.commands))
-		for i, c := range r.commands {
-			out[i] = FuzzyResult{Command: c}
-		}
-		return out
-	}
-
-	src := commandSource(r.commands)
-	matches := fuzzy.FindFrom(query, src)
-
-	out := make([]FuzzyResult, len(matches))
-	for i, m := range matches {
-		out[i] = FuzzyResult{
-			Command:        r.commands[m.Index],
-			MatchedIndexes: m.MatchedIndexes,
-			Score:          m.Score,
-		}
-	}
-	return out
-}
-
-// FuzzyResult holds a command and its fuzzy match metadata.
-type FuzzyResult struct {
-	Command        domain.CommandDescriptor
-	MatchedIndexes []int
-	Score          int
-}
-
 func containsAlias(aliases []string, name string) bool {
 	for _, a := range aliases {
 		if a == name {
@@ -0,0 +1,109 @@
+package registry_test
+
+import (
+	"avro_cli/internal/app/registry"
+	"avro_cli/internal/domain"
+	"slices"
+	"testing"
+)
+
+var git = domain.Category{Name: "git", Description: "Git commands"}
+
+func TestRegisterUnique(t *testing.T) {
+	r := registry.New()
+	r.Register(domain.CommandDescriptor{Category: git, Name: "status", Aliases: []string{"st"}})
+
+	tests := []struct {
+		name    string
+		cmd     domain.CommandDescriptor
+		wantErr bool
+	}{
+		{"same name", domain.CommandDescriptor{Category: domain.Category{Name: "git"}, Name: "status"}, true},
+		{"name taken as alias", domain.CommandDescriptor{Category: domain.Category{Name: "git"}, Name: "st"}, true},
+		{"alias taken as name", domain.CommandDescriptor{Category: domain.Category{Name: "git"}, Name: "stat", Aliases: []string{"status"}}, true},
+		{"other category", domain.CommandDescriptor{Category: domain.Category{Name: "svn"}, Name: "status"}, false},
+		{"new name", domain.CommandDescriptor{Category: domain.Category{Name: "git", Description: "mine"}, Name: "wip"}, false},
+	}
+	for _, tt := range tests {
+		if err := r.RegisterUnique(tt.cmd); (err != nil) != tt.wantErr {
+			t.Errorf("%s: RegisterUnique() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// A command joining a category adopts its metadata.
+	for _, c := range r.All() {
+		if c.Name == "wip" && c.Category != git {
+			t.Errorf("wip category = %+v, want %+v", c.Category, git)
+		}
+	}
+}
+
+func names(results []registry.SearchResult) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.Command.FullName()
+	}
+	return out
+}
+
+func searchRegistry() *registry.Registry {
+	r := registry.New()
+	r.Register(
+		domain.CommandDescriptor{Category: git, Name: "status", Description: "Show the working tree status"},
+		domain.CommandDescriptor{Category: git, Name: "stash", Description: "Stash changes"},
+		domain.CommandDescriptor{Category: git, Name: "log", Description: "Show commits", Tags: []string{"history"}},
+		domain.CommandDescriptor{Category: domain.Category{Name: "system"}, Name: "info", Description: "Show system status"},
+	)
+	return r
+}
+
+func TestSearchPrefersNames(t *testing.T) {
+	got := names(searchRegistry().Search("status"))
+	if len(got) < 2 || got[0] != "git status" {
+		t.Fatalf("Search(status) = %q, want git status first", got)
+	}
+	// "system info" only matches in its description, below the name match.
+	for _, n := range got[1:] {
+		if n == "git status" {
+			t.Errorf("git status listed twice: %q", got)
+		}
+	}
+}
+
+func TestSearchMatchesTags(t *testing.T) {
+	results := searchRegistry().Search("history")
+	if len(results) != 1 || results[0].Command.Name != "log" || results[0].Field != registry.FieldTag {
+		t.Errorf("Search(history) = %+v, want git log by tag", results)
+	}
+}
+
+func TestRankedSearch(t *testing.T) {
+	r := searchRegistry()
+
+	// Frecency lifts a command past a slightly better match.
+	if got := names(r.RankedSearch("st", registry.Usage{})); got[0] != "git stash" {
+		t.Fatalf("RankedSearch(st) = %q, want git stash first", got)
+	}
+	u := registry.Usage{Frecency: map[string]float64{"git status": 1000}}
+	if got := names(r.RankedSearch("st", u)); got[0] != "git status" {
+		t.Errorf("RankedSearch(st) with status used = %q, want git status first", got)
+	}
+
+	// Pinned commands lead in pin order, whatever the score.
+	u = registry.Usage{Pinned: []string{"system info", "git log"}}
+	got := r.RankedSearch("s", u)
+	if n := names(got); len(n) < 2 || n[0] != "system info" || n[1] != "git log" || !got[0].Pinned {
+		t.Errorf("RankedSearch(s) with pins = %q, want system info, git log first", n)
+	}
+
+	// An empty query lists pinned, then recent, then the rest in order.
+	u = registry.Usage{Pinned: []string{"git log"}, Recent: []string{"system info", "git log"}}
+	got = r.RankedSearch("", u)
+	want := []string{"git log", "system info", "git status", "git stash"}
+	if n := names(got); !slices.Equal(n, want) {
+		t.Errorf("RankedSearch(\"\") = %q, want %q", n, want)
+	}
+	if !got[1].Recent || got[0].Recent {
+		t.Errorf("Recent flags = %v, %v; want false, true", got[0].Recent, got[1].Recent)
+	}
+}
@@ -0,0 +1,161 @@
+package registry
+
+import (
+	"avro_cli/internal/domain"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// Field identifies the part of a command a search query matched.
+type Field int
+
+const (
+	FieldName        Field = iota // full name, "category name"
+	FieldAlias                    // "category alias"
+	FieldTag                      // one of CommandDescriptor.Tags
+	FieldArg                      // "<arg>" or "--flag"
+	FieldDescription              // CommandDescriptor.Description
+)
+
+// fieldWeight is added to a match's score so that, for equally good matches,
+// names rank above aliases, then tags, arg and flag names and descriptions.
+var fieldWeight = map[Field]int{
+	FieldName:        30,
+	FieldAlias:       25,
+	FieldTag:         20,
+	FieldArg:         10,
+	FieldDescription: 5,
+}
+
+// SearchResult is a command matching a query.
+type SearchResult struct {
+	Command        domain.CommandDescriptor
+	Field          Field  // the best-scoring field
+	Text           string // that field's text, e.g. the alias or "--flag"
+	MatchedIndexes []int  // byte offsets of the matched characters in Text
+	Score          int
+	Pinned         bool // set by RankedSearch
+	Recent         bool // set by RankedSearch for an empty query
+}
+
+// searchEntry is one searchable text of the command at index cmd.
+type searchEntry struct {
+	cmd   int
+	field Field
+	text  string
+}
+
+// fuzzySource implements fuzzy.Source over name and alias entries.
+type fuzzySource []searchEntry
+
+func (s fuzzySource) String(i int) string { return s[i].text }
+func (s fuzzySource) Len() int            { return len(s) }
+
+// Search ranks commands against query across their full name, aliases, tags,
+// arg and flag names and description. Names and aliases match fuzzily; the
+// other fields are free text in which scattered letters match almost
+// anything, so they need the query as a case-insensitive substring. Each
+// command is scored by its best field. An empty query returns every command
+// in registration order.
+func (r *Registry) Search(query string) []SearchResult {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if query == "" {
+		out := make([]SearchResult, len(r.commands))
+		for i, c := range r.commands {
+			out[i] = SearchResult{Command: c, Field: FieldName, Text: c.FullName()}
+		}
+		return out
+	}
+
+	var names, texts []searchEntry
+	for i, c := range r.commands {
+		names = append(names, searchEntry{i, FieldName, c.FullName()})
+		for _, a := range c.Aliases {
+			names = append(names, searchEntry{i, FieldAlias, c.Category.Name + " " + a})
+		}
+		for _, t := range c.Tags {
+			texts = append(texts, searchEntry{i, FieldTag, t})
+		}
+		for _, a := range c.Args {
+			texts = append(texts, searchEntry{i, FieldArg, "<" + a.Name + ">"})
+		}
+		for _, f := range c.Flags {
+			texts = append(texts, searchEntry{i, FieldArg, "--" + f.Name})
+		}
+		texts = append(texts, searchEntry{i, FieldDescription, c.Description})
+	}
+
+	best := make(map[int]SearchResult)
+	consider := func(e searchEntry, matched []int, score int) {
+		score += fieldWeight[e.field]
+		// On a tie the field considered first, and so weighted higher, wins.
+		if cur, ok := best[e.cmd]; ok && cur.Score >= score {
+			return
+		}
+		best[e.cmd] = SearchResult{
+			Command:        r.commands[e.cmd],
+			Field:          e.field,
+			Text:           e.text,
+			MatchedIndexes: matched,
+			Score:          score,
+		}
+	}
+	for _, m := range fuzzy.FindFrom(query, fuzzySource(names)) {
+		consider(names[m.Index], m.MatchedIndexes, m.Score)
+	}
+	for _, e := range texts {
+		if matched, score, ok := substringMatch(e.text, query); ok {
+			consider(e, matched, score)
+		}
+	}
+
+	cmds := make([]int, 0, len(best))
+	for i := range best {
+		cmds = append(cmds, i)
+	}
+	sort.Slice(cmds, func(a, b int) bool {
+		x, y := best[cmds[a]], best[cmds[b]]
+		if x.Score != y.Score {
+			return x.Score > y.Score
+		}
+		return cmds[a] < cmds[b]
+	})
+	out := make([]SearchResult, len(cmds))
+	for i, c := range cmds {
+		out[i] = best[c]
+	}
+	return out
+}
+
+// substringMatch finds query in text ignoring case, scoring matches that
+// start a word, and more so ones covering the whole text, above the rest.
+func substringMatch(text, query string) ([]int, int, bool) {
+	lower := strings.ToLower(text)
+	pos := strings.Index(lower, strings.ToLower(query))
+	// ToLower can change byte lengths outside ASCII; offsets are then unreliable.
+	if pos < 0 || len(lower) != len(text) {
+		return nil, 0, false
+	}
+
+	score := 0
+	if pos == 0 {
+		score += 10
+	} else if prev, _ := utf8.DecodeLastRuneInString(text[:pos]); !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		score += 10
+	}
+	if len(query) == len(text) {
+		score += 10
+	}
+
+	var matched []int
+	for i := range text[pos : pos+len(query)] {
+		matched = append(matched, pos+i)
+	}
+	return matched, score, true
+}
@@ -0,0 +1,180 @@
+package usercmds
+
+import (
+	"avro_cli/internal/app/manifest"
+	"avro_cli/internal/domain"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// File is the schema of a commands.yaml file.
+//
+//	commands:
+//	  - category: dev
+//	    category_description: Project shortcuts
+//	    name: serve
+//	    aliases: [s]
+//	    description: Run the dev server
+//	    tags: [web, local]
+//	    run: go run ./cmd/server --port {{.Args.port}}{{if .Flags.race}} -race{{end}}
+//	    timeout: 10m
+//	    no_history: false   # keep executions out of the history
+//	    args:
+//	      - {name: port, type: int, default: "8080"}
+//	    flags:
+//	      - {name: race, short: r, type: bool}
+//
+// run is a Go text/template executed with .Args and .Flags (maps keyed by
+// name, holding string, bool or int values, or string slices for repeatable
+// ones) and .Extra (positional args beyond
+// args, when extra_args is set). The result runs through sh -c (cmd /C on
+// Windows). Values print quoted as single shell words, so user input cannot
+// inject commands; {{raw .Args.x}} inserts a value as typed, for values that
+// are meant to be shell syntax.
+type File struct {
+	Commands []Spec `yaml:"commands"`
+}
+
+// Spec is one entry of a commands.yaml file.
+type Spec struct {
+	Category            string             `yaml:"category"`
+	CategoryDescription string             `yaml:"category_description"`
+	Name                string             `yaml:"name"`
+	Aliases             []string           `yaml:"aliases"`
+	Description         string             `yaml:"description"`
+	Tags                []string           `yaml:"tags"`
+	Run                 string             `yaml:"run"`
+	Timeout             string             `yaml:"timeout"`
+	ExtraArgs           bool               `yaml:"extra_args"`
+	NoHistory           bool               `yaml:"no_history"`
+	Args                []manifest.ArgSpec `yaml:"args"`
+	Flags               []manifest.ArgSpec `yaml:"flags"`
+}
+
+// Parse reads the command file path, whose content is data, and returns its
+// valid commands. Each invalid entry yields an error naming the file and entry.
+func Parse(path string, data []byte) ([]Command, []error) {
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, []error{fmt.Errorf("%s: %w", path, err)}
+	}
+
+	var (
+		cmds []Command
+		errs []error
+		seen = make(map[string]bool)
+	)
+	for i, spec := range f.Commands {
+		c, err := spec.command(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: command #%d%s: %w", path, i+1, spec.label(), err))
+			continue
+		}
+		name := c.Command.FullName()
+		if seen[name] {
+			errs = append(errs, fmt.Errorf("%s: command #%d%s: defined more than once", path, i+1, spec.label()))
+			continue
+		}
+		seen[name] = true
+		cmds = append(cmds, c)
+	}
+	return cmds, errs
+}
+
+// label names the entry in errors as far as it can be identified.
+func (s Spec) label() string {
+	name := strings.TrimSpace(s.Category + " " + s.Name)
+	if name == "" {
+		return ""
+	}
+	return " (" + name + ")"
+}
+
+// command validates the spec and builds its descriptor.
+func (s Spec) command(source string) (Command, error) {
+	if err := manifest.ValidateName("category", s.Category); err != nil {
+		return Command{}, err
+	}
+	if err := manifest.ValidateName("name", s.Name); err != nil {
+		return Command{}, err
+	}
+	for _, a := range s.Aliases {
+		if err := manifest.ValidateName("aliases", a); err != nil {
+			return Command{}, err
+		}
+	}
+	if strings.TrimSpace(s.Run) == "" {
+		return Command{}, &domain.ValidationError{Field: "run", Message: "command line template is required"}
+	}
+
+	var timeout time.Duration
+	if s.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(s.Timeout); err != nil {
+			return Command{}, &domain.ValidationError{Field: "timeout", Message: err.Error()}
+		}
+	}
+
+	args, err := manifest.PositionalDefs(s.Args, s.ExtraArgs)
+	if err != nil {
+		return Command{}, err
+	}
+	flags, err := manifest.FlagDefs(s.Flags)
+	if err != nil {
+		return Command{}, err
+	}
+	for _, f := range flags {
+		for _, a := range args {
+			if f.Name == a.Name {
+				return Command{}, &domain.ValidationError{Field: f.Name, Message: "declared as both an arg and a flag"}
+			}
+		}
+	}
+
+	tmpl, err := template.New(s.Name).Funcs(funcs).Option("missingkey=error").Parse(s.Run)
+	if err != nil {
+		return Command{}, &domain.ValidationError{Field: "run", Message: err.Error()}
+	}
+
+	description := s.Description
+	if description == "" {
+		description = s.Run
+	}
+	categoryDescription := s.CategoryDescription
+	if categoryDescription == "" {
+		categoryDescription = "User commands"
+	}
+
+	c := Command{Source: source, tmpl: tmpl}
+	c.Command = domain.CommandDescriptor{
+		Category:    domain.Category{Name: s.Category, Description: categoryDescription},
+		Name:        s.Name,
+		Aliases:     s.Aliases,
+		Description: description,
+		Tags:        s.Tags,
+		Args:        args,
+		ExtraArgs:   s.ExtraArgs,
+		Flags:       flags,
+		Timeout:     timeout,
+		NoHistory:   s.NoHistory,
+	}
+	// Bound after the descriptor is complete: the action reads it for names and types.
+	c.Command.Action = c.action
+
+	// Render once with zero values so references to undeclared args or flags
+	// are reported at load time rather than when the command runs.
+	data, _ := templateData(c.Command, domain.CommandContext{})
+	if err := c.tmpl.Execute(io.Discard, data); err != nil {
+		return Command{}, &domain.ValidationError{Field: "run", Message: err.Error()}
+	}
+	return c, nil
+}
@@ -0,0 +1,70 @@
+package usercmds
+
+import (
+	"avro_cli/internal/config"
+	"avro_cli/internal/domain"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+)
+
+// TrustFile returns the path of the list of trusted project command files
+// (~/.avro/trusted_commands). Each line holds the SHA-256 of a file's
+// content and its absolute path.
+func TrustFile() string {
+	return filepath.Join(config.Dir(), "trusted_commands")
+}
+
+// Trusted reports whether the project command file path was trusted with
+// exactly data as its content. Any edit withdraws the trust until the file
+// is reviewed and trusted again.
+func Trusted(fs domain.FileSystem, path string, data []byte) bool {
+	sum, ok := readTrust(fs)[path]
+	return ok && sum == digest(data)
+}
+
+// Trust records the current content of the project command file path as
+// trusted, replacing any earlier entry for it.
+func Trust(fs domain.FileSystem, path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	entries := readTrust(fs)
+	entries[path] = digest(data)
+
+	var b strings.Builder
+	for p, sum := range entries {
+		b.WriteString(sum + " " + p + "\n")
+	}
+	if err := fs.MkdirAll(config.Dir(), 0o700); err != nil {
+		return err
+	}
+	return fs.WriteFileAtomic(TrustFile(), []byte(b.String()), 0o600)
+}
+
+// readTrust maps trusted paths to their digests. A missing or unreadable
+// list trusts nothing.
+func readTrust(fs domain.FileSystem) map[string]string {
+	entries := make(map[string]string)
+	data, err := fs.ReadFile(TrustFile())
+	if err != nil {
+		return entries
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if sum, path, ok := strings.Cut(line, " "); ok && path != "" {
+			entries[path] = sum
+		}
+	}
+	return entries
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,57 @@
+package usercmds
+
+import (
+	"avro_cli/internal/infra/fs"
+	"testing"
+)
+
+func TestTrust(t *testing.T) {
+	files := fs.NewMemory()
+	const a, b = "/work/a/.avro/commands.yaml", "/work/b/.avro/commands.yaml"
+	v1, v2 := []byte("commands: []\n"), []byte("commands: [{name: rm}]\n")
+	files.WriteFile(a, v1, 0o644)
+	files.WriteFile(b, v1, 0o644)
+
+	if Trusted(files, a, v1) {
+		t.Fatal("trusted before Trust")
+	}
+	if err := Trust(files, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := Trust(files, b); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		desc string
+		path string
+		data []byte
+		want bool
+	}{
+		{"trusted content", a, v1, true},
+		{"second file", b, v1, true},
+		{"edited content", a, v2, false},
+		{"other path, same content", "/work/c/.avro/commands.yaml", v1, false},
+	}
+	for _, st := range steps {
+		if got := Trusted(files, st.path, st.data); got != st.want {
+			t.Errorf("%s: Trusted = %v, want %v", st.desc, got, st.want)
+		}
+	}
+
+	// Trusting the edit replaces the entry for a and keeps b's.
+	files.WriteFile(a, v2, 0o644)
+	if err := Trust(files, a); err != nil {
+		t.Fatal(err)
+	}
+	if !Trusted(files, a, v2) || Trusted(files, a, v1) || !Trusted(files, b, v1) {
+		t.Error("re-trusting a did not replace its entry alone")
+	}
+
+	if info, err := files.Stat(TrustFile()); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("trust file: %v", err)
+	}
+	if err := Trust(files, "/work/missing.yaml"); err == nil {
+		t.Error("trusted a missing file")
+	}
+}
@@ -0,0 +1,305 @@
+package usercmds
+
+import (
+	"avro_cli/internal/app/registry"
+	"avro_cli/internal/config"
+	"avro_cli/internal/domain"
+	"bytes"
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// FileName is the name of a declarative command file, both in ~/.avro and in
+// a project's .avro directory.
+const FileName = "commands.yaml"
+
+// Command is a declarative command: a descriptor whose action renders a
+// command line template and runs it through the shell.
+type Command struct {
+	Source  string // file the command was defined in
+	Command domain.CommandDescriptor
+
+	tmpl *template.Template
+}
+
+// UserFile returns the path of the user's command file (~/.avro/commands.yaml).
+func UserFile() string {
+	return filepath.Join(config.Dir(), FileName)
+}
+
+// ProjectFile returns the nearest .avro/commands.yaml in dir or one of its
+// parents, or "" if there is none. The user's own file is never returned.
+func ProjectFile(fs domain.FileSystem, dir string) string {
+	user := UserFile()
+	for {
+		path := filepath.Join(dir, ".avro", FileName)
+		if path != user && fs.Exists(path) {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load reads the project-local and user command files from fs and registers
+// their commands into reg. Project commands are loaded first so they take
+// precedence over user commands of the same name, but only once the file has
+// been trusted as it is now (see Trust): merely changing into a checkout must
+// not define commands. Returned errors describe commands that were skipped;
+// the rest still load.
+func Load(reg *registry.Registry, fs domain.FileSystem) []error {
+	var (
+		found []Command
+		errs  []error
+		seen  = make(map[string]bool)
+	)
+	load := func(path string, data []byte) {
+		cmds, fileErrs := Parse(path, data)
+		errs = append(errs, fileErrs...)
+		for _, c := range cmds {
+			if name := c.Command.FullName(); !seen[name] {
+				seen[name] = true
+				found = append(found, c)
+			}
+		}
+	}
+
+	if wd, err := os.Getwd(); err == nil {
+		if path := ProjectFile(fs, wd); path != "" {
+			data, err := fs.ReadFile(path)
+			switch {
+			case err != nil:
+				errs = append(errs, err)
+			case !Trusted(fs, path, data):
+				errs = append(errs, fmt.Errorf("%s: not trusted, its commands are not loaded; review it, then run 'avro config trust'", path))
+			default:
+				load(path, data)
+			}
+		}
+	}
+	data, err := fs.ReadFile(UserFile())
+	switch {
+	case err == nil:
+		load(UserFile(), data)
+	case !errors.Is(err, iofs.ErrNotExist):
+		errs = append(errs, err)
+	}
+	return append(errs, Register(reg, found)...)
+}
+
+// Register adds commands to reg, skipping any that collide with an existing
+// command so a command file can never shadow built-ins or plugins.
+func Register(reg *registry.Registry, cmds []Command) []error {
+	var errs []error
+	for _, c := range cmds {
+		if err := reg.RegisterUnique(c.Command); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Source, err))
+		}
+	}
+	return errs
+}
+
+// action renders the command line and runs it, streaming its output.
+func (c Command) action(ctx domain.CommandContext) domain.Result[string] {
+	data, err := templateData(c.Command, ctx)
+	if err != nil {
+		return domain.Fail[string](err)
+	}
+	line, err := c.render(data)
+	if err != nil {
+		return domain.Fail[string](&domain.ExecutionError{Command: c.Command.FullName(), Cause: err})
+	}
+
+	name, args := shellCommand(line)
+	opts := domain.StreamOptions{
+		Stdout: ctx.Output.Stdout,
+		Stderr: ctx.Output.Stderr,
+	}
+	if err := ctx.Shell.RunStream(ctx.Context, opts, name, args...); err != nil {
+		return domain.Fail[string](err)
+	}
+	return domain.Ok("")
+}
+
+func (c Command) render(data map[string]any) (string, error) {
+	var buf bytes.Buffer
+	if err := c.tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	line := strings.TrimSpace(buf.String())
+	if line == "" {
+		return "", errors.New("command line rendered empty")
+	}
+	return line, nil
+}
+
+// word is a string value as templates see it. It prints as a single quoted
+// shell word, so {{.Args.name}} cannot inject commands whatever the user
+// types; {{raw .Args.name}} opts out where the value is meant to be shell
+// syntax.
+type word string
+
+func (w word) String() string { return quote(string(w)) }
+
+// templateData exposes resolved values to the template as .Args, .Flags and
+// .Extra. Strings are words; bool and int values keep their type so
+// {{if .Flags.verbose}} works; repeatable ones are word slices for {{range}}
+// or join.
+func templateData(cmd domain.CommandDescriptor, ctx domain.CommandContext) (map[string]any, error) {
+	var err error
+	values := func(defs []domain.ArgDef, raw map[string]string) map[string]any {
+		m := make(map[string]any, len(defs))
+		for _, def := range defs {
+			v := typedValue(def, raw[def.Name])
+			if e := checkWords(def.Name, v); e != nil && err == nil {
+				err = e
+			}
+			m[def.Name] = v
+		}
+		return m
+	}
+	data := map[string]any{
+		"Args":  values(cmd.Args, ctx.Args),
+		"Flags": values(cmd.Flags, ctx.Flags),
+	}
+	extra := make([]word, len(ctx.Extra))
+	for i, v := range ctx.Extra {
+		extra[i] = word(v)
+	}
+	if e := checkWords("args", extra); e != nil && err == nil {
+		err = e
+	}
+	data["Extra"] = extra
+	return data, err
+}
+
+// checkWords refuses line breaks on Windows, where cmd /C cannot take one
+// inside a quoted word.
+func checkWords(name string, v any) error {
+	if runtime.GOOS != "windows" {
+		return nil
+	}
+	words, _ := v.([]word)
+	if w, ok := v.(word); ok {
+		words = []word{w}
+	}
+	for _, w := range words {
+		if strings.ContainsAny(string(w), "\r\n") {
+			return &domain.ValidationError{Field: name, Message: "cannot contain a line break on Windows"}
+		}
+	}
+	return nil
+}
+
+// typedValue converts a resolved string into the def's type, using the
+// type's zero value when unset.
+func typedValue(def domain.ArgDef, raw string) any {
+	if def.Repeatable && def.Type != domain.ArgBool {
+		values := domain.SplitValues(raw)
+		words := make([]word, len(values))
+		for i, v := range values {
+			words[i] = word(v)
+		}
+		return words
+	}
+	switch def.Type {
+	case domain.ArgBool:
+		b, _ := strconv.ParseBool(raw)
+		return b
+	case domain.ArgInt:
+		n, _ := strconv.Atoi(raw)
+		return n
+	}
+	return word(raw)
+}
+
+// shellCommand wraps a rendered command line for the platform shell.
+func shellCommand(line string) (string, []string) {
+	if runtime.GOOS == "windows" {
+		return "cmd", []string{"/C", line}
+	}
+	return "sh", []string{"-c", line}
+}
+
+// quote makes s a single word for the platform shell.
+func quote(s string) string {
+	if runtime.GOOS == "windows" {
+		return quoteCmd(s)
+	}
+	return quotePOSIX(s)
+}
+
+// quotePOSIX makes s a single POSIX shell word.
+func quotePOSIX(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(s, " \t\n'\"\\$`!*?[]{}()<>|&;#~=%^") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteCmd makes s a single word for cmd /C. Inside double quotes cmd takes
+// & | < > and ^ literally and a doubled quote stands for one; only % still
+// expands, so it is escaped outside the quotes as ^%.
+func quoteCmd(s string) string {
+	if s == "" {
+		return `""`
+	}
+	if !strings.ContainsAny(s, " \t\"&|<>^%()!,;=") {
+		return s
+	}
+	s = strings.ReplaceAll(s, `"`, `""`)
+	s = strings.ReplaceAll(s, "%", `"^%"`)
+	return `"` + s + `"`
+}
+
+var funcs = template.FuncMap{
+	// quote is what printing a value does anyway; it remains for templates
+	// written before values were quoted by default, and for literals.
+	"quote": func(v any) string {
+		if w, ok := v.(word); ok {
+			return w.String()
+		}
+		return quote(fmt.Sprint(v))
+	},
+	// raw yields a value, or each value of a repeatable one, unquoted.
+	"raw": func(v any) any {
+		switch v := v.(type) {
+		case word:
+			return string(v)
+		case []word:
+			out := make([]string, len(v))
+			for i, w := range v {
+				out[i] = string(w)
+			}
+			return out
+		}
+		return v
+	},
+	// join joins words quoted, and raw strings as they are.
+	"join": func(v any, sep string) string {
+		switch v := v.(type) {
+		case []word:
+			out := make([]string, len(v))
+			for i, w := range v {
+				out[i] = w.String()
+			}
+			return strings.Join(out, sep)
+		case []string:
+			return strings.Join(v, sep)
+		}
+		return fmt.Sprint(v)
+	},
+}
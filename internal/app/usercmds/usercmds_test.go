@@ -0,0 +1,57 @@
+package usercmds
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestQuotePOSIX(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", "''"},
+		{"plain-word_1.txt", "plain-word_1.txt"},
+		{"two words", "'two words'"},
+		{"it's", `'it'\''s'`},
+		{"$HOME", "'$HOME'"},
+		{"a;rm -rf /", "'a;rm -rf /'"},
+		{"`id`", "'`id`'"},
+		{"*.go", "'*.go'"},
+		{"line\nbreak", "'line\nbreak'"},
+		{"k=v", "'k=v'"},
+	}
+	sh, err := exec.LookPath("sh")
+	for _, tt := range tests {
+		got := quotePOSIX(tt.in)
+		if got != tt.want {
+			t.Errorf("quotePOSIX(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+		if err != nil {
+			continue
+		}
+		// The shell must read the word back as exactly the input.
+		out, err := exec.Command(sh, "-c", "printf %s "+got).Output()
+		if err != nil || string(out) != tt.in {
+			t.Errorf("sh read %s as %q, %v; want %q", got, out, err, tt.in)
+		}
+	}
+}
+
+func TestQuoteCmd(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", `""`},
+		{`C:\Users\me`, `C:\Users\me`},
+		{"two words", `"two words"`},
+		{`say "hi"`, `"say ""hi"""`},
+		{"a&b|c", `"a&b|c"`},
+		{"%PATH%", `""^%"PATH"^%""`},
+		{"x>y", `"x>y"`},
+	}
+	for _, tt := range tests {
+		if got := quoteCmd(tt.in); got != tt.want {
+			t.Errorf("quoteCmd(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -6,12 +6,21 @@ import (
 	"avro_cli/internal/domain"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
-// BuildCobraTree creates cobra commands from the registry and attaches them to root.
-func BuildCobraTree(root *cobra.Command, exec *executor.Executor) {
+// BuildCobraTree creates cobra commands from the registry and attaches them to
+// root. A command whose flags clash with root's persistent flags or with each
+// other, which cobra would only panic over when it runs, is left out with an
+// error.
+func BuildCobraTree(root *cobra.Command, exec *executor.Executor) []error {
+	var errs []error
 	reg := registry.Global()
 	categoryCmds := make(map[string]*cobra.Command)
 
@@ -25,54 +34,245 @@ func BuildCobraTree(root *cobra.Command, exec *executor.Executor) {
 	}
 
 	for _, cmd := range reg.All() {
+		if err := checkFlags(root, cmd); err != nil {
+			errs = append(errs, fmt.Errorf("command %s: %w", cmd.FullName(), err))
+			continue
+		}
 		leafCmd := buildLeafCommand(cmd, exec)
 		if parent, ok := categoryCmds[cmd.Category.Name]; ok {
 			parent.AddCommand(leafCmd)
 		}
 	}
+	return errs
+}
+
+// checkFlags rejects flags of desc that reuse a name or shorthand of root's
+// persistent flags, of cobra's --help, or of one another.
+func checkFlags(root *cobra.Command, desc domain.CommandDescriptor) error {
+	global := root.PersistentFlags()
+	names := map[string]bool{"help": true}
+	shorts := map[string]bool{"h": true}
+	for _, f := range desc.Flags {
+		if names[f.Name] || global.Lookup(f.Name) != nil {
+			return fmt.Errorf("flag --%s is already defined", f.Name)
+		}
+		if f.Short != "" && (shorts[f.Short] || global.ShorthandLookup(f.Short) != nil) {
+			return fmt.Errorf("flag --%s: shorthand -%s is already defined", f.Name, f.Short)
+		}
+		names[f.Name] = true
+		if f.Short != "" {
+			shorts[f.Short] = true
+		}
+	}
+	return nil
 }
 
 func buildLeafCommand(desc domain.CommandDescriptor, exec *executor.Executor) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     buildUse(desc),
 		Short:   desc.Description,
+		Long:    buildLong(desc),
 		Aliases: desc.Aliases,
+		// Commands that take free-form args and declare no flags (e.g. PATH
+		// plugins) receive every token untouched, including --flags.
+		DisableFlagParsing: desc.ExtraArgs && len(desc.Flags) == 0,
 		RunE: func(c *cobra.Command, args []string) error {
+			// Ask for missing required args rather than failing, unless
+			// there is no one at a terminal to answer or --no-input is set.
+			if noInput, _ := c.Flags().GetBool("no-input"); !noInput && canPrompt() {
+				var err error
+				if args, err = promptMissing(c.Context(), exec, desc, args); err != nil {
+					return err
+				}
+			}
+
 			flags := make(map[string]string)
 			for _, f := range desc.Flags {
-				val, _ := c.Flags().GetString(f.Name)
-				if val != "" {
-					flags[f.Name] = val
+				if !c.Flags().Changed(f.Name) {
+					continue
+				}
+				if isArrayFlag(f) {
+					values, _ := c.Flags().GetStringArray(f.Name)
+					flags[f.Name] = domain.JoinValues(values)
+				} else {
+					flags[f.Name] = c.Flags().Lookup(f.Name).Value.String()
 				}
 			}
 
-			result := exec.Run(desc, args, flags)
-			if !result.IsOk() {
-				fmt.Fprintln(os.Stderr, "Error:", result.Err())
-				os.Exit(1)
+			rawFormat, _ := c.Flags().GetString("output")
+			format, err := parseOutputFormat(rawFormat)
+			if err != nil {
+				return err
 			}
-			if output := result.Value(); output != "" {
-				fmt.Println(output)
+
+			// Keep stdout machine-readable: structured formats stream progress to stderr.
+			sink := terminalSink
+			if format != FormatText {
+				sink = stderrSink
 			}
-			return nil
+
+			// Ctrl+C cancels the command's context instead of killing avro outright.
+			ctx, stop := signal.NotifyContext(c.Context(), os.Interrupt)
+			result := exec.RunRecords(ctx, desc, args, flags, sink)
+			stop()
+			if !result.IsOk() {
+				// main reports it once deferred cleanup, like closing the log, has run.
+				return result.Err()
+			}
+			return render(os.Stdout, format, result.Value())
 		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.ValidArgsFunction = func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		i := len(args)
+		if n := len(desc.Args); n > 0 && i >= n && desc.Args[n-1].Repeatable {
+			i = n - 1
+		}
+		if i >= len(desc.Args) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(c, exec, desc, desc.Args[i], args, toComplete)
 	}
 
 	for _, f := range desc.Flags {
-		cmd.Flags().StringP(f.Name, f.Short, f.Default, f.Description)
+		addFlag(cmd, f)
+		if f.Complete != nil || len(f.Choices) > 0 {
+			def := f
+			_ = cmd.RegisterFlagCompletionFunc(f.Name, func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				return complete(c, exec, desc, def, args, toComplete)
+			})
+		}
 	}
 
 	return cmd
 }
 
+// complete asks the ArgDef's provider or choices for suggestions, falling
+// back to the shell's file completion when the arg has neither.
+func complete(c *cobra.Command, exec *executor.Executor, desc domain.CommandDescriptor, def domain.ArgDef, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if def.Complete == nil && len(def.Choices) == 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return exec.Complete(c.Context(), desc, def, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// addFlag registers a pflag matching the ArgDef type so cobra parses bools
+// without a value and rejects non-numeric ints before the executor runs.
+// Repeatable flags collect every occurrence; the executor checks their type.
+func addFlag(cmd *cobra.Command, f domain.ArgDef) {
+	usage := withConstraints(f.Description, f)
+	switch {
+	case isArrayFlag(f):
+		cmd.Flags().StringArrayP(f.Name, f.Short, domain.SplitValues(f.Default), usage)
+	case f.Type == domain.ArgBool:
+		def, _ := strconv.ParseBool(f.Default)
+		cmd.Flags().BoolP(f.Name, f.Short, def, usage)
+	case f.Type == domain.ArgInt:
+		def, _ := strconv.Atoi(f.Default)
+		cmd.Flags().IntP(f.Name, f.Short, def, usage)
+	default:
+		cmd.Flags().StringP(f.Name, f.Short, f.Default, usage)
+	}
+}
+
+// isArrayFlag reports whether f is registered as a StringArray. A repeated
+// bool is still just on or off.
+func isArrayFlag(f domain.ArgDef) bool {
+	return f.Repeatable && f.Type != domain.ArgBool
+}
+
+// terminalSink streams command output straight to the process's stdout/stderr.
+var terminalSink domain.OutputSink = &consoleSink{
+	tty: term.IsTerminal(int(os.Stdout.Fd())) && term.IsTerminal(int(os.Stderr.Fd())),
+}
+
+// consoleSink writes lines to stdout and stderr. On a terminal, progress
+// lines end in a carriage return instead of a newline, so each update
+// overwrites the last as it would without avro in between; elsewhere every
+// update is kept as a line of its own.
+type consoleSink struct {
+	tty bool
+
+	mu      sync.Mutex
+	pending bool // a progress line is on screen, cursor at its end
+}
+
+func (s *consoleSink) WriteLine(line domain.OutputLine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := os.Stdout
+	if line.Stream == domain.Stderr {
+		w = os.Stderr
+	}
+	if !s.tty {
+		fmt.Fprintln(w, line.Text)
+		return
+	}
+	if s.pending {
+		// Return to the start of the progress line and clear it.
+		fmt.Fprint(w, "\r\x1b[K")
+	}
+	s.pending = line.Progress
+	if line.Progress {
+		fmt.Fprint(w, line.Text)
+		return
+	}
+	fmt.Fprintln(w, line.Text)
+}
+
+// stderrSink streams every line to stderr, leaving stdout for the rendered result.
+var stderrSink = domain.OutputSinkFunc(func(line domain.OutputLine) {
+	fmt.Fprintln(os.Stderr, line.Text)
+})
+
 func buildUse(desc domain.CommandDescriptor) string {
 	use := desc.Name
 	for _, a := range desc.Args {
+		name := a.Name
+		if a.Repeatable {
+			name += "..."
+		}
 		if a.Required {
-			use += " <" + a.Name + ">"
+			use += " <" + name + ">"
 		} else {
-			use += " [" + a.Name + "]"
+			use += " [" + name + "]"
 		}
 	}
+	if desc.ExtraArgs {
+		use += " [args...]"
+	}
 	return use
 }
+
+// buildLong documents the positional args, which cobra's help has no section
+// for, or returns "" so help falls back to the short description.
+func buildLong(desc domain.CommandDescriptor) string {
+	if len(desc.Args) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(desc.Description + "\n\nArguments:")
+	for _, a := range desc.Args {
+		line := a.Description
+		if a.Default != "" {
+			line = strings.TrimSpace(line + " (default " + a.Default + ")")
+		}
+		fmt.Fprintf(&b, "\n  %-14s %s", a.Name, withConstraints(line, a))
+	}
+	return b.String()
+}
+
+// withConstraints appends def's constraints, if any, to its help text.
+func withConstraints(text string, def domain.ArgDef) string {
+	c := def.Constraints()
+	switch {
+	case c == "":
+		return text
+	case text == "":
+		return "(" + c + ")"
+	}
+	return text + " (" + c + ")"
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/testkit"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// testRoot returns a root with the persistent flags leaf commands read.
+func testRoot() *cobra.Command {
+	root := &cobra.Command{Use: "avro", SilenceUsage: true, SilenceErrors: true}
+	root.PersistentFlags().StringP("output", "o", string(FormatText), "")
+	root.PersistentFlags().Bool("no-input", true, "")
+	root.PersistentFlags().Bool("verbose", false, "")
+	return root
+}
+
+func TestCheckFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   []domain.ArgDef
+		wantErr string
+	}{
+		{"distinct", []domain.ArgDef{{Name: "all", Short: "a"}, {Name: "count", Short: "n"}}, ""},
+		{"global name", []domain.ArgDef{{Name: "output"}}, "flag --output is already defined"},
+		{"global shorthand", []domain.ArgDef{{Name: "open", Short: "o"}}, "shorthand -o is already defined"},
+		{"help", []domain.ArgDef{{Name: "help"}}, "flag --help is already defined"},
+		{"help shorthand", []domain.ArgDef{{Name: "host", Short: "h"}}, "shorthand -h is already defined"},
+		{"each other", []domain.ArgDef{{Name: "all"}, {Name: "all"}}, "flag --all is already defined"},
+		{"each other's shorthand", []domain.ArgDef{{Name: "all", Short: "a"}, {Name: "any", Short: "a"}}, "shorthand -a is already defined"},
+	}
+	for _, tt := range tests {
+		err := checkFlags(testRoot(), domain.CommandDescriptor{Name: "x", Flags: tt.flags})
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: checkFlags = %v, want error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// runLeaf runs desc as "avro <args...>" and returns the flags its action saw.
+func runLeaf(t *testing.T, desc domain.CommandDescriptor, args ...string) (map[string]string, error) {
+	t.Helper()
+	var seen map[string]string
+	action := desc.Action
+	desc.Action = func(ctx domain.CommandContext) domain.Result[string] {
+		seen = ctx.Flags
+		if action != nil {
+			return action(ctx)
+		}
+		return domain.Ok("")
+	}
+	env := testkit.New(t)
+	root := testRoot()
+	root.AddCommand(buildLeafCommand(desc, env.Executor))
+	root.SetArgs(args)
+	_, err := root.ExecuteC()
+	return seen, err
+}
+
+func TestLeafFlags(t *testing.T) {
+	desc := domain.CommandDescriptor{
+		Name: "get",
+		Flags: []domain.ArgDef{
+			{Name: "format", Default: "short"},
+			{Name: "header", Short: "H", Repeatable: true},
+			{Name: "count", Type: domain.ArgInt, Default: "10"},
+		},
+	}
+	tests := []struct {
+		name string
+		args []string
+		want map[string]string
+	}{
+		{"defaults", []string{"get"}, map[string]string{"format": "short", "count": "10"}},
+		{"explicitly empty clears the default", []string{"get", "--format="}, map[string]string{"format": "", "count": "10"}},
+		{"repeated", []string{"get", "-H", "a: 1", "-H", "b: 2", "--count", "3"}, map[string]string{
+			"format": "short", "header": domain.JoinValues([]string{"a: 1", "b: 2"}), "count": "3",
+		}},
+	}
+	for _, tt := range tests {
+		got, err := runLeaf(t, desc, tt.args...)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: flags = %q, want %q", tt.name, got, tt.want)
+			continue
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("%s: flag %s = %q, want %q", tt.name, k, got[k], v)
+			}
+		}
+	}
+}
+
+func TestLeafReturnsErrors(t *testing.T) {
+	failing := domain.CommandDescriptor{
+		Name: "fail",
+		Action: func(domain.CommandContext) domain.Result[string] {
+			return domain.Fail[string](errors.New("boom"))
+		},
+	}
+	if _, err := runLeaf(t, failing, "fail"); err == nil || err.Error() != "boom" {
+		t.Errorf("failing command = %v, want boom", err)
+	}
+	if _, err := runLeaf(t, domain.CommandDescriptor{Name: "ok"}, "ok", "--output", "xml"); err == nil {
+		t.Error("unknown --output format accepted")
+	}
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// newCompletionCommand creates "avro completion <shell>", replacing cobra's
+// default so the install instructions match avro's binary name and layout.
+func newCompletionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion <bash|zsh|fish|powershell>",
+		Short: "Generate a shell completion script",
+		Long: `Generate a shell completion script for avro.
+
+Completions cover categories, commands and flags, plus dynamic values such as
+git branch names, environment variable names and previously requested URLs.
+
+Bash:
+  source <(avro completion bash)
+  # or persist it:
+  avro completion bash > /etc/bash_completion.d/avro
+
+Zsh:
+  avro completion zsh > "${fpath[1]}/_avro"
+
+Fish:
+  avro completion fish > ~/.config/fish/completions/avro.fish
+
+PowerShell:
+  avro completion powershell | Out-String | Invoke-Expression`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+			return fmt.Errorf("unsupported shell %q", args[0])
+		},
+	}
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"avro_cli/internal/domain"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// OutputFormat selects how a command's result is printed in CLI mode.
+type OutputFormat string
+
+const (
+	FormatText  OutputFormat = "text"
+	FormatJSON  OutputFormat = "json"
+	FormatYAML  OutputFormat = "yaml"
+	FormatTable OutputFormat = "table"
+)
+
+var outputFormats = []OutputFormat{FormatText, FormatJSON, FormatYAML, FormatTable}
+
+// parseOutputFormat validates the value of the global --output flag.
+func parseOutputFormat(s string) (OutputFormat, error) {
+	for _, f := range outputFormats {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+	names := make([]string, len(outputFormats))
+	for i, f := range outputFormats {
+		names[i] = string(f)
+	}
+	return "", &domain.ValidationError{
+		Field:   "output",
+		Message: fmt.Sprintf("unknown format %q (expected %s)", s, strings.Join(names, ", ")),
+	}
+}
+
+// render writes records to w in the given format.
+func render(w io.Writer, format OutputFormat, records domain.Records) error {
+	switch format {
+	case FormatJSON:
+		return renderJSON(w, records)
+	case FormatYAML:
+		return renderYAML(w, records)
+	case FormatTable:
+		return renderTable(w, records)
+	default:
+		if text := records.Text(); text != "" {
+			_, err := fmt.Fprintln(w, text)
+			return err
+		}
+		return nil
+	}
+}
+
+func renderJSON(w io.Writer, records domain.Records) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if records.Single && len(records.Items) == 1 {
+		return enc.Encode(records.Items[0])
+	}
+	items := records.Items
+	if items == nil {
+		items = []domain.Record{}
+	}
+	return enc.Encode(items)
+}
+
+func renderYAML(w io.Writer, records domain.Records) error {
+	var doc *yaml.Node
+	if records.Single && len(records.Items) == 1 {
+		node, err := yamlRecord(records.Items[0])
+		if err != nil {
+			return err
+		}
+		doc = node
+	} else {
+		doc = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, rec := range records.Items {
+			node, err := yamlRecord(rec)
+			if err != nil {
+				return err
+			}
+			doc.Content = append(doc.Content, node)
+		}
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// yamlRecord builds a mapping node so fields keep their declared order.
+func yamlRecord(rec domain.Record) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, f := range rec {
+		val := &yaml.Node{}
+		if err := val.Encode(f.Value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.Name},
+			val,
+		)
+	}
+	return node, nil
+}
+
+func renderTable(w io.Writer, records domain.Records) error {
+	cols := records.Columns()
+	if len(cols) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(cols))
+	for i, c := range cols {
+		headers[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, rec := range records.Items {
+		cells := make([]string, len(cols))
+		for i, c := range cols {
+			if v := rec.Get(c); v != nil {
+				cells[i] = tableCell(v)
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// tableCell flattens a value onto one line so it cannot break the column layout.
+func tableCell(v any) string {
+	s := fmt.Sprint(v)
+	s = strings.ReplaceAll(s, "\r", "")
+	s = strings.ReplaceAll(s, "\t", " ")
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
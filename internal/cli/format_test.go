@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"avro_cli/internal/domain"
+	"strings"
+	"testing"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	for _, s := range []string{"text", "json", "YAML", "Table"} {
+		if _, err := parseOutputFormat(s); err != nil {
+			t.Errorf("parseOutputFormat(%q): %v", s, err)
+		}
+	}
+	if _, err := parseOutputFormat("xml"); err == nil || !strings.Contains(err.Error(), "expected text, json, yaml, table") {
+		t.Errorf("parseOutputFormat(xml) = %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	branches := domain.Records{
+		Items: []domain.Record{
+			{{Name: "name", Value: "main"}, {Name: "current", Value: true}},
+			{{Name: "name", Value: "dev"}, {Name: "note", Value: "two\nlines"}},
+		},
+		Human: func(domain.Records) string { return "* main\n  dev" },
+	}
+	single := domain.Records{
+		Items:  []domain.Record{{{Name: "key", Value: "theme"}, {Name: "value", Value: "light"}}},
+		Single: true,
+	}
+
+	tests := []struct {
+		name    string
+		format  OutputFormat
+		records domain.Records
+		want    string
+	}{
+		{"text uses Human", FormatText, branches, "* main\n  dev\n"},
+		{"text without Human", FormatText, single, "key: theme\nvalue: light\n"},
+		{"text of nothing", FormatText, domain.Records{}, ""},
+		{"json list", FormatJSON, branches, "[\n  {\n    \"name\": \"main\",\n    \"current\": true\n  },\n  {\n    \"name\": \"dev\",\n    \"note\": \"two\\nlines\"\n  }\n]\n"},
+		{"json single", FormatJSON, single, "{\n  \"key\": \"theme\",\n  \"value\": \"light\"\n}\n"},
+		{"json empty", FormatJSON, domain.Records{}, "[]\n"},
+		{"yaml list", FormatYAML, branches, "- name: main\n  current: true\n- name: dev\n  note: |-\n    two\n    lines\n"},
+		{"yaml single", FormatYAML, single, "key: theme\nvalue: light\n"},
+		{"table", FormatTable, branches, "NAME  CURRENT  NOTE\nmain  true     \ndev            two\\nlines\n"},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := render(&b, tt.format, tt.records); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s:\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
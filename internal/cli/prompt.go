@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"avro_cli/internal/app/executor"
+	"avro_cli/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+)
+
+// errPromptCancelled is returned when the user abandons the prompt.
+var errPromptCancelled = errors.New("cancelled")
+
+// canPrompt reports whether missing args may be asked for: stdin must be a
+// terminal to type into and stderr, where the form is drawn, one to see it.
+func canPrompt() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// promptMissing asks on the terminal for every required positional arg of
+// desc that args leaves out, returning args filled in up to the last one
+// asked for. Optional args skipped on the way stay empty and take their default.
+func promptMissing(ctx context.Context, exec *executor.Executor, desc domain.CommandDescriptor, args []string) ([]string, error) {
+	var missing []int
+	for i, def := range desc.Args {
+		if def.Required && (i >= len(args) || args[i] == "") {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return args, nil
+	}
+
+	filled := make([]string, max(len(args), missing[len(missing)-1]+1))
+	copy(filled, args)
+
+	m := newPromptModel(ctx, exec, desc, filled, missing)
+	final, err := tea.NewProgram(m, tea.WithInput(os.Stdin), tea.WithOutput(os.Stderr), tea.WithContext(ctx)).Run()
+	if err != nil {
+		if errors.Is(err, tea.ErrProgramKilled) || errors.Is(err, context.Canceled) {
+			return nil, errPromptCancelled
+		}
+		return nil, err
+	}
+	result := final.(promptModel)
+	if result.cancelled {
+		return nil, errPromptCancelled
+	}
+	return result.args, nil
+}
+
+var (
+	promptLabel = lipgloss.NewStyle().Bold(true)
+	promptFaint = lipgloss.NewStyle().Faint(true)
+	promptError = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// promptModel is a small inline form asking for one arg at a time. Answered
+// args stay on screen above the current one.
+type promptModel struct {
+	ctx     context.Context
+	exec    *executor.Executor
+	desc    domain.CommandDescriptor
+	args    []string
+	missing []int // indexes into desc.Args, in prompt order
+	current int   // position in missing
+	input   textinput.Model
+	hints   bool // the current arg has suggestions to complete from
+	err     string
+
+	done, cancelled bool
+}
+
+func newPromptModel(ctx context.Context, exec *executor.Executor, desc domain.CommandDescriptor, args []string, missing []int) promptModel {
+	m := promptModel{ctx: ctx, exec: exec, desc: desc, args: args, missing: missing}
+	m.startField()
+	return m
+}
+
+// startField resets the input for the arg at m.current, prefilled with its
+// default and offering its completions as suggestions.
+func (m *promptModel) startField() {
+	def := m.desc.Args[m.missing[m.current]]
+
+	suggestions := m.suggestions(def)
+	in := textinput.New()
+	in.Prompt = ""
+	in.SetValue(def.Default)
+	switch def.Type {
+	case domain.ArgInt:
+		in.Placeholder = "number"
+	case domain.ArgBool:
+		in.Placeholder = "true or false"
+		if len(suggestions) == 0 {
+			suggestions = []string{"true", "false"}
+		}
+	}
+	in.SetSuggestions(suggestions)
+	in.ShowSuggestions = true
+	in.Focus()
+
+	m.input = in
+	m.hints = len(suggestions) > 0
+	m.err = ""
+}
+
+// suggestions returns def's completions, with the shell descriptions after a tab removed.
+func (m promptModel) suggestions(def domain.ArgDef) []string {
+	values := m.exec.Complete(m.ctx, m.desc, def, m.args[:m.missing[m.current]], "")
+	for i, v := range values {
+		values[i], _, _ = strings.Cut(v, "\t")
+	}
+	return values
+}
+
+func (m promptModel) Init() tea.Cmd { return textinput.Blink }
+
+func (m promptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.cancelled = true
+			return m, tea.Quit
+		case tea.KeyEnter:
+			def := m.desc.Args[m.missing[m.current]]
+			value := strings.TrimSpace(m.input.Value())
+			if err := checkPromptValue(def, value); err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			m.args[m.missing[m.current]] = value
+			if m.current == len(m.missing)-1 {
+				m.done = true
+				return m, tea.Quit
+			}
+			m.current++
+			m.startField()
+			return m, textinput.Blink
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// checkPromptValue rejects values the executor would, so mistakes can be
+// fixed in place instead of failing after the form closes.
+func checkPromptValue(def domain.ArgDef, value string) error {
+	if value == "" {
+		return errors.New("a value is required")
+	}
+	switch def.Type {
+	case domain.ArgInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return errors.New("expected an integer")
+		}
+	case domain.ArgBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("expected true or false")
+		}
+	}
+	var verr *domain.ValidationError
+	if err := def.Check(value); errors.As(err, &verr) {
+		return errors.New(verr.Message)
+	}
+	return nil
+}
+
+func (m promptModel) View() string {
+	answered := m.missing[:m.current]
+	if m.done {
+		answered = m.missing
+	}
+	var b strings.Builder
+	for _, idx := range answered {
+		fmt.Fprintf(&b, "%s %s\n", promptLabel.Render(m.desc.Args[idx].Name+":"), m.args[idx])
+	}
+	if m.done || m.cancelled {
+		return b.String()
+	}
+
+	def := m.desc.Args[m.missing[m.current]]
+	label := promptLabel.Render(def.Name + ":")
+	if def.Description != "" {
+		label += " " + promptFaint.Render("("+def.Description+")")
+	}
+	b.WriteString(label + "\n> " + m.input.View() + "\n")
+	if m.err != "" {
+		b.WriteString(promptError.Render(m.err) + "\n")
+	}
+	help := "enter: confirm | esc: cancel"
+	if m.hints {
+		help = "tab: complete | up/down: cycle suggestions | " + help
+	}
+	b.WriteString(promptFaint.Render(help) + "\n")
+	return b.String()
+}
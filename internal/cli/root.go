@@ -2,7 +2,10 @@ package cli
 
 import (
 	"avro_cli/internal/app/executor"
+	"avro_cli/internal/config"
+	"avro_cli/internal/infra/logging"
 	"avro_cli/internal/tui"
+	"log/slog"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -10,7 +13,10 @@ import (
 )
 
 // NewRootCommand creates the root cobra command with dual-mode dispatch.
-func NewRootCommand(exec *executor.Executor) *cobra.Command {
+// cfg is the loaded configuration, handed on to the TUI; logs is adjusted by
+// the --verbose flag. The errors name registered commands left out of the
+// tree; see BuildCobraTree.
+func NewRootCommand(exec *executor.Executor, cfg *config.Config, logs *logging.Logging) (*cobra.Command, []error) {
 	root := &cobra.Command{
 		Use:     "avro",
 		Short:   "Avro - personal dev toolbox",
@@ -21,7 +27,7 @@ func NewRootCommand(exec *executor.Executor) *cobra.Command {
 			if !term.IsTerminal(int(os.Stdin.Fd())) {
 				return cmd.Help()
 			}
-			return tui.Run()
+			return tui.Run(exec, cfg)
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
@@ -33,14 +39,39 @@ func NewRootCommand(exec *executor.Executor) *cobra.Command {
 		Short:   "Launch command palette",
 		Long:    "Launch a fuzzy-search command palette to find and execute any registered command.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return tui.RunPalette()
+			return tui.RunPalette(exec, cfg)
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
 
-	root.AddCommand(paletteCmd)
+	// Keep domain.ReservedFlags in step with the persistent flags.
+	root.PersistentFlags().StringP("output", "o", string(FormatText),
+		"output format: text, json, yaml or table")
+	root.PersistentFlags().DurationVar(&exec.Timeout, "timeout", 0,
+		"override the per-command timeout (e.g. 30s, 2m; 0 uses each command's default)")
+
+	root.PersistentFlags().Bool("no-input", false,
+		"never prompt for missing arguments; fail instead (implied when stdin is not a terminal)")
+
+	var verbose bool
+	root.PersistentFlags().BoolVar(&verbose, "verbose", false,
+		"log at debug level and echo log records to stderr")
+	root.PersistentFlags().BoolVar(&verbose, "debug", false, "same as --verbose")
+	root.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if !verbose {
+			return
+		}
+		logs.SetLevel(slog.LevelDebug)
+		// The TUI owns the terminal, so only one-shot commands echo to stderr.
+		if cmd != root && cmd != paletteCmd {
+			logs.Mirror(os.Stderr)
+		}
+	}
+
+	root.CompletionOptions.DisableDefaultCmd = true
+	root.AddCommand(paletteCmd, newCompletionCommand())
 	root.SetVersionTemplate("avro {{.Version}}\n")
-	BuildCobraTree(root, exec)
-	return root
+	errs := BuildCobraTree(root, exec)
+	return root, errs
 }
@@ -1,37 +1,136 @@
 package config
 
 import (
+	"avro_cli/internal/domain"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
-// Config holds application configuration.
+// Config holds application configuration. Keys documents each setting.
 type Config struct {
-	Theme    string `mapstructure:"theme"`
-	LogLevel string `mapstructure:"log_level"`
+	Theme    string        `mapstructure:"theme"`
+	LogLevel string        `mapstructure:"log_level"`
+	Timeout  time.Duration `mapstructure:"timeout"`
 }
 
-// Load reads config from ~/.avro/config.yaml or defaults.
-func Load() *Config {
+// Dir returns the avro state directory (~/.avro).
+func Dir() string {
 	home, _ := os.UserHomeDir()
-	configDir := filepath.Join(home, ".avro")
+	return filepath.Join(home, ".avro")
+}
+
+// EnsureDir creates the avro state directory on fs if needed and returns
+// its path.
+func EnsureDir(fs domain.FileSystem) (string, error) {
+	dir := Dir()
+	return dir, fs.MkdirAll(dir, 0o700)
+}
+
+// Path returns the config file location (~/.avro/config.yaml).
+func Path() string {
+	return filepath.Join(Dir(), "config.yaml")
+}
+
+// Default returns the configuration used when nothing overrides a key.
+func Default() *Config {
+	cfg, _ := decode(viper.New())
+	return cfg
+}
+
+// current is the Config of the last Load, see Current.
+var current atomic.Pointer[Config]
 
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(configDir)
-	viper.AddConfigPath(".")
+// Load reads ~/.avro/config.yaml, applying AVRO_<KEY> environment overrides on
+// top. A missing file is not an error. An unreadable file or invalid values
+// are reported in the error while the offending keys fall back to defaults,
+// so the returned Config is always usable. It also becomes Current.
+//
+// Only ~/.avro/config.yaml is read: a config.yaml in the working directory,
+// which earlier versions fell back to, is ignored.
+func Load() (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(Path())
+	v.SetConfigType("yaml")
+	v.SetEnvPrefix("AVRO")
+	v.AutomaticEnv()
 
-	viper.SetDefault("theme", "default")
-	viper.SetDefault("log_level", "info")
+	var errs []error
+	if err := v.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errs = append(errs, fmt.Errorf("%s: %w", Path(), err))
+	}
+	for _, k := range Keys {
+		if !v.IsSet(k.Name) {
+			continue
+		}
+		if err := k.Check(v.GetString(k.Name)); err != nil {
+			errs = append(errs, fmt.Errorf("config: %w (using default %q)", err, k.Default))
+			v.Set(k.Name, k.Default)
+		}
+	}
 
-	viper.SetEnvPrefix("AVRO")
-	viper.AutomaticEnv()
+	cfg, err := decode(v)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	current.Store(cfg)
+	return cfg, errors.Join(errs...)
+}
 
-	_ = viper.ReadInConfig() // ignore missing config
+// Current returns the configuration of the last Load, updated by Set since,
+// or the defaults if nothing was loaded.
+func Current() *Config {
+	if cfg := current.Load(); cfg != nil {
+		return cfg
+	}
+	return Default()
+}
 
+// envName is the environment variable overriding key name.
+func envName(name string) string {
+	return "AVRO_" + strings.ToUpper(name)
+}
+
+// decode fills a Config from v, with schema defaults for unset keys.
+func decode(v *viper.Viper) (*Config, error) {
+	for _, k := range Keys {
+		v.SetDefault(k.Name, k.Default)
+	}
 	cfg := &Config{}
-	_ = viper.Unmarshal(cfg)
-	return cfg
+	if err := v.Unmarshal(cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// Get returns the value of a schema key as it would be written in the file.
+func (c *Config) Get(name string) (string, error) {
+	switch name {
+	case "theme":
+		return c.Theme, nil
+	case "log_level":
+		return c.LogLevel, nil
+	case "timeout":
+		return c.Timeout.String(), nil
+	}
+	return "", unknownKey(name)
+}
+
+// set stores an already checked value of a schema key.
+func (c *Config) set(name, value string) {
+	switch name {
+	case "theme":
+		c.Theme = value
+	case "log_level":
+		c.LogLevel = value
+	case "timeout":
+		c.Timeout, _ = time.ParseDuration(value)
+	}
 }
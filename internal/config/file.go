@@ -0,0 +1,144 @@
+package config
+
+import (
+	"avro_cli/internal/domain"
+	"bytes"
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Set validates value against the schema and writes it to the config file on
+// fs, creating the file if needed. Comments and other keys are preserved.
+// Current reflects the new value unless the environment overrides the key.
+func Set(fs domain.FileSystem, name, value string) error {
+	key, ok := LookupKey(name)
+	if !ok {
+		return unknownKey(name)
+	}
+	if err := key.Check(value); err != nil {
+		return err
+	}
+
+	doc, err := readDocument(fs, Path())
+	if err != nil {
+		return err
+	}
+	root := doc.Content[0]
+
+	replaced := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == name {
+			old := root.Content[i+1]
+			root.Content[i+1] = &yaml.Node{
+				Kind:        yaml.ScalarNode,
+				Tag:         "!!str",
+				Value:       value,
+				LineComment: old.LineComment,
+			}
+			replaced = true
+		}
+	}
+	if !replaced {
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+		)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if _, err := EnsureDir(fs); err != nil {
+		return err
+	}
+	if err := fs.WriteFileAtomic(Path(), buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	if cfg := current.Load(); cfg != nil && os.Getenv(envName(name)) == "" {
+		updated := *cfg
+		updated.set(name, value)
+		current.Store(&updated)
+	}
+	return nil
+}
+
+// ValidateFile checks the config file at path on fs against the schema and
+// returns one error per problem, each naming its line. A missing file is valid.
+func ValidateFile(fs domain.FileSystem, path string) []error {
+	doc, err := readDocument(fs, path)
+	if err != nil {
+		return []error{err}
+	}
+	root := doc.Content[0]
+
+	var errs []error
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k, v := root.Content[i], root.Content[i+1]
+		key, ok := LookupKey(k.Value)
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("line %d: %w", k.Line, unknownKey(k.Value)))
+		case seen[k.Value]:
+			errs = append(errs, fmt.Errorf("line %d: %q is set more than once", k.Line, k.Value))
+		case v.Kind != yaml.ScalarNode:
+			errs = append(errs, fmt.Errorf("line %d: %q must be a single %s value", v.Line, k.Value, key.Type))
+		default:
+			if err := key.Check(v.Value); err != nil {
+				errs = append(errs, fmt.Errorf("line %d: %w", v.Line, err))
+			} else if key.Name == "theme" {
+				if err := CheckTheme(fs, v.Value); err != nil {
+					errs = append(errs, fmt.Errorf("line %d: %w", v.Line, err))
+				}
+			}
+		}
+		seen[k.Value] = true
+	}
+	return errs
+}
+
+// readDocument parses path into a document whose root is a mapping. A
+// missing or empty file yields an empty mapping.
+func readDocument(fs domain.FileSystem, path string) (*yaml.Node, error) {
+	empty := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+
+	data, err := fs.ReadFile(path)
+	if errors.Is(err, iofs.ErrNotExist) {
+		return empty, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		// Comments only (e.g. the edit template): keep them above the new keys.
+		empty.HeadComment = strings.TrimRight(string(data), "\n")
+		return empty, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: top level must be a mapping of key: value", path)
+	}
+	return &doc, nil
+}
+
+func unknownKey(name string) error {
+	return &domain.ValidationError{
+		Field:   name,
+		Message: fmt.Sprintf("unknown config key (expected %s)", strings.Join(KeyNames(), ", ")),
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/infra/fs"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSetKeepsCommentsAndOtherKeys(t *testing.T) {
+	files := fs.NewMemory()
+	files.WriteFile(Path(), []byte("# mine\ntheme: light # comfy\nlog_level: info\n"), 0o644)
+
+	if err := Set(files, "log_level", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(files, "theme", "solarized"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(files, "timeout", "5m"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := files.ReadFile(Path())
+	want := "# mine\ntheme: solarized # comfy\nlog_level: debug\ntimeout: 5m\n"
+	if string(data) != want {
+		t.Errorf("config file:\n%s\nwant:\n%s", data, want)
+	}
+	if errs := ValidateFile(files, Path()); len(errs) != 0 {
+		t.Errorf("file written by Set is invalid: %v", errs)
+	}
+}
+
+func TestSetOnTemplate(t *testing.T) {
+	files := fs.NewMemory()
+	files.WriteFile(Path(), []byte(Template()), 0o644)
+
+	if err := Set(files, "timeout", "30s"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := files.ReadFile(Path())
+	if !strings.HasPrefix(string(data), "# avro configuration.") || !strings.HasSuffix(string(data), "\ntimeout: 30s\n") {
+		t.Errorf("config file:\n%s", data)
+	}
+}
+
+func TestSetRejectsInvalidValues(t *testing.T) {
+	files := fs.NewMemory()
+	tests := []struct{ key, value string }{
+		{"colour", "red"},
+		{"log_level", "loud"},
+		{"timeout", "soon"},
+		{"timeout", "-1s"},
+		{"theme", ""},
+	}
+	for _, tt := range tests {
+		var verr *domain.ValidationError
+		if err := Set(files, tt.key, tt.value); !errors.As(err, &verr) || verr.Field != tt.key {
+			t.Errorf("Set(%q, %q) = %v, want a ValidationError", tt.key, tt.value, err)
+		}
+	}
+	if files.Exists(Path()) {
+		t.Error("invalid values were written")
+	}
+}
+
+func TestSetUpdatesCurrent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AVRO_THEME", "light")
+	if _, err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	files := fs.NewMemory()
+	Set(files, "log_level", "warn")
+	Set(files, "theme", "solarized")
+
+	// The environment still overrides the saved theme.
+	if cfg := Current(); cfg.LogLevel != "warn" || cfg.Theme != "light" {
+		t.Errorf("Current = %+v", cfg)
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	files := fs.NewMemory()
+	if errs := ValidateFile(files, "/missing.yaml"); errs != nil {
+		t.Errorf("missing file: %v", errs)
+	}
+
+	files.WriteFile("/config.yaml", []byte("theme: light\ncolour: red\nlog_level: loud\ntimeout: [1, 2]\ntheme: dark\n"), 0o644)
+	want := []string{
+		`line 2: validation error for "colour": unknown config key`,
+		`line 3: validation error for "log_level": unknown value "loud"`,
+		`line 4: "timeout" must be a single duration value`,
+		`line 5: "theme" is set more than once`,
+	}
+	errs := ValidateFile(files, "/config.yaml")
+	if len(errs) != len(want) {
+		t.Fatalf("got %d problems, want %d: %v", len(errs), len(want), errs)
+	}
+	for i, w := range want {
+		if !strings.Contains(errs[i].Error(), w) {
+			t.Errorf("problem %d = %q, want it to contain %q", i, errs[i], w)
+		}
+	}
+
+	files.WriteFile("/list.yaml", []byte("- theme\n"), 0o644)
+	if errs := ValidateFile(files, "/list.yaml"); len(errs) != 1 || !strings.Contains(errs[0].Error(), "must be a mapping") {
+		t.Errorf("list document: %v", errs)
+	}
+}
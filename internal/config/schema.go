@@ -0,0 +1,109 @@
+package config
+
+import (
+	"avro_cli/internal/domain"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// KeyType is the kind of value a config key holds.
+type KeyType string
+
+const (
+	TypeString   KeyType = "string"
+	TypeDuration KeyType = "duration"
+)
+
+// Key documents one setting in config.yaml.
+type Key struct {
+	Name        string
+	Type        KeyType
+	Default     string
+	Description string
+	Values      []string // allowed values; empty means any
+}
+
+// Keys is the config.yaml schema. Every key can also be set through the
+// environment as AVRO_<NAME> (e.g. AVRO_LOG_LEVEL=debug).
+var Keys = []Key{
+	{
+		Name:        "theme",
+		Type:        TypeString,
+		Default:     "default",
+		Description: "TUI color theme: default, light, high-contrast, solarized, monochrome or a name from ~/.avro/themes",
+	},
+	{
+		Name:        "log_level",
+		Type:        TypeString,
+		Default:     "info",
+		Description: "Minimum level written to ~/.avro/logs/avro.log",
+		Values:      []string{"debug", "info", "warn", "error"},
+	},
+	{
+		Name:        "timeout",
+		Type:        TypeDuration,
+		Default:     "0s",
+		Description: "Timeout for commands that declare none (e.g. 5m); 0 means no limit",
+	},
+}
+
+// LookupKey returns the schema entry for name.
+func LookupKey(name string) (Key, bool) {
+	for _, k := range Keys {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// KeyNames returns the names of all schema keys in documented order.
+func KeyNames() []string {
+	names := make([]string, len(Keys))
+	for i, k := range Keys {
+		names[i] = k.Name
+	}
+	return names
+}
+
+// Check validates value against the key's type and allowed values.
+func (k Key) Check(value string) error {
+	switch k.Type {
+	case TypeDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return &domain.ValidationError{Field: k.Name, Message: fmt.Sprintf("%q is not a duration (e.g. 30s, 5m)", value)}
+		}
+		if d < 0 {
+			return &domain.ValidationError{Field: k.Name, Message: "must not be negative"}
+		}
+	case TypeString:
+		if value == "" {
+			return &domain.ValidationError{Field: k.Name, Message: "must not be empty"}
+		}
+	}
+	if len(k.Values) > 0 && !slices.Contains(k.Values, value) {
+		return &domain.ValidationError{
+			Field:   k.Name,
+			Message: fmt.Sprintf("unknown value %q (expected %s)", value, strings.Join(k.Values, ", ")),
+		}
+	}
+	return nil
+}
+
+// Template returns a commented config file documenting every key with its
+// default, written by "avro config edit" when no file exists yet.
+func Template() string {
+	var b strings.Builder
+	b.WriteString("# avro configuration. Run \"avro config list\" to see effective values.\n")
+	for _, k := range Keys {
+		b.WriteString("\n# " + k.Description)
+		if len(k.Values) > 0 {
+			b.WriteString(" (" + strings.Join(k.Values, ", ") + ")")
+		}
+		fmt.Fprintf(&b, "\n# %s: %s\n", k.Name, k.Default)
+	}
+	return b.String()
+}
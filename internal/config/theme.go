@@ -0,0 +1,67 @@
+package config
+
+import (
+	"avro_cli/internal/app/manifest"
+	"avro_cli/internal/domain"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// BuiltinThemes lists the shipped themes in the order they are offered.
+var BuiltinThemes = []string{"default", "light", "high-contrast", "solarized", "monochrome"}
+
+// ThemesDir returns the directory searched for user themes (~/.avro/themes).
+func ThemesDir() string {
+	return filepath.Join(Dir(), "themes")
+}
+
+// ThemeNames lists the built-in themes followed by user themes found in
+// ThemesDir on fs.
+func ThemeNames(fs domain.FileSystem) []string {
+	names := append([]string(nil), BuiltinThemes...)
+	entries, _ := fs.ListDir(ThemesDir())
+	var user []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e, ".yaml")
+		if !ok || slices.Contains(BuiltinThemes, name) || manifest.ValidateName("theme", name) != nil {
+			continue
+		}
+		if info, err := fs.Stat(filepath.Join(ThemesDir(), e)); err == nil && !info.IsDir() {
+			user = append(user, name)
+		}
+	}
+	sort.Strings(user)
+	return append(names, user...)
+}
+
+// ThemeFile returns the file of the user theme name. The name must be a plain
+// word, so it cannot point outside ThemesDir.
+func ThemeFile(name string) (string, error) {
+	if err := manifest.ValidateName("theme", name); err != nil {
+		return "", err
+	}
+	return filepath.Join(ThemesDir(), name+".yaml"), nil
+}
+
+// CheckTheme reports an error unless name is a built-in theme or has a file
+// in ThemesDir on fs. Whether the file is a valid theme is up to the TUI,
+// which falls back to the default theme when it is not.
+func CheckTheme(fs domain.FileSystem, name string) error {
+	if slices.Contains(BuiltinThemes, name) {
+		return nil
+	}
+	path, err := ThemeFile(name)
+	if err != nil {
+		return err
+	}
+	if info, err := fs.Stat(path); err != nil || info.IsDir() {
+		return &domain.ValidationError{
+			Field:   "theme",
+			Message: fmt.Sprintf("unknown theme %q (expected %s, or a file in %s)", name, strings.Join(ThemeNames(fs), ", "), ThemesDir()),
+		}
+	}
+	return nil
+}
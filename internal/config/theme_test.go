@@ -0,0 +1,78 @@
+package config
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/infra/fs"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// themeFiles returns a file system with user themes "ocean" and "zen", a
+// shadowed built-in, a stray file, a directory and a badly named theme.
+func themeFiles(t *testing.T) domain.FileSystem {
+	t.Helper()
+	files := fs.NewMemory()
+	for _, name := range []string{"zen.yaml", "ocean.yaml", "light.yaml", "notes.txt", "dir.yaml/x", "bad name.yaml"} {
+		if err := files.WriteFile(filepath.Join(ThemesDir(), name), []byte("base: default\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return files
+}
+
+func TestThemeNames(t *testing.T) {
+	want := append(slices.Clone(BuiltinThemes), "ocean", "zen")
+	if got := ThemeNames(themeFiles(t)); !slices.Equal(got, want) {
+		t.Errorf("ThemeNames = %q, want %q", got, want)
+	}
+	if got := ThemeNames(fs.NewMemory()); !slices.Equal(got, BuiltinThemes) {
+		t.Errorf("ThemeNames without a themes dir = %q, want the built-ins", got)
+	}
+}
+
+func TestCheckTheme(t *testing.T) {
+	files := themeFiles(t)
+	tests := []struct {
+		name    string
+		wantErr string
+	}{
+		{"solarized", ""},
+		{"zen", ""},
+		{"missing", `unknown theme "missing" (expected default, light, high-contrast, solarized, monochrome, ocean, zen`},
+		{"dir", `unknown theme "dir"`},
+		{"../zen", "theme"},
+	}
+	for _, tt := range tests {
+		err := CheckTheme(files, tt.name)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("CheckTheme(%q) = %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("CheckTheme(%q) = %v, want an error containing %q", tt.name, err, tt.wantErr)
+		}
+		var verr *domain.ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("CheckTheme(%q) = %T, want a *domain.ValidationError", tt.name, err)
+		}
+	}
+}
+
+func TestValidateFileChecksTheme(t *testing.T) {
+	files := themeFiles(t)
+	files.WriteFile("/ok.yaml", []byte("theme: zen\n"), 0o644)
+	if errs := ValidateFile(files, "/ok.yaml"); errs != nil {
+		t.Errorf("user theme: %v", errs)
+	}
+
+	files.WriteFile("/bad.yaml", []byte("log_level: info\ntheme: nope\n"), 0o644)
+	errs := ValidateFile(files, "/bad.yaml")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `line 2: validation error for "theme": unknown theme "nope"`) {
+		t.Errorf("unknown theme: %v", errs)
+	}
+}
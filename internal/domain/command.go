@@ -1,5 +1,11 @@
 package domain
 
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
 // Category groups related commands (e.g., "git", "system", "http").
 type Category struct {
 	Name        string
@@ -15,8 +21,31 @@ type ArgDef struct {
 	Required    bool
 	Default     string
 	Type        ArgType
+	Choices     []string       // allowed values; offered in completion and as a select list in the TUI
+	Pattern     string         // regular expression every value must match in full
+	Min         string         // inclusive lower bound for ArgInt values; empty means none
+	Max         string         // inclusive upper bound for ArgInt values; empty means none
+	Repeatable  bool           // flags may be given several times; a last positional arg takes all remaining args
+	Sensitive   bool           // values are secrets or payloads, kept out of logs and history; see IsSensitive
+	Complete    CompletionFunc // optional value suggestions for shell completion
+}
+
+// ReservedFlags are the global flags every command inherits from the root
+// command. A command's own flags may not reuse their names or shorthands.
+var ReservedFlags = []ArgDef{
+	{Name: "output", Short: "o"},
+	{Name: "timeout"},
+	{Name: "no-input"},
+	{Name: "verbose"},
+	{Name: "debug"},
+	{Name: "help", Short: "h"},
 }
 
+// CompletionFunc suggests values for an arg or flag whose value begins with
+// toComplete. ctx carries dependencies and the positional args typed so far;
+// its Context is short-lived, so providers must not block for long.
+type CompletionFunc func(ctx CommandContext, toComplete string) []string
+
 // ArgType enumerates supported argument types.
 type ArgType int
 
@@ -26,27 +55,112 @@ const (
 	ArgInt
 )
 
+// String returns the lowercase name of the type (e.g., "bool").
+func (t ArgType) String() string {
+	switch t {
+	case ArgBool:
+		return "bool"
+	case ArgInt:
+		return "int"
+	default:
+		return "string"
+	}
+}
+
 // CommandContext carries resolved arguments and dependencies to a command action.
+// Context is cancelled when the user interrupts the command or its timeout elapses;
+// actions must pass it to every blocking call. Output streams progress while the
+// action runs; the returned Result remains the final summary.
 type CommandContext struct {
-	Args  map[string]string
-	Flags map[string]string
-	Shell ShellRunner
-	FS    FileSystem
-	HTTP  HTTPClient
+	Context context.Context
+	Output  *Output
+	Args    map[string]string
+	Flags   map[string]string
+	Extra   []string // positional args beyond Args, when the command accepts them
+	Shell   ShellRunner
+	FS      FileSystem
+	HTTP    HTTPClient
+
+	// Run executes another command through the same executor, streaming into
+	// this command's output. It lets commands compose others (e.g. history rerun).
+	Run func(cmd CommandDescriptor, args []string, flags map[string]string) Result[Records]
+}
+
+// lookup returns the resolved value for name, checking args before flags.
+func (c CommandContext) lookup(name string) (string, bool) {
+	if v, ok := c.Args[name]; ok {
+		return v, true
+	}
+	v, ok := c.Flags[name]
+	return v, ok
+}
+
+// String returns the resolved value of an arg or flag, or "" if unset.
+func (c CommandContext) String(name string) string {
+	v, _ := c.lookup(name)
+	return v
+}
+
+// Bool returns the resolved value of an ArgBool arg or flag. Unset values are false.
+func (c CommandContext) Bool(name string) bool {
+	v, ok := c.lookup(name)
+	if !ok {
+		return false
+	}
+	b, _ := strconv.ParseBool(v)
+	return b
+}
+
+// Int returns the resolved value of an ArgInt arg or flag. Unset values are 0.
+func (c CommandContext) Int(name string) int {
+	v, ok := c.lookup(name)
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.Atoi(v)
+	return n
+}
+
+// Strings returns the values of a Repeatable arg or flag. Unset values are
+// nil. Use String for other defs, whose value may span lines.
+func (c CommandContext) Strings(name string) []string {
+	v, _ := c.lookup(name)
+	return SplitValues(v)
+}
+
+// Has reports whether an arg or flag was provided or has a default.
+func (c CommandContext) Has(name string) bool {
+	_, ok := c.lookup(name)
+	return ok
 }
 
 // CommandAction is the function signature every command implements.
+// Long-running actions should stream through ctx.Output and return a short summary.
 type CommandAction func(ctx CommandContext) Result[string]
 
+// DataAction is the signature for commands that return structured records.
+// Their text output is rendered from the records, so they need no CommandAction.
+type DataAction func(ctx CommandContext) Result[Records]
+
 // CommandDescriptor fully describes a command for registration, CLI routing, and TUI rendering.
 type CommandDescriptor struct {
 	Category    Category
 	Name        string
 	Aliases     []string
 	Description string
-	Args        []ArgDef // positional
-	Flags       []ArgDef // --flags
+	Tags        []string      // extra search keywords (e.g. "vcs", "download")
+	Args        []ArgDef      // positional
+	ExtraArgs   bool          // accept positional args beyond Args (passed through as CommandContext.Extra)
+	Flags       []ArgDef      // --flags
+	Timeout     time.Duration // zero means no limit
+	Interactive bool          // needs the terminal (e.g. opens an editor); the TUI suspends while it runs
+	NoHistory   bool          // executions are not recorded in history (e.g. the history commands)
 	Action      CommandAction
+	Data        DataAction // set instead of Action for structured output
+
+	// NoHistoryOutput records executions without their output, for commands
+	// whose output may hold secrets (e.g. HTTP responses, the environment).
+	NoHistoryOutput bool
 }
 
 // FullName returns "category name" (e.g., "git clone").
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// valueSep separates the values of a Repeatable arg or flag in the args and
+// flags maps, which hold a single string per name. A line break inside a
+// value is escaped with valueEsc, a NUL, which no command-line argument can
+// contain, so values without one encode as themselves.
+const (
+	valueSep = "\n"
+	valueEsc = "\x00"
+)
+
+var (
+	valueEscaper   = strings.NewReplacer(valueEsc, valueEsc+"0", valueSep, valueEsc+"n")
+	valueUnescaper = strings.NewReplacer(valueEsc+"0", valueEsc, valueEsc+"n", valueSep)
+)
+
+// JoinValues encodes the values of a Repeatable arg or flag for the args and
+// flags maps passed to the executor.
+func JoinValues(values []string) string {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = valueEscaper.Replace(v)
+	}
+	return strings.Join(escaped, valueSep)
+}
+
+// SplitValues decodes a value produced by JoinValues, dropping empty entries.
+// A default written by hand may separate its values with line breaks.
+func SplitValues(raw string) []string {
+	var out []string
+	for _, v := range strings.Split(raw, valueSep) {
+		if v != "" {
+			out = append(out, valueUnescaper.Replace(v))
+		}
+	}
+	return out
+}
+
+// Check validates a single value, already of the def's type, against its
+// Choices, Pattern, Min and Max.
+func (d ArgDef) Check(value string) error {
+	if len(d.Choices) > 0 && !slices.Contains(d.Choices, value) {
+		return &ValidationError{
+			Field:   d.Name,
+			Message: fmt.Sprintf("unknown value %q (expected %s)", value, strings.Join(d.Choices, ", ")),
+		}
+	}
+	if d.Pattern != "" {
+		re, err := compilePattern(d.Pattern)
+		if err != nil {
+			return &ValidationError{Field: d.Name, Message: fmt.Sprintf("invalid pattern %q: %v", d.Pattern, err)}
+		}
+		if !re.MatchString(value) {
+			return &ValidationError{Field: d.Name, Message: fmt.Sprintf("%q does not match %s", value, d.Pattern)}
+		}
+	}
+	if d.Type == ArgInt && (d.Min != "" || d.Max != "") {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return &ValidationError{Field: d.Name, Message: fmt.Sprintf("expected an integer, got %q", value)}
+		}
+		if d.Min != "" {
+			if lo, err := strconv.Atoi(d.Min); err != nil || n < lo {
+				return &ValidationError{Field: d.Name, Message: "must be at least " + d.Min}
+			}
+		}
+		if d.Max != "" {
+			if hi, err := strconv.Atoi(d.Max); err != nil || n > hi {
+				return &ValidationError{Field: d.Name, Message: "must be at most " + d.Max}
+			}
+		}
+	}
+	return nil
+}
+
+// patterns caches compiled Pattern values, keyed by pattern, since Check
+// runs for every value of every execution and completion.
+var patterns sync.Map
+
+// compilePattern compiles an ArgDef.Pattern, anchored to match whole values.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// CheckArgs rejects positional args the executor cannot assign values to:
+// a Repeatable arg before the last, or a repeatable last arg together with
+// ExtraArgs, as both would claim the trailing values.
+func (c CommandDescriptor) CheckArgs() error {
+	for i, a := range c.Args {
+		if !a.Repeatable {
+			continue
+		}
+		if i < len(c.Args)-1 {
+			return &ValidationError{Field: a.Name, Message: "only the last arg can be repeatable"}
+		}
+		if c.ExtraArgs {
+			return &ValidationError{Field: a.Name, Message: "a repeatable last arg cannot be combined with extra args"}
+		}
+	}
+	return nil
+}
+
+// Constraints summarizes Choices, Pattern, Min, Max and Repeatable for help
+// text, e.g. "one of: json, yaml; repeatable", or "" when there are none.
+func (d ArgDef) Constraints() string {
+	var parts []string
+	if len(d.Choices) > 0 {
+		parts = append(parts, "one of: "+strings.Join(d.Choices, ", "))
+	}
+	if d.Pattern != "" {
+		parts = append(parts, "matching "+d.Pattern)
+	}
+	switch {
+	case d.Min != "" && d.Max != "":
+		parts = append(parts, d.Min+" to "+d.Max)
+	case d.Min != "":
+		parts = append(parts, "at least "+d.Min)
+	case d.Max != "":
+		parts = append(parts, "at most "+d.Max)
+	}
+	if d.Repeatable {
+		parts = append(parts, "repeatable")
+	}
+	return strings.Join(parts, "; ")
+}
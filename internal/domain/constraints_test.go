@@ -0,0 +1,96 @@
+package domain_test
+
+import (
+	"avro_cli/internal/domain"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		def     domain.ArgDef
+		value   string
+		wantErr string
+	}{
+		{domain.ArgDef{Choices: []string{"json", "yaml"}}, "yaml", ""},
+		{domain.ArgDef{Choices: []string{"json", "yaml"}}, "xml", `unknown value "xml" (expected json, yaml)`},
+		{domain.ArgDef{Pattern: `v\d+`}, "v12", ""},
+		// Patterns match whole values.
+		{domain.ArgDef{Pattern: `v\d+`}, "xv12", `does not match`},
+		{domain.ArgDef{Pattern: `a|b`}, "ab", `does not match`},
+		{domain.ArgDef{Pattern: `(`}, "x", `invalid pattern`},
+		{domain.ArgDef{Type: domain.ArgInt, Min: "1", Max: "10"}, "1", ""},
+		{domain.ArgDef{Type: domain.ArgInt, Min: "1", Max: "10"}, "10", ""},
+		{domain.ArgDef{Type: domain.ArgInt, Min: "1", Max: "10"}, "0", "must be at least 1"},
+		{domain.ArgDef{Type: domain.ArgInt, Min: "1", Max: "10"}, "11", "must be at most 10"},
+		{domain.ArgDef{Type: domain.ArgInt, Min: "-5"}, "-5", ""},
+		// Min and Max only apply to ints.
+		{domain.ArgDef{Min: "5"}, "1", ""},
+	}
+	for _, tt := range tests {
+		tt.def.Name = "x"
+		err := tt.def.Check(tt.value)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("Check(%q) with %+v: unexpected error %v", tt.value, tt.def, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("Check(%q) with %+v = %v, want error containing %q", tt.value, tt.def, err, tt.wantErr)
+		}
+		var verr *domain.ValidationError
+		if err != nil && (!errors.As(err, &verr) || verr.Field != "x") {
+			t.Errorf("Check(%q): error %v is not a ValidationError for x", tt.value, err)
+		}
+	}
+}
+
+func TestCheckArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     domain.CommandDescriptor
+		wantErr bool
+	}{
+		{"repeatable last", domain.CommandDescriptor{Args: []domain.ArgDef{{Name: "a"}, {Name: "b", Repeatable: true}}}, false},
+		{"repeatable first", domain.CommandDescriptor{Args: []domain.ArgDef{{Name: "a", Repeatable: true}, {Name: "b"}}}, true},
+		{"repeatable with extra args", domain.CommandDescriptor{Args: []domain.ArgDef{{Name: "a", Repeatable: true}}, ExtraArgs: true}, true},
+		{"extra args", domain.CommandDescriptor{Args: []domain.ArgDef{{Name: "a"}}, ExtraArgs: true}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.cmd.CheckArgs(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: CheckArgs() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestJoinSplitValuesRoundTrip(t *testing.T) {
+	tests := [][]string{
+		{"a"},
+		{"a", "b", "c"},
+		{"line one\nline two", "plain"},
+		{"nul\x00inside", "\x00n", "\x000"},
+		{"trailing\n"},
+	}
+	for _, values := range tests {
+		joined := domain.JoinValues(values)
+		if got := domain.SplitValues(joined); !slices.Equal(got, values) {
+			t.Errorf("SplitValues(JoinValues(%q)) = %q", values, got)
+		}
+	}
+}
+
+func TestJoinValuesKeepsPlainValues(t *testing.T) {
+	if got, want := domain.JoinValues([]string{"a", "b"}), "a\nb"; got != want {
+		t.Errorf("JoinValues = %q, want %q", got, want)
+	}
+}
+
+func TestSplitValuesDropsEmpty(t *testing.T) {
+	// Defaults written by hand separate values with line breaks.
+	if got, want := domain.SplitValues("a\n\nb\n"), []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("SplitValues = %q, want %q", got, want)
+	}
+	if got := domain.SplitValues(""); got != nil {
+		t.Errorf("SplitValues(\"\") = %q, want nil", got)
+	}
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 // CommandNotFoundError indicates a command was not found in the registry.
 type CommandNotFoundError struct {
@@ -32,3 +36,15 @@ func (e *ExecutionError) Error() string {
 }
 
 func (e *ExecutionError) Unwrap() error { return e.Cause }
+
+// TimeoutError indicates a command did not finish within its allotted time.
+type TimeoutError struct {
+	Command string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%q timed out after %s", e.Command, e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error { return context.DeadlineExceeded }
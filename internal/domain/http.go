@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPRequest describes a request for HTTPClient.Do.
+type HTTPRequest struct {
+	Method string // defaults to GET
+	URL    string
+	Header http.Header
+	Query  url.Values // added to any query already in URL
+	Body   io.Reader  // nil for no body
+}
+
+// HTTPResponse is a completed response with its body read in full.
+type HTTPResponse struct {
+	Status     int
+	StatusText string // e.g. "200 OK"
+	Proto      string // e.g. "HTTP/2.0"
+	Header     http.Header
+	Body       []byte
+	Timing     HTTPTiming
+	TLS        *TLSInfo // nil for plain HTTP
+}
+
+// HTTPTiming breaks down where a request spent its time. Phases that did
+// not happen, such as DNS for an IP address or a reused connection, are zero.
+type HTTPTiming struct {
+	DNS       time.Duration
+	Connect   time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration // from sending the request to the first response byte
+	Total     time.Duration
+}
+
+// TLSInfo summarizes the TLS connection a response arrived on.
+type TLSInfo struct {
+	Version     string    `yaml:"version"` // e.g. "TLS 1.3"
+	CipherSuite string    `yaml:"cipher_suite"`
+	ServerName  string    `yaml:"server_name"`
+	Subject     string    `yaml:"subject"` // leaf certificate
+	Issuer      string    `yaml:"issuer"`
+	NotAfter    time.Time `yaml:"not_after"`
+}
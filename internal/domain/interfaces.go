@@ -1,23 +1,75 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path/filepath"
+)
 
 // ShellRunner executes OS commands.
 type ShellRunner interface {
 	Run(ctx context.Context, name string, args ...string) (string, error)
 	RunDir(ctx context.Context, dir string, name string, args ...string) (string, error)
+	// RunStream executes a command, copying its stdout and stderr to the writers
+	// in opts as they are produced.
+	RunStream(ctx context.Context, opts StreamOptions, name string, args ...string) error
 }
 
-// FileSystem provides basic file operations.
+// StreamOptions configures a ShellRunner.RunStream invocation.
+type StreamOptions struct {
+	Dir    string    // working directory; empty for the current one
+	Env    []string  // extra "KEY=value" entries added to the inherited environment
+	Stdin  io.Reader // nil for no input
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// FileSystem provides file operations. Commands use it instead of package os
+// so they can run against a sandbox, memory or a dry-run view of the disk.
+// Errors are *fs.PathError values wrapping fs.ErrNotExist and the like, as
+// with package os.
 type FileSystem interface {
 	ReadFile(path string) ([]byte, error)
-	WriteFile(path string, data []byte) error
+	WriteFile(path string, data []byte, perm fs.FileMode) error
+	// WriteFileAtomic writes data to a temporary file beside path and renames
+	// it into place, so readers see either the old content or the new.
+	WriteFileAtomic(path string, data []byte, perm fs.FileMode) error
+	// Open opens a file for streaming reads.
+	Open(path string) (io.ReadCloser, error)
+	// Create creates or truncates a file for streaming writes.
+	Create(path string) (io.WriteCloser, error)
+	// OpenFile opens a file with os.OpenFile flags, e.g. O_APPEND to add to
+	// it or O_CREATE|O_EXCL to create it only if it does not exist yet.
+	OpenFile(path string, flag int, perm fs.FileMode) (File, error)
+
 	Exists(path string) bool
+	Stat(path string) (fs.FileInfo, error)
+	// ListDir returns the sorted names of the entries in a directory.
 	ListDir(path string) ([]string, error)
+	// Walk calls fn for root and everything below it, in lexical order.
+	Walk(root string, fn filepath.WalkFunc) error
+	// Glob returns the paths matching pattern, as filepath.Glob does.
+	Glob(pattern string) ([]string, error)
+
+	MkdirAll(path string, perm fs.FileMode) error
+	Remove(path string) error
+	RemoveAll(path string) error
+	Rename(oldpath, newpath string) error
+	Chmod(path string, mode fs.FileMode) error
+}
+
+// File is an open file of a FileSystem, with the methods of *os.File that
+// commands and stores need.
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Writer
+	io.Closer
+	Stat() (fs.FileInfo, error)
 }
 
-// HTTPClient performs HTTP requests.
+// HTTPClient performs HTTP requests. A non-2xx status is not an error.
 type HTTPClient interface {
-	Get(ctx context.Context, url string, headers map[string]string) (int, string, error)
-	Post(ctx context.Context, url string, body string, headers map[string]string) (int, string, error)
+	Do(ctx context.Context, req HTTPRequest) (HTTPResponse, error)
 }
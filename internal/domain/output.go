@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Stream identifies which output channel a line was written to.
+type Stream int
+
+const (
+	Stdout Stream = iota
+	Stderr
+)
+
+// OutputLine is a single line of command output, without its terminator.
+type OutputLine struct {
+	Stream Stream
+	Text   string
+	// Progress marks a line ended by a lone carriage return, such as a
+	// progress meter update: the next line on the stream replaces it.
+	Progress bool
+}
+
+// OutputSink receives command output line by line as it is produced.
+// Implementations must be safe for concurrent use.
+type OutputSink interface {
+	WriteLine(line OutputLine)
+}
+
+// OutputSinkFunc adapts a plain function to OutputSink.
+type OutputSinkFunc func(line OutputLine)
+
+func (f OutputSinkFunc) WriteLine(line OutputLine) { f(line) }
+
+// DiscardOutput drops every line.
+var DiscardOutput OutputSink = OutputSinkFunc(func(OutputLine) {})
+
+// Output is the streaming side of a command: a line-oriented writer with
+// separate stdout and stderr channels feeding a single sink.
+type Output struct {
+	Stdout *LineWriter
+	Stderr *LineWriter
+}
+
+// NewOutput creates an Output writing to sink. A nil sink discards output.
+func NewOutput(sink OutputSink) *Output {
+	if sink == nil {
+		sink = DiscardOutput
+	}
+	return &Output{
+		Stdout: &LineWriter{sink: sink, stream: Stdout},
+		Stderr: &LineWriter{sink: sink, stream: Stderr},
+	}
+}
+
+// Printf writes a formatted line to stdout.
+func (o *Output) Printf(format string, args ...any) {
+	o.Stdout.writeLine(fmt.Sprintf(format, args...))
+}
+
+// Flush emits any buffered partial lines.
+func (o *Output) Flush() {
+	o.Stdout.Flush()
+	o.Stderr.Flush()
+}
+
+// LineWriter is an io.Writer that splits written bytes into lines and forwards
+// each one to a sink. A lone carriage return also ends a line, marked as
+// Progress, so meters (e.g. git's "Receiving objects: 42%\r") stream as they
+// update; "\r\n" is an ordinary terminator.
+type LineWriter struct {
+	mu     sync.Mutex
+	sink   OutputSink
+	stream Stream
+	buf    []byte
+}
+
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexAny(w.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		line, end, progress := string(w.buf[:i]), i+1, false
+		if w.buf[i] == '\r' {
+			if end == len(w.buf) {
+				// A '\n' may follow in the next write; wait for it.
+				break
+			}
+			if w.buf[end] == '\n' {
+				end++
+			} else {
+				progress = true
+			}
+		}
+		w.buf = w.buf[end:]
+		w.sink.WriteLine(OutputLine{Stream: w.stream, Text: line, Progress: progress})
+	}
+	return len(p), nil
+}
+
+// Flush emits the buffered partial line, if any.
+func (w *LineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		text := strings.TrimSuffix(string(w.buf), "\r")
+		w.sink.WriteLine(OutputLine{Stream: w.stream, Text: text})
+		w.buf = nil
+	}
+}
+
+func (w *LineWriter) writeLine(text string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.sink.WriteLine(OutputLine{Stream: w.stream, Text: text})
+}
+
+// OutputBuffer is an OutputSink that records every line in memory.
+type OutputBuffer struct {
+	mu    sync.Mutex
+	lines []OutputLine
+}
+
+func (b *OutputBuffer) WriteLine(line OutputLine) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, line)
+}
+
+// Lines returns a copy of the recorded lines.
+func (b *OutputBuffer) Lines() []OutputLine {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]OutputLine, len(b.lines))
+	copy(out, b.lines)
+	return out
+}
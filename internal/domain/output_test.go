@@ -0,0 +1,49 @@
+package domain_test
+
+import (
+	"avro_cli/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func TestLineWriter(t *testing.T) {
+	line := func(text string) domain.OutputLine { return domain.OutputLine{Text: text} }
+	progress := func(text string) domain.OutputLine { return domain.OutputLine{Text: text, Progress: true} }
+
+	tests := []struct {
+		name   string
+		writes []string
+		want   []domain.OutputLine
+	}{
+		{"lines", []string{"a\nb\n"}, []domain.OutputLine{line("a"), line("b")}},
+		{"split across writes", []string{"he", "llo\nwor", "ld\n"}, []domain.OutputLine{line("hello"), line("world")}},
+		{"partial line flushed", []string{"a\nb"}, []domain.OutputLine{line("a"), line("b")}},
+		{"crlf", []string{"a\r\nb\r\n"}, []domain.OutputLine{line("a"), line("b")}},
+		{"crlf split between writes", []string{"a\r", "\nb\n"}, []domain.OutputLine{line("a"), line("b")}},
+		{"progress", []string{"10%\r50%\r100%\ndone\n"}, []domain.OutputLine{progress("10%"), progress("50%"), line("100%"), line("done")}},
+		{"trailing cr flushed", []string{"a\r"}, []domain.OutputLine{line("a")}},
+		{"empty lines", []string{"\n\n"}, []domain.OutputLine{line(""), line("")}},
+	}
+	for _, tt := range tests {
+		var buf domain.OutputBuffer
+		out := domain.NewOutput(&buf)
+		for _, w := range tt.writes {
+			out.Stdout.Write([]byte(w))
+		}
+		out.Flush()
+		if got := buf.Lines(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: lines = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOutputStreams(t *testing.T) {
+	var buf domain.OutputBuffer
+	out := domain.NewOutput(&buf)
+	out.Stdout.Write([]byte("out\n"))
+	out.Stderr.Write([]byte("err\n"))
+	want := []domain.OutputLine{{Stream: domain.Stdout, Text: "out"}, {Stream: domain.Stderr, Text: "err"}}
+	if got := buf.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Field is a single named value within a Record.
+type Field struct {
+	Name  string
+	Value any
+}
+
+// Record is an ordered list of fields, rendered in declaration order.
+type Record []Field
+
+// Get returns the value of the named field, or nil.
+func (r Record) Get(name string) any {
+	for _, f := range r {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the record as a JSON object preserving field order.
+func (r Record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Records is a structured command result that can be rendered as text for
+// humans or serialized for scripts.
+type Records struct {
+	Items []Record
+	// Single marks a result that is one object rather than a list (e.g., "system info").
+	Single bool
+	// Human renders the text form; nil falls back to "name: value" lines.
+	Human func(Records) string
+}
+
+// Columns returns the union of field names across all items, in first-seen order.
+func (r Records) Columns() []string {
+	seen := make(map[string]bool)
+	var cols []string
+	for _, rec := range r.Items {
+		for _, f := range rec {
+			if !seen[f.Name] {
+				seen[f.Name] = true
+				cols = append(cols, f.Name)
+			}
+		}
+	}
+	return cols
+}
+
+// Text returns the human-readable form of the records.
+func (r Records) Text() string {
+	if r.Human != nil {
+		return r.Human(r)
+	}
+	blocks := make([]string, len(r.Items))
+	for i, rec := range r.Items {
+		lines := make([]string, len(rec))
+		for j, f := range rec {
+			lines[j] = fmt.Sprintf("%s: %v", f.Name, f.Value)
+		}
+		blocks[i] = strings.Join(lines, "\n")
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// TextRecords wraps plain text output as a single record with an "output" field,
+// so text-only commands still serialize under --output json/yaml/table.
+func TextRecords(text string) Records {
+	return Records{
+		Items:  []Record{{{Name: "output", Value: text}}},
+		Single: true,
+		Human:  func(Records) string { return text },
+	}
+}
@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces secret values in logs, history and recordings.
+const Redacted = "[redacted]"
+
+// secretWords mark a header, query parameter or key=value name as holding a
+// secret when the name contains one of them.
+var secretWords = []string{"auth", "token", "passw", "secret", "cookie", "credential", "session", "signature", "key", "private"}
+
+// payloadWords mark an arg or flag as carrying credentials or a payload, on
+// top of secretWords. "key" alone is left out: config keys and the like are
+// names, not secrets.
+var payloadWords = []string{"auth", "token", "passw", "secret", "cookie", "credential", "session", "api-key", "api_key", "apikey", "private", "header", "body"}
+
+// SecretName reports whether a header, query parameter or key=value name
+// suggests its value is a secret.
+func SecretName(name string) bool {
+	return containsAny(strings.ToLower(name), secretWords)
+}
+
+// SensitiveName reports whether an arg or flag name says it carries
+// credentials or a payload (e.g. header, token, password, body).
+func SensitiveName(name string) bool {
+	return containsAny(strings.ToLower(name), payloadWords)
+}
+
+// IsSensitive reports whether the def's values must be kept out of logs,
+// history and recordings: it is marked Sensitive or has a SensitiveName.
+func (d ArgDef) IsSensitive() bool {
+	return d.Sensitive || SensitiveName(d.Name)
+}
+
+// Redact returns copies of args and flags, as passed to the executor for
+// cmd, with secrets replaced by Redacted: every value of a sensitive def,
+// secret query parameters and credentials in URLs, key=value pairs with a
+// secret key, and the values of secret-looking --flags among extra args.
+func (c CommandDescriptor) Redact(args []string, flags map[string]string) ([]string, map[string]string) {
+	outArgs := make([]string, len(args))
+	n := len(c.Args)
+	for i, v := range args {
+		switch {
+		case i < n:
+			outArgs[i] = redactValue(c.Args[i], v)
+		case n > 0 && c.Args[n-1].Repeatable:
+			outArgs[i] = redactValue(c.Args[n-1], v)
+		default:
+			outArgs[i] = v
+		}
+	}
+	if len(args) > n && (n == 0 || !c.Args[n-1].Repeatable) {
+		redactExtra(outArgs[n:])
+	}
+
+	var outFlags map[string]string
+	if flags != nil {
+		outFlags = make(map[string]string, len(flags))
+	}
+	for name, v := range flags {
+		def := ArgDef{Name: name}
+		for _, f := range c.Flags {
+			if f.Name == name {
+				def = f
+			}
+		}
+		outFlags[name] = redactValue(def, v)
+	}
+	return outArgs, outFlags
+}
+
+// redactValue redacts raw, which holds one or more values of def.
+func redactValue(def ArgDef, raw string) string {
+	if !def.Repeatable {
+		values := []string{raw}
+		redactValues(def, values)
+		return values[0]
+	}
+	values := SplitValues(raw)
+	redactValues(def, values)
+	return JoinValues(values)
+}
+
+func redactValues(def ArgDef, values []string) {
+	for i, v := range values {
+		if def.IsSensitive() {
+			values[i] = Redacted
+		} else {
+			values[i] = RedactText(v)
+		}
+	}
+}
+
+// RedactText hides the secret in a single value: the password and secret
+// query parameters of a URL, or the value of a key=value pair whose key is
+// a secret name. Other values are returned as they are.
+func RedactText(v string) string {
+	if strings.Contains(v, "://") {
+		return RedactURL(v)
+	}
+	if k, _, ok := strings.Cut(v, "="); ok && SecretName(k) {
+		return k + "=" + Redacted
+	}
+	return v
+}
+
+// RedactURL replaces the password of raw, as url.URL.Redacted does, and the
+// values of its secret query parameters. A value that does not parse is
+// redacted whole.
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Redacted
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = RedactQuery(u.RawQuery)
+	}
+	return u.Redacted()
+}
+
+// RedactQuery replaces the values of secret parameters in an encoded query,
+// keeping the order and encoding of everything else.
+func RedactQuery(query string) string {
+	parts := strings.Split(query, "&")
+	for i, p := range parts {
+		k, _, ok := strings.Cut(p, "=")
+		if name, err := url.QueryUnescape(k); ok && err == nil && SecretName(name) {
+			parts[i] = k + "=" + Redacted
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// RedactArgs returns a copy of a free-form argument list, such as an argv,
+// with the values of secret-looking --flags and secrets in URLs and
+// key=value pairs redacted.
+func RedactArgs(args []string) []string {
+	out := append([]string(nil), args...)
+	redactExtra(out)
+	return out
+}
+
+// redactExtra redacts free-form args in place: the value of --name=value or
+// of "--name value" when name is a secret, and secrets in plain values.
+func redactExtra(args []string) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		name, ok := strings.CutPrefix(a, "--")
+		if !ok {
+			args[i] = RedactText(a)
+			continue
+		}
+		if k, _, ok := strings.Cut(name, "="); ok {
+			if SensitiveName(k) {
+				args[i] = "--" + k + "=" + Redacted
+			}
+			continue
+		}
+		if SensitiveName(name) && i+1 < len(args) {
+			i++
+			args[i] = Redacted
+		}
+	}
+}
+
+func containsAny(s string, words []string) bool {
+	for _, w := range words {
+		if strings.Contains(s, w) {
+			return true
+		}
+	}
+	return false
+}
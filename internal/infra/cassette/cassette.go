@@ -0,0 +1,217 @@
+// Package cassette records the HTTP requests and shell commands avro makes
+// and replays them later, so modules can be exercised offline and
+// deterministically. A Recorder wraps the real client or runner and appends
+// every interaction to a cassette file; a Replayer serves those interactions
+// back without touching the network or spawning processes.
+package cassette
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Cassette is the set of interactions stored in one YAML file. Each list is
+// kept in the order the interactions happened. Secrets are redacted before
+// they are written; see redact.go.
+type Cassette struct {
+	HTTP  []HTTPInteraction  `yaml:"http,omitempty"`
+	Shell []ShellInteraction `yaml:"shell,omitempty"`
+
+	// Match sets how calls find their interaction on replay.
+	Match Matching `yaml:"-"`
+
+	path string
+	mu   sync.Mutex
+	used map[any]bool // interactions already replayed, by pointer
+}
+
+// New returns an empty cassette saved to path as interactions are recorded.
+func New(path string) *Cassette {
+	return &Cassette{path: path}
+}
+
+// Open returns the cassette at path to record more interactions onto, or
+// a new one if the file does not exist yet.
+func Open(path string) (*Cassette, error) {
+	c, err := Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return New(path), nil
+	}
+	return c, err
+}
+
+// Load reads the cassette at path for replay.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := New(path)
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Matching holds the rules replay matches calls by. The zero value compares
+// everything recorded and serves each interaction once.
+type Matching struct {
+	// Lenient serves the last matching interaction again once every match
+	// has been used, for calls repeated more often than during recording.
+	// Otherwise such a call fails with a *MismatchError.
+	Lenient bool
+	// IgnoreQuery names URL query parameters left out of the comparison,
+	// e.g. timestamps and nonces.
+	IgnoreQuery []string
+	// IgnoreBody compares HTTP requests without their bodies.
+	IgnoreBody bool
+	// IgnoreEnv names environment variables left out of the comparison.
+	IgnoreEnv []string
+	// IgnoreStdin compares commands without their standard input.
+	IgnoreStdin bool
+}
+
+// ParseMatching reads matching rules from a comma-separated list, as given
+// in AVRO_REPLAY_MATCH:
+//
+//	lenient             see Matching.Lenient
+//	ignore-body         see Matching.IgnoreBody
+//	ignore-stdin        see Matching.IgnoreStdin
+//	ignore-query:<name> leave out a query parameter, may repeat
+//	ignore-env:<name>   leave out an environment variable, may repeat
+func ParseMatching(spec string) (Matching, error) {
+	var m Matching
+	for _, rule := range strings.Split(spec, ",") {
+		rule = strings.TrimSpace(rule)
+		kind, name, hasName := strings.Cut(rule, ":")
+		switch {
+		case rule == "":
+		case rule == "lenient":
+			m.Lenient = true
+		case rule == "ignore-body":
+			m.IgnoreBody = true
+		case rule == "ignore-stdin":
+			m.IgnoreStdin = true
+		case kind == "ignore-query" && hasName && name != "":
+			m.IgnoreQuery = append(m.IgnoreQuery, name)
+		case kind == "ignore-env" && hasName && name != "":
+			m.IgnoreEnv = append(m.IgnoreEnv, name)
+		default:
+			return Matching{}, fmt.Errorf("unknown matching rule %q (expected lenient, ignore-body, ignore-stdin, ignore-query:<name> or ignore-env:<name>)", rule)
+		}
+	}
+	return m, nil
+}
+
+// Path returns the file the cassette is stored in.
+func (c *Cassette) Path() string { return c.path }
+
+// save writes the cassette via a temporary file, readable by the owner
+// only since responses may hold personal data. Callers hold c.mu.
+func (c *Cassette) save() error {
+	// An indent of 2 avoids a yaml.v3 bug that writes block scalars starting
+	// with a space (e.g. "git status --short" output) unreadably at the default 4.
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// take marks the first unused candidate as replayed and returns it. When
+// every candidate has been used it returns nil, unless c.Match.Lenient
+// serves the last one again. Callers hold c.mu.
+func take[T any](c *Cassette, candidates []*T) *T {
+	if c.used == nil {
+		c.used = make(map[any]bool)
+	}
+	for _, cand := range candidates {
+		if !c.used[cand] {
+			c.used[cand] = true
+			return cand
+		}
+	}
+	if c.Match.Lenient && len(candidates) > 0 {
+		return candidates[len(candidates)-1]
+	}
+	return nil
+}
+
+// MismatchError is returned on replay when no recorded interaction matches,
+// or every match has already been replayed.
+type MismatchError struct {
+	Cassette string
+	Request  string // the request that found no match, e.g. "GET https://…"
+	Recorded int    // interactions of the same kind on the cassette
+	Replayed int    // matching interactions already used up
+}
+
+func (e *MismatchError) Error() string {
+	if e.Replayed > 0 {
+		return fmt.Sprintf("cassette %s: %s was recorded %d time(s) and replayed as often (lenient matching repeats the last answer)", e.Cassette, e.Request, e.Replayed)
+	}
+	return fmt.Sprintf("cassette %s: no recorded interaction matches %s (%d of this kind recorded)", e.Cassette, e.Request, e.Recorded)
+}
+
+// Blob holds a body or stream: as text when it is valid UTF-8, otherwise
+// base64-encoded.
+type Blob struct {
+	Text   string `yaml:"text,omitempty"`
+	Base64 string `yaml:"base64,omitempty"`
+}
+
+func newBlob(data []byte) Blob {
+	if utf8.Valid(data) {
+		return Blob{Text: string(data)}
+	}
+	return Blob{Base64: base64.StdEncoding.EncodeToString(data)}
+}
+
+// Bytes returns the content. A corrupt base64 value yields what decoded.
+func (b Blob) Bytes() []byte {
+	if b.Base64 != "" {
+		data, _ := base64.StdEncoding.DecodeString(b.Base64)
+		return data
+	}
+	return []byte(b.Text)
+}
+
+// IsZero reports whether the blob is empty, so the YAML encoder omits it.
+func (b Blob) IsZero() bool { return b.Text == "" && b.Base64 == "" }
+
+// errorText returns err's message, or "" for nil.
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// recordedError recreates a recorded failure, or nil if there was none.
+func recordedError(msg string) error {
+	if msg == "" {
+		return nil
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,253 @@
+package cassette
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/infra/shell"
+	"avro_cli/internal/testkit"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMatching(t *testing.T) {
+	m, err := ParseMatching("lenient, ignore-body,ignore-query:ts,ignore-query:nonce,ignore-env:HOME,ignore-stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Lenient || !m.IgnoreBody || !m.IgnoreStdin ||
+		strings.Join(m.IgnoreQuery, ",") != "ts,nonce" || strings.Join(m.IgnoreEnv, ",") != "HOME" {
+		t.Errorf("ParseMatching = %+v", m)
+	}
+	if m, err := ParseMatching(""); err != nil || m.Lenient {
+		t.Errorf("ParseMatching(\"\") = %+v, %v", m, err)
+	}
+	for _, spec := range []string{"strict", "ignore-query", "ignore-env:", "lenient,bogus"} {
+		if _, err := ParseMatching(spec); err == nil {
+			t.Errorf("ParseMatching(%q) succeeded", spec)
+		}
+	}
+}
+
+// recordHTTP records the requests made by do against the fake client and
+// returns the cassette loaded back from disk.
+func recordHTTP(t *testing.T, client *testkit.HTTP, do func(domain.HTTPClient)) *Cassette {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	do(NewHTTPRecorder(New(path), client))
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func get(t *testing.T, client domain.HTTPClient, req domain.HTTPRequest) (string, error) {
+	t.Helper()
+	resp, err := client.Do(context.Background(), req)
+	return string(resp.Body), err
+}
+
+func TestHTTPReplay(t *testing.T) {
+	fake := testkit.NewHTTP(t)
+	fake.On("GET", "https://api.test/items?page=1").Respond(200, "first")
+	c := recordHTTP(t, fake, func(client domain.HTTPClient) {
+		get(t, client, domain.HTTPRequest{URL: "https://api.test/items", Query: map[string][]string{"page": {"1"}}})
+		fake.On("GET", "https://api.test/items?page=1").Respond(200, "second")
+		get(t, client, domain.HTTPRequest{URL: "https://api.test/items?page=1"})
+	})
+
+	replay := NewHTTPReplayer(c)
+	for _, want := range []string{"first", "second"} {
+		if body, err := get(t, replay, domain.HTTPRequest{URL: "https://api.test/items?page=1"}); err != nil || body != want {
+			t.Errorf("replay = %q, %v; want %q", body, err, want)
+		}
+	}
+
+	// Every match is used up.
+	var mismatch *MismatchError
+	if _, err := get(t, replay, domain.HTTPRequest{URL: "https://api.test/items?page=1"}); !errors.As(err, &mismatch) || mismatch.Replayed != 2 {
+		t.Errorf("third replay = %v, want a MismatchError with Replayed 2", err)
+	}
+	if _, err := get(t, replay, domain.HTTPRequest{URL: "https://api.test/other"}); !errors.As(err, &mismatch) || mismatch.Replayed != 0 {
+		t.Errorf("unrecorded request = %v, want a MismatchError", err)
+	}
+
+	// Lenient matching repeats the last answer.
+	c.Match.Lenient = true
+	if body, err := get(t, replay, domain.HTTPRequest{URL: "https://api.test/items?page=1"}); err != nil || body != "second" {
+		t.Errorf("lenient replay = %q, %v; want second", body, err)
+	}
+}
+
+func TestHTTPReplayIgnores(t *testing.T) {
+	fake := testkit.NewHTTP(t)
+	fake.On("POST", "https://api.test/log?ts=1&v=2").Respond(204, "")
+	c := recordHTTP(t, fake, func(client domain.HTTPClient) {
+		get(t, client, domain.HTTPRequest{Method: "POST", URL: "https://api.test/log?ts=1&v=2", Body: strings.NewReader("at 1")})
+	})
+
+	call := func() error {
+		_, err := get(t, NewHTTPReplayer(c), domain.HTTPRequest{Method: "POST", URL: "https://api.test/log?v=2&ts=9", Body: strings.NewReader("at 9")})
+		c.used = nil
+		return err
+	}
+	if err := call(); err == nil {
+		t.Error("replay matched a different query and body")
+	}
+	c.Match = Matching{IgnoreQuery: []string{"ts"}}
+	if err := call(); err == nil {
+		t.Error("replay matched a different body")
+	}
+	c.Match.IgnoreBody = true
+	if err := call(); err != nil {
+		t.Errorf("replay ignoring ts and the body: %v", err)
+	}
+}
+
+func TestHTTPRecordsErrors(t *testing.T) {
+	fake := testkit.NewHTTP(t)
+	fake.On("GET", "https://down.test/").Fails(errors.New("connection refused"))
+	c := recordHTTP(t, fake, func(client domain.HTTPClient) {
+		get(t, client, domain.HTTPRequest{URL: "https://down.test/"})
+	})
+	if _, err := get(t, NewHTTPReplayer(c), domain.HTTPRequest{URL: "https://down.test/"}); err == nil || err.Error() != "connection refused" {
+		t.Errorf("replayed error = %v", err)
+	}
+}
+
+func TestHTTPRedactsSecrets(t *testing.T) {
+	fake := testkit.NewHTTP(t)
+	fake.On("POST", "https://api.test/login?api_key=k1&user=bob").
+		Respond(200, `{"token":"t0ps3cret","user":"bob"}`).
+		Header("Set-Cookie", "session=abc")
+	req := func() domain.HTTPRequest {
+		return domain.HTTPRequest{
+			Method: "POST",
+			URL:    "https://api.test/login?api_key=k1&user=bob",
+			Header: http.Header{"Authorization": {"Bearer xyz"}, "Content-Type": {"application/json"}},
+			Body:   strings.NewReader(`{"user":"bob","password":"hunter2"}`),
+		}
+	}
+	c := recordHTTP(t, fake, func(client domain.HTTPClient) {
+		// The caller still sees the real response.
+		if body, _ := get(t, client, req()); !strings.Contains(body, "t0ps3cret") {
+			t.Errorf("recorder changed the response: %q", body)
+		}
+	})
+
+	data, _ := os.ReadFile(c.Path())
+	for _, secret := range []string{"k1", "xyz", "hunter2", "t0ps3cret", "abc"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette holds %q:\n%s", secret, data)
+		}
+	}
+	if info, _ := os.Stat(c.Path()); info.Mode().Perm() != 0o600 {
+		t.Errorf("cassette mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// The same call, redacted the same way, still finds its interaction.
+	if body, err := get(t, NewHTTPReplayer(c), req()); err != nil || body != `{"token":"[redacted]","user":"bob"}` {
+		t.Errorf("replay = %q, %v", body, err)
+	}
+}
+
+// streamRunner writes to stdout and stderr in turns, as a real command may.
+type streamRunner struct{ domain.ShellRunner }
+
+func (streamRunner) RunStream(ctx context.Context, opts domain.StreamOptions, name string, args ...string) error {
+	io.WriteString(opts.Stdout, "one\n")
+	io.WriteString(opts.Stderr, "warn\n")
+	io.WriteString(opts.Stdout, "two\n")
+	io.WriteString(opts.Stdout, "three\n")
+	return &shell.ShellError{Command: name, Output: "warn", Cause: errors.New("exit status 1")}
+}
+
+func TestShellReplayKeepsStreamOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	rec := NewShellRecorder(New(path), streamRunner{})
+	rec.RunStream(context.Background(), domain.StreamOptions{}, "build")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	opts := domain.StreamOptions{Stdout: &out, Stderr: prefixWriter{&out, "E:"}}
+	err = NewShellReplayer(c).RunStream(context.Background(), opts, "build")
+
+	if got, want := out.String(), "one\nE:warn\ntwo\nthree\n"; got != want {
+		t.Errorf("replayed output = %q, want %q", got, want)
+	}
+	var serr *shell.ShellError
+	if !errors.As(err, &serr) || serr.Output != "warn" || serr.Cause.Error() != "exit status 1" {
+		t.Errorf("replayed error = %v", err)
+	}
+	if n := len(c.Shell[0].Streamed); n != 3 {
+		t.Errorf("recorded %d chunks, want 3", n)
+	}
+}
+
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+}
+
+func (p prefixWriter) Write(b []byte) (int, error) {
+	io.WriteString(p.w, p.prefix)
+	return p.w.Write(b)
+}
+
+func TestShellReplay(t *testing.T) {
+	fake := testkit.NewShell(t)
+	fake.Expect("git", "status").InDir("/repo").Stdout("clean")
+	fake.Expect("plugin", "--token", "s3cret", "x").Stdout("ok")
+
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	rec := NewShellRecorder(New(path), fake)
+	ctx := context.Background()
+	rec.RunDir(ctx, "/repo", "git", "status")
+	opts := domain.StreamOptions{
+		Env:   []string{"AVRO_FLAG_API_KEY=k1", "AVRO_ARG_URL=https://u:pw@host/", "HOME=/home/a"},
+		Stdin: strings.NewReader(`{"args":{"password":"p1"}}`),
+	}
+	rec.RunStream(ctx, opts, "plugin", "--token", "s3cret", "x")
+
+	data, _ := os.ReadFile(path)
+	for _, secret := range []string{"s3cret", "k1", "pw@", "p1"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette holds %q:\n%s", secret, data)
+		}
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := NewShellReplayer(c)
+	if out, err := replay.RunDir(ctx, "/repo", "git", "status"); err != nil || out != "clean" {
+		t.Errorf("RunDir = %q, %v", out, err)
+	}
+	if _, err := replay.Run(ctx, "git", "status"); err == nil {
+		t.Error("replay matched a call in another directory")
+	}
+
+	stream := func() error {
+		opts.Stdin = strings.NewReader(`{"args":{"password":"p1"}}`)
+		opts.Env[2] = "HOME=/home/b"
+		err := replay.RunStream(ctx, opts, "plugin", "--token", "s3cret", "x")
+		c.used = nil
+		return err
+	}
+	if err := stream(); err == nil {
+		t.Error("replay matched another environment")
+	}
+	c.Match.IgnoreEnv = []string{"HOME"}
+	if err := stream(); err != nil {
+		t.Errorf("replay ignoring HOME: %v", err)
+	}
+}
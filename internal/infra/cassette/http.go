@@ -0,0 +1,198 @@
+package cassette
+
+import (
+	"avro_cli/internal/domain"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPInteraction is one recorded request and its response or error.
+type HTTPInteraction struct {
+	Method   string      `yaml:"method"`
+	URL      string      `yaml:"url"` // with the request's Query applied, secrets redacted
+	Header   http.Header `yaml:"header,omitempty"`
+	Body     Blob        `yaml:"body,omitempty"`
+	Error    string      `yaml:"error,omitempty"`
+	Response HTTPReply   `yaml:"response,omitempty"`
+}
+
+// HTTPReply is the recorded part of a domain.HTTPResponse.
+type HTTPReply struct {
+	Status     int             `yaml:"status,omitempty"`
+	StatusText string          `yaml:"status_text,omitempty"`
+	Proto      string          `yaml:"proto,omitempty"`
+	Header     http.Header     `yaml:"header,omitempty"`
+	Body       Blob            `yaml:"body,omitempty"`
+	Duration   time.Duration   `yaml:"duration,omitempty"`
+	TLS        *domain.TLSInfo `yaml:"tls,omitempty"`
+}
+
+// HTTPRecorder is a domain.HTTPClient that passes requests to another
+// client and records them on a cassette.
+type HTTPRecorder struct {
+	cassette *Cassette
+	client   domain.HTTPClient
+}
+
+// NewHTTPRecorder records the requests made through client on c.
+func NewHTTPRecorder(c *Cassette, client domain.HTTPClient) *HTTPRecorder {
+	return &HTTPRecorder{cassette: c, client: client}
+}
+
+func (r *HTTPRecorder) Do(ctx context.Context, req domain.HTTPRequest) (domain.HTTPResponse, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return domain.HTTPResponse{}, err
+		}
+		req.Body = bytes.NewReader(body)
+	}
+	target, err := requestURL(req)
+	if err != nil {
+		return domain.HTTPResponse{}, err
+	}
+
+	resp, err := r.client.Do(ctx, req)
+
+	in := HTTPInteraction{
+		Method: method(req),
+		URL:    target,
+		Header: redactHeader(req.Header),
+		Body:   newBlob(redactBody(req.Header, body)),
+		Error:  redactError(err),
+	}
+	if err == nil {
+		in.Response = HTTPReply{
+			Status:     resp.Status,
+			StatusText: resp.StatusText,
+			Proto:      resp.Proto,
+			Header:     redactHeader(resp.Header),
+			Body:       newBlob(redactBody(resp.Header, resp.Body)),
+			Duration:   resp.Timing.Total,
+			TLS:        resp.TLS,
+		}
+	}
+
+	r.cassette.mu.Lock()
+	defer r.cassette.mu.Unlock()
+	r.cassette.HTTP = append(r.cassette.HTTP, in)
+	if serr := r.cassette.save(); serr != nil && err == nil {
+		err = fmt.Errorf("recording: %w", serr)
+	}
+	return resp, err
+}
+
+// HTTPReplayer is a domain.HTTPClient answering from a cassette. A request
+// matches an interaction with the same method, URL (query parameters in any
+// order) and body, subject to the cassette's Matching; matches are served in
+// recorded order.
+type HTTPReplayer struct {
+	cassette *Cassette
+}
+
+// NewHTTPReplayer replays the HTTP interactions on c.
+func NewHTTPReplayer(c *Cassette) *HTTPReplayer {
+	return &HTTPReplayer{cassette: c}
+}
+
+func (r *HTTPReplayer) Do(ctx context.Context, req domain.HTTPRequest) (domain.HTTPResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.HTTPResponse{}, err
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return domain.HTTPResponse{}, err
+		}
+	}
+	target, err := requestURL(req)
+	if err != nil {
+		return domain.HTTPResponse{}, err
+	}
+
+	body = redactBody(req.Header, body)
+
+	c := r.cassette
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var candidates []*HTTPInteraction
+	for i := range c.HTTP {
+		in := &c.HTTP[i]
+		if in.Method == method(req) && c.Match.sameURL(in.URL, target) &&
+			(c.Match.IgnoreBody || bytes.Equal(in.Body.Bytes(), body)) {
+			candidates = append(candidates, in)
+		}
+	}
+	in := take(c, candidates)
+	if in == nil {
+		return domain.HTTPResponse{}, &MismatchError{Cassette: c.path, Request: method(req) + " " + target, Recorded: len(c.HTTP), Replayed: len(candidates)}
+	}
+	if in.Error != "" {
+		return domain.HTTPResponse{}, recordedError(in.Error)
+	}
+	reply := in.Response
+	return domain.HTTPResponse{
+		Status:     reply.Status,
+		StatusText: reply.StatusText,
+		Proto:      reply.Proto,
+		Header:     reply.Header,
+		Body:       reply.Body.Bytes(),
+		Timing:     domain.HTTPTiming{Total: reply.Duration},
+		TLS:        reply.TLS,
+	}, nil
+}
+
+func method(req domain.HTTPRequest) string {
+	if req.Method == "" {
+		return http.MethodGet
+	}
+	return req.Method
+}
+
+// requestURL returns the URL the request goes to, with Query merged in, all
+// parameters sorted so equivalent requests compare equal, and secrets
+// redacted.
+func requestURL(req domain.HTTPRequest) (string, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for k, vs := range req.Query {
+		for _, v := range vs {
+			q.Add(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return domain.RedactURL(u.String()), nil
+}
+
+// sameURL compares a recorded URL with that of a call, both from requestURL,
+// leaving out the parameters in IgnoreQuery.
+func (m Matching) sameURL(recorded, call string) bool {
+	if len(m.IgnoreQuery) == 0 {
+		return recorded == call
+	}
+	return m.stripQuery(recorded) == m.stripQuery(call)
+}
+
+func (m Matching) stripQuery(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	q := u.Query()
+	for _, name := range m.IgnoreQuery {
+		q.Del(name)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
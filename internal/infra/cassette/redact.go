@@ -0,0 +1,174 @@
+package cassette
+
+import (
+	"avro_cli/internal/domain"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Secrets are replaced with domain.Redacted before an interaction is written.
+// Replay applies the same redaction to each call before matching it, so a
+// call still finds the interaction recorded for it.
+
+// redactHeader copies h with the values of secret headers (Authorization,
+// Cookie, X-Api-Key, …) replaced.
+func redactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := h.Clone()
+	for name := range out {
+		if domain.SecretName(name) {
+			out[name] = []string{domain.Redacted}
+		}
+	}
+	return out
+}
+
+// redactError returns err's message with the URL of a *url.Error, which
+// the HTTP client wraps its failures in, redacted.
+func redactError(err error) string {
+	text := errorText(err)
+	var uerr *url.Error
+	if errors.As(err, &uerr) && uerr.URL != "" {
+		text = strings.ReplaceAll(text, uerr.URL, domain.RedactURL(uerr.URL))
+	}
+	return text
+}
+
+// redactBody hides secrets in a request or response body: the values of
+// secret members of a JSON document, or of secret parameters of a form.
+// Other bodies are kept as they are.
+func redactBody(header http.Header, body []byte) []byte {
+	if strings.HasPrefix(header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return []byte(domain.RedactQuery(string(body)))
+	}
+	return redactJSON(body, domain.SecretName)
+}
+
+// redactEnv copies a plugin environment with secrets replaced: the values of
+// AVRO_ARG_* and AVRO_FLAG_* variables whose arg or flag name is sensitive,
+// and of other variables with a secret name. The remaining AVRO_* values are
+// redacted as free text (URLs, key=value pairs).
+func redactEnv(env []string) []string {
+	out := make([]string, len(env))
+	for i, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		name, isValue := strings.CutPrefix(key, "AVRO_ARG_")
+		if !isValue {
+			name, isValue = strings.CutPrefix(key, "AVRO_FLAG_")
+		}
+		switch {
+		case isValue && domain.SensitiveName(strings.ReplaceAll(name, "_", "-")):
+			value = domain.Redacted
+		case isValue:
+			value = domain.RedactText(value)
+		case domain.SecretName(key):
+			value = domain.Redacted
+		}
+		out[i] = key + "=" + value
+	}
+	return out
+}
+
+// redactStdin hides the values of sensitive args and flags in the JSON a
+// plugin reads from stdin. Other input is kept as it is.
+func redactStdin(data []byte) []byte {
+	return redactJSON(data, domain.SensitiveName)
+}
+
+// redactJSON replaces the value of every object member of a JSON document
+// whose name secret reports true, keeping the rest byte for byte so replay
+// serves what was received. Data that is not JSON is returned unchanged.
+func redactJSON(data []byte, secret func(name string) bool) []byte {
+	if len(data) == 0 || !json.Valid(data) {
+		return data
+	}
+
+	type level struct {
+		object  bool
+		wantKey bool // in an object, the next token is a member name
+	}
+	var (
+		stack []level
+		spans [][2]int64 // byte ranges of the values to replace
+		hide  bool       // the value about to start belongs to a secret member
+		start int64      // where the hidden value starts
+		depth = -1       // stack depth of a hidden object or array, if any
+		dec   = json.NewDecoder(bytes.NewReader(data))
+	)
+	dec.UseNumber()
+	for {
+		off := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		top := len(stack) - 1
+
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:top]
+			if depth == top {
+				spans = append(spans, [2]int64{start, dec.InputOffset()})
+				depth = -1
+			}
+			if top > 0 && stack[top-1].object {
+				stack[top-1].wantKey = true
+			}
+			continue
+		}
+
+		if top >= 0 && stack[top].object && stack[top].wantKey {
+			name, _ := tok.(string)
+			hide = depth < 0 && secret(name)
+			stack[top].wantKey = false
+			continue
+		}
+
+		// tok starts a value.
+		if hide {
+			start = tokenStart(data, off)
+		}
+		if d, ok := tok.(json.Delim); ok {
+			if hide {
+				depth = len(stack)
+			}
+			stack = append(stack, level{object: d == '{', wantKey: d == '{'})
+		} else {
+			if hide {
+				spans = append(spans, [2]int64{start, dec.InputOffset()})
+			}
+			if top >= 0 && stack[top].object {
+				stack[top].wantKey = true
+			}
+		}
+		hide = false
+	}
+
+	if len(spans) == 0 {
+		return data
+	}
+	quoted, _ := json.Marshal(domain.Redacted)
+	var out bytes.Buffer
+	last := int64(0)
+	for _, s := range spans {
+		out.Write(data[last:s[0]])
+		out.Write(quoted)
+		last = s[1]
+	}
+	out.Write(data[last:])
+	return out.Bytes()
+}
+
+// tokenStart returns the offset of the token following off, skipping the
+// white space and separators the decoder reports as part of the gap.
+func tokenStart(data []byte, off int64) int64 {
+	for off < int64(len(data)) && strings.IndexByte(" \t\r\n:,", data[off]) >= 0 {
+		off++
+	}
+	return off
+}
@@ -0,0 +1,71 @@
+package cassette
+
+import (
+	"avro_cli/internal/domain"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`{"user":"bob","password":"p"}`, `{"user":"bob","password":"[redacted]"}`},
+		// Layout, key order and number formatting are kept.
+		{"{\n  \"token\" : 12.50,\n  \"n\": 1e3\n}", "{\n  \"token\" : \"[redacted]\",\n  \"n\": 1e3\n}"},
+		// A secret object or array is replaced whole.
+		{`{"auth":{"user":"a","pass":"b"},"x":[1,{"secret":[1,2]}]}`, `{"auth":"[redacted]","x":[1,{"secret":"[redacted]"}]}`},
+		{`[{"api_key":"k"},{"name":"n"}]`, `[{"api_key":"[redacted]"},{"name":"n"}]`},
+		// Secret-looking values are not names.
+		{`{"name":"token"}`, `{"name":"token"}`},
+		{`not json {"token":"x"}`, `not json {"token":"x"}`},
+		{``, ``},
+	}
+	for _, tt := range tests {
+		if got := string(redactJSON([]byte(tt.in), domain.SecretName)); got != tt.want {
+			t.Errorf("redactJSON(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	form := http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+	if got := string(redactBody(form, []byte("user=bob&password=p&x=1"))); got != "user=bob&password=[redacted]&x=1" {
+		t.Errorf("form body = %q", got)
+	}
+	if got := string(redactBody(nil, []byte("plain text password=p"))); got != "plain text password=p" {
+		t.Errorf("text body = %q", got)
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	h := http.Header{"Authorization": {"Bearer x"}, "X-Api-Key": {"k"}, "Accept": {"*/*"}}
+	got := redactHeader(h)
+	if got.Get("Authorization") != domain.Redacted || got.Get("X-Api-Key") != domain.Redacted || got.Get("Accept") != "*/*" {
+		t.Errorf("redactHeader = %v", got)
+	}
+	if h.Get("Authorization") != "Bearer x" {
+		t.Error("redactHeader changed its input")
+	}
+}
+
+func TestRedactEnv(t *testing.T) {
+	env := []string{
+		"AVRO_ARG_BODY={\"a\":1}",
+		"AVRO_FLAG_API_KEY=k",
+		"AVRO_ARG_URL=https://api.test/?token=t&x=1",
+		"AVRO_ARG_NAME=plain",
+		"GITHUB_TOKEN=g",
+		"PATH=/bin",
+	}
+	want := []string{
+		"AVRO_ARG_BODY=[redacted]",
+		"AVRO_FLAG_API_KEY=[redacted]",
+		"AVRO_ARG_URL=https://api.test/?token=[redacted]&x=1",
+		"AVRO_ARG_NAME=plain",
+		"GITHUB_TOKEN=[redacted]",
+		"PATH=/bin",
+	}
+	if got := redactEnv(env); !slices.Equal(got, want) {
+		t.Errorf("redactEnv = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,281 @@
+package cassette
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/infra/shell"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ShellInteraction is one recorded command and its outcome. Run and RunDir
+// calls keep their result in Output; RunStream calls keep their writes to
+// stdout and stderr in Streamed, in the order they happened. Args, Env and
+// Stdin are stored with secrets redacted.
+type ShellInteraction struct {
+	Stream bool     `yaml:"stream,omitempty"`
+	Dir    string   `yaml:"dir,omitempty"`
+	Name   string   `yaml:"name"`
+	Args   []string `yaml:"args,omitempty"`
+	Env    []string `yaml:"env,omitempty"`
+	Stdin  Blob     `yaml:"stdin,omitempty"`
+
+	Output   string  `yaml:"output,omitempty"`
+	Streamed []Chunk `yaml:"streamed,omitempty"`
+	Error    string  `yaml:"error,omitempty"`
+	// ErrorOutput is the Output of a *shell.ShellError, recreated on replay.
+	ErrorOutput string `yaml:"error_output,omitempty"`
+}
+
+// Chunk is a piece of a streamed command's output, on stdout unless Stderr
+// is set. Consecutive writes to the same stream share a chunk.
+type Chunk struct {
+	Stderr bool `yaml:"stderr,omitempty"`
+	Blob   `yaml:",inline"`
+}
+
+// shellCall describes a call for recording or matching, with its secrets
+// redacted.
+func shellCall(stream bool, dir, name string, args, env []string, stdin []byte) ShellInteraction {
+	return ShellInteraction{
+		Stream: stream,
+		Dir:    dir,
+		Name:   name,
+		Args:   domain.RedactArgs(args),
+		Env:    redactEnv(sortedEnv(env)),
+		Stdin:  newBlob(redactStdin(stdin)),
+	}
+}
+
+func (in *ShellInteraction) matches(call ShellInteraction, m Matching) bool {
+	return in.Stream == call.Stream && in.Dir == call.Dir && in.Name == call.Name &&
+		slices.Equal(in.Args, call.Args) && slices.Equal(m.env(in.Env), m.env(call.Env)) &&
+		(m.IgnoreStdin || bytes.Equal(in.Stdin.Bytes(), call.Stdin.Bytes()))
+}
+
+// env returns env without the variables in IgnoreEnv.
+func (m Matching) env(env []string) []string {
+	if len(m.IgnoreEnv) == 0 {
+		return env
+	}
+	return slices.DeleteFunc(slices.Clone(env), func(kv string) bool {
+		name, _, _ := strings.Cut(kv, "=")
+		return slices.Contains(m.IgnoreEnv, name)
+	})
+}
+
+func (in *ShellInteraction) String() string {
+	return strings.Join(append([]string{in.Name}, in.Args...), " ")
+}
+
+// setError records err, keeping the output of a shell error apart from its cause.
+func (in *ShellInteraction) setError(err error) {
+	var serr *shell.ShellError
+	if errors.As(err, &serr) {
+		in.ErrorOutput = serr.Output
+		in.Error = errorText(serr.Cause)
+		return
+	}
+	in.Error = errorText(err)
+}
+
+func (in *ShellInteraction) err() error {
+	if in.ErrorOutput != "" {
+		return &shell.ShellError{Command: in.Name, Output: in.ErrorOutput, Cause: recordedError(in.Error)}
+	}
+	return recordedError(in.Error)
+}
+
+// ShellRecorder is a domain.ShellRunner that passes commands to another
+// runner and records them on a cassette.
+type ShellRecorder struct {
+	cassette *Cassette
+	runner   domain.ShellRunner
+}
+
+// NewShellRecorder records the commands run through runner on c.
+func NewShellRecorder(c *Cassette, runner domain.ShellRunner) *ShellRecorder {
+	return &ShellRecorder{cassette: c, runner: runner}
+}
+
+func (r *ShellRecorder) Run(ctx context.Context, name string, args ...string) (string, error) {
+	return r.RunDir(ctx, "", name, args...)
+}
+
+func (r *ShellRecorder) RunDir(ctx context.Context, dir string, name string, args ...string) (string, error) {
+	var out string
+	var err error
+	if dir == "" {
+		out, err = r.runner.Run(ctx, name, args...)
+	} else {
+		out, err = r.runner.RunDir(ctx, dir, name, args...)
+	}
+	in := shellCall(false, dir, name, args, nil, nil)
+	in.Output = out
+	in.setError(err)
+	return out, r.record(in, err)
+}
+
+func (r *ShellRecorder) RunStream(ctx context.Context, opts domain.StreamOptions, name string, args ...string) error {
+	var stdin []byte
+	if opts.Stdin != nil {
+		var err error
+		if stdin, err = io.ReadAll(opts.Stdin); err != nil {
+			return err
+		}
+		opts.Stdin = bytes.NewReader(stdin)
+	}
+	var streamed streamLog
+	opts.Stdout = streamed.tee(opts.Stdout, false)
+	opts.Stderr = streamed.tee(opts.Stderr, true)
+
+	err := r.runner.RunStream(ctx, opts, name, args...)
+
+	in := shellCall(true, opts.Dir, name, args, opts.Env, stdin)
+	in.Streamed = streamed.chunks()
+	in.setError(err)
+	return r.record(in, err)
+}
+
+// record appends in to the cassette, returning err or else any failure to save.
+func (r *ShellRecorder) record(in ShellInteraction, err error) error {
+	r.cassette.mu.Lock()
+	defer r.cassette.mu.Unlock()
+	r.cassette.Shell = append(r.cassette.Shell, in)
+	if serr := r.cassette.save(); serr != nil && err == nil {
+		return fmt.Errorf("recording: %w", serr)
+	}
+	return err
+}
+
+// ShellReplayer is a domain.ShellRunner answering from a cassette. A command
+// matches an interaction with the same name, args, directory, environment
+// and stdin, subject to the cassette's Matching; matches are served in
+// recorded order.
+type ShellReplayer struct {
+	cassette *Cassette
+}
+
+// NewShellReplayer replays the shell interactions on c.
+func NewShellReplayer(c *Cassette) *ShellReplayer {
+	return &ShellReplayer{cassette: c}
+}
+
+func (r *ShellReplayer) Run(ctx context.Context, name string, args ...string) (string, error) {
+	return r.RunDir(ctx, "", name, args...)
+}
+
+func (r *ShellReplayer) RunDir(ctx context.Context, dir string, name string, args ...string) (string, error) {
+	in, err := r.find(ctx, shellCall(false, dir, name, args, nil, nil))
+	if err != nil {
+		return "", err
+	}
+	return in.Output, in.err()
+}
+
+func (r *ShellReplayer) RunStream(ctx context.Context, opts domain.StreamOptions, name string, args ...string) error {
+	var stdin []byte
+	if opts.Stdin != nil {
+		var err error
+		if stdin, err = io.ReadAll(opts.Stdin); err != nil {
+			return err
+		}
+	}
+	in, err := r.find(ctx, shellCall(true, opts.Dir, name, args, opts.Env, stdin))
+	if err != nil {
+		return err
+	}
+	for _, c := range in.Streamed {
+		w := opts.Stdout
+		if c.Stderr {
+			w = opts.Stderr
+		}
+		if w != nil {
+			w.Write(c.Bytes())
+		}
+	}
+	return in.err()
+}
+
+func (r *ShellReplayer) find(ctx context.Context, call ShellInteraction) (*ShellInteraction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c := r.cassette
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var candidates []*ShellInteraction
+	for i := range c.Shell {
+		if c.Shell[i].matches(call, c.Match) {
+			candidates = append(candidates, &c.Shell[i])
+		}
+	}
+	in := take(c, candidates)
+	if in == nil {
+		return nil, &MismatchError{Cassette: c.path, Request: call.String(), Recorded: len(c.Shell), Replayed: len(candidates)}
+	}
+	return in, nil
+}
+
+// streamLog collects the writes of a streamed command to stdout and stderr
+// in the order they happen.
+type streamLog struct {
+	mu  sync.Mutex
+	raw []rawChunk
+}
+
+type rawChunk struct {
+	stderr bool
+	data   []byte
+}
+
+// tee returns a writer copying to both w, which may be nil, and the log.
+func (l *streamLog) tee(w io.Writer, stderr bool) io.Writer {
+	lw := streamWriter{log: l, stderr: stderr}
+	if w == nil {
+		return lw
+	}
+	return io.MultiWriter(w, lw)
+}
+
+// chunks returns the writes, consecutive ones to the same stream merged.
+func (l *streamLog) chunks() []Chunk {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]Chunk, len(l.raw))
+	for i, c := range l.raw {
+		out[i] = Chunk{Stderr: c.stderr, Blob: newBlob(c.data)}
+	}
+	return out
+}
+
+type streamWriter struct {
+	log    *streamLog
+	stderr bool
+}
+
+func (w streamWriter) Write(p []byte) (int, error) {
+	l := w.log
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n := len(l.raw); n > 0 && l.raw[n-1].stderr == w.stderr {
+		l.raw[n-1].data = append(l.raw[n-1].data, p...)
+	} else {
+		l.raw = append(l.raw, rawChunk{stderr: w.stderr, data: slices.Clone(p)})
+	}
+	return len(p), nil
+}
+
+// sortedEnv returns env in a stable order, since plugins build it from a map.
+func sortedEnv(env []string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+	return slices.Sorted(slices.Values(env))
+}
@@ -1,28 +1,148 @@
 package fs
 
-import "os"
+import (
+	"avro_cli/internal/domain"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"path/filepath"
+	"strings"
 
-// LocalFS implements domain.FileSystem using the local file system.
-type LocalFS struct{}
+	"github.com/spf13/afero"
+)
 
-// New creates a new local file system adapter.
-func New() *LocalFS { return &LocalFS{} }
+// FS implements domain.FileSystem on top of an afero.Fs, so the same
+// commands can run against the local disk, memory, or a restricted view.
+type FS struct {
+	fs afero.Fs
+}
+
+// Wrap adapts any afero.Fs.
+func Wrap(fs afero.Fs) *FS {
+	return &FS{fs: fs}
+}
+
+// New returns the local file system.
+func New() *FS { return Wrap(afero.NewOsFs()) }
+
+// NewMemory returns an empty in-memory file system.
+func NewMemory() *FS { return Wrap(afero.NewMemMapFs()) }
+
+// NewReadOnly returns a view of base in which every write fails.
+func NewReadOnly(base *FS) *FS { return Wrap(afero.NewReadOnlyFs(base.fs)) }
+
+// NewSandbox returns a view of base confined to root: paths, absolute ones
+// included, resolve below root and cannot climb out of it with "..".
+// Symbolic links are followed, so a link below root that points elsewhere
+// leads out of the sandbox: it guards against mistaken paths, not against a
+// hostile tree.
+func NewSandbox(base *FS, root string) *FS {
+	return Wrap(afero.NewBasePathFs(base.fs, root))
+}
+
+// NewCopyOnWrite returns a view of base whose writes land in memory, leaving
+// base untouched. Reads see the written files over those of base, which makes
+// it a dry run for commands that modify files.
+func NewCopyOnWrite(base *FS) *FS {
+	return Wrap(afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base.fs), afero.NewMemMapFs()))
+}
+
+// Parse builds the file system named by spec:
+//
+//	local            the local disk (also for "")
+//	memory           an empty in-memory file system
+//	readonly         the local disk, refusing writes
+//	cow              the local disk, with writes kept in memory (dry run)
+//	sandbox:<root>   the local disk below root
+func Parse(spec string) (*FS, error) {
+	kind, root, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "", "local":
+		return New(), nil
+	case "memory":
+		return NewMemory(), nil
+	case "readonly":
+		return NewReadOnly(New()), nil
+	case "cow":
+		return NewCopyOnWrite(New()), nil
+	case "sandbox":
+		if root == "" {
+			return nil, fmt.Errorf("file system %q: sandbox needs a root, e.g. sandbox:/tmp/avro", spec)
+		}
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		return NewSandbox(New(), abs), nil
+	}
+	return nil, fmt.Errorf("unknown file system %q (expected local, memory, readonly, cow or sandbox:<root>)", spec)
+}
+
+// Local reports whether f is the local disk itself rather than a view of it.
+func (f *FS) Local() bool {
+	_, ok := f.fs.(*afero.OsFs)
+	return ok
+}
 
-func (f *LocalFS) ReadFile(path string) ([]byte, error) {
-	return os.ReadFile(path)
+// Afero returns the underlying afero.Fs.
+func (f *FS) Afero() afero.Fs { return f.fs }
+
+func (f *FS) ReadFile(path string) ([]byte, error) {
+	return afero.ReadFile(f.fs, path)
 }
 
-func (f *LocalFS) WriteFile(path string, data []byte) error {
-	return os.WriteFile(path, data, 0644)
+func (f *FS) WriteFile(path string, data []byte, perm iofs.FileMode) error {
+	return afero.WriteFile(f.fs, path, data, perm)
 }
 
-func (f *LocalFS) Exists(path string) bool {
-	_, err := os.Stat(path)
+func (f *FS) WriteFileAtomic(path string, data []byte, perm iofs.FileMode) error {
+	tmp, err := afero.TempFile(f.fs, filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// On success the rename has consumed tmp and this fails harmlessly.
+	defer f.fs.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := f.fs.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return f.fs.Rename(tmp.Name(), path)
+}
+
+func (f *FS) Open(path string) (io.ReadCloser, error) {
+	return f.fs.Open(path)
+}
+
+func (f *FS) Create(path string) (io.WriteCloser, error) {
+	return f.fs.Create(path)
+}
+
+func (f *FS) OpenFile(path string, flag int, perm iofs.FileMode) (domain.File, error) {
+	return f.fs.OpenFile(path, flag, perm)
+}
+
+func (f *FS) Exists(path string) bool {
+	_, err := f.fs.Stat(path)
 	return err == nil
 }
 
-func (f *LocalFS) ListDir(path string) ([]string, error) {
-	entries, err := os.ReadDir(path)
+func (f *FS) Stat(path string) (iofs.FileInfo, error) {
+	return f.fs.Stat(path)
+}
+
+func (f *FS) ListDir(path string) ([]string, error) {
+	entries, err := afero.ReadDir(f.fs, path)
 	if err != nil {
 		return nil, err
 	}
@@ -32,3 +152,31 @@ func (f *LocalFS) ListDir(path string) ([]string, error) {
 	}
 	return names, nil
 }
+
+func (f *FS) Walk(root string, fn filepath.WalkFunc) error {
+	return afero.Walk(f.fs, root, fn)
+}
+
+func (f *FS) Glob(pattern string) ([]string, error) {
+	return afero.Glob(f.fs, pattern)
+}
+
+func (f *FS) MkdirAll(path string, perm iofs.FileMode) error {
+	return f.fs.MkdirAll(path, perm)
+}
+
+func (f *FS) Remove(path string) error {
+	return f.fs.Remove(path)
+}
+
+func (f *FS) RemoveAll(path string) error {
+	return f.fs.RemoveAll(path)
+}
+
+func (f *FS) Rename(oldpath, newpath string) error {
+	return f.fs.Rename(oldpath, newpath)
+}
+
+func (f *FS) Chmod(path string, mode iofs.FileMode) error {
+	return f.fs.Chmod(path, mode)
+}
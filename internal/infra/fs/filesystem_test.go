@@ -0,0 +1,258 @@
+package fs
+
+import (
+	"errors"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		local   bool
+		wantErr string
+	}{
+		{"", true, ""},
+		{"local", true, ""},
+		{"memory", false, ""},
+		{"readonly", false, ""},
+		{"cow", false, ""},
+		{"sandbox:" + t.TempDir(), false, ""},
+		{"sandbox", false, "sandbox needs a root"},
+		{"sandbox:", false, "sandbox needs a root"},
+		{"tmpfs", false, `unknown file system "tmpfs"`},
+	}
+	for _, tt := range tests {
+		f, err := Parse(tt.spec)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) = %v, want an error containing %q", tt.spec, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.spec, err)
+			continue
+		}
+		if f.Local() != tt.local {
+			t.Errorf("Parse(%q).Local() = %v, want %v", tt.spec, f.Local(), tt.local)
+		}
+	}
+}
+
+func TestSandbox(t *testing.T) {
+	root := t.TempDir()
+	box := NewSandbox(New(), root)
+
+	if err := box.MkdirAll("/etc", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path    string
+		refused bool
+	}{
+		{"/etc/avro.conf", false},
+		{"/a/../etc/avro.conf", false},
+		{"/a/../../etc/avro.conf", true},
+		{"../../etc/avro.conf", true},
+	}
+	for _, tt := range tests {
+		err := box.WriteFile(tt.path, []byte(tt.path), 0o644)
+		if tt.refused {
+			if err == nil {
+				t.Errorf("WriteFile(%q) was not refused", tt.path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("WriteFile(%q): %v", tt.path, err)
+		}
+		data, err := os.ReadFile(filepath.Join(root, "etc", "avro.conf"))
+		if err != nil || string(data) != tt.path {
+			t.Errorf("WriteFile(%q) landed elsewhere: %q, %v", tt.path, data, err)
+		}
+	}
+	if _, err := os.Stat("/etc/avro.conf"); err == nil {
+		t.Error("sandbox wrote outside its root")
+	}
+
+	if err := box.WriteFileAtomic("/etc/state", []byte("x"), 0o600); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+	if names, _ := box.ListDir("/etc"); strings.Join(names, ",") != "avro.conf,state" {
+		t.Errorf("ListDir(/etc) = %q, want the two files and no temporary one", names)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	base := NewMemory()
+	base.WriteFile("/f", []byte("base"), 0o644)
+	ro := NewReadOnly(base)
+
+	if data, err := ro.ReadFile("/f"); err != nil || string(data) != "base" {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+	writes := map[string]func() error{
+		"WriteFile":       func() error { return ro.WriteFile("/f", []byte("x"), 0o644) },
+		"WriteFileAtomic": func() error { return ro.WriteFileAtomic("/f", []byte("x"), 0o644) },
+		"Create":          func() error { _, err := ro.Create("/g"); return err },
+		"OpenFile":        func() error { _, err := ro.OpenFile("/f", os.O_WRONLY|os.O_APPEND, 0o644); return err },
+		"MkdirAll":        func() error { return ro.MkdirAll("/d", 0o755) },
+		"Remove":          func() error { return ro.Remove("/f") },
+		"Rename":          func() error { return ro.Rename("/f", "/g") },
+		"Chmod":           func() error { return ro.Chmod("/f", 0o600) },
+	}
+	for name, write := range writes {
+		if err := write(); err == nil {
+			t.Errorf("%s succeeded on a read-only file system", name)
+		}
+	}
+	if data, _ := base.ReadFile("/f"); string(data) != "base" {
+		t.Errorf("base changed to %q", data)
+	}
+}
+
+func TestCopyOnWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "f")
+	os.WriteFile(path, []byte("disk"), 0o644)
+	cow := NewCopyOnWrite(New())
+
+	if data, err := cow.ReadFile(path); err != nil || string(data) != "disk" {
+		t.Errorf("ReadFile before writing = %q, %v", data, err)
+	}
+	if err := cow.WriteFile(path, []byte("memory"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cow.WriteFile(filepath.Join(dir, "new"), []byte("memory"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := cow.ReadFile(path); string(data) != "memory" {
+		t.Errorf("ReadFile after writing = %q, want the written content", data)
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "disk" {
+		t.Errorf("disk file changed to %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new")); !errors.Is(err, iofs.ErrNotExist) {
+		t.Errorf("new file reached the disk: %v", err)
+	}
+}
+
+// backends returns a fresh local and in-memory file system with the root
+// each should work below.
+func backends(t *testing.T) map[string]struct {
+	fs   *FS
+	root string
+} {
+	return map[string]struct {
+		fs   *FS
+		root string
+	}{
+		"local":  {New(), t.TempDir()},
+		"memory": {NewMemory(), "/work"},
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			f, path := b.fs, filepath.Join(b.root, "state.yaml")
+			f.MkdirAll(b.root, 0o755)
+			f.WriteFile(path, []byte("old"), 0o644)
+
+			if err := f.WriteFileAtomic(path, []byte("new"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := f.ReadFile(path); string(data) != "new" {
+				t.Errorf("content = %q, want new", data)
+			}
+			if info, _ := f.Stat(path); info.Mode().Perm() != 0o600 {
+				t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+			}
+			if names, _ := f.ListDir(b.root); strings.Join(names, ",") != "state.yaml" {
+				t.Errorf("ListDir = %q, want no temporary files left", names)
+			}
+		})
+	}
+}
+
+func TestOpenFile(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			f, path := b.fs, filepath.Join(b.root, "log")
+			f.MkdirAll(b.root, 0o755)
+
+			excl := os.O_CREATE | os.O_EXCL | os.O_WRONLY
+			lock, err := f.OpenFile(path, excl, 0o600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lock.Close()
+			if _, err := f.OpenFile(path, excl, 0o600); !errors.Is(err, iofs.ErrExist) {
+				t.Errorf("second O_EXCL open = %v, want ErrExist", err)
+			}
+
+			for _, line := range []string{"one\n", "two\n"} {
+				w, err := f.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				w.Write([]byte(line))
+				w.Close()
+			}
+
+			r, err := f.OpenFile(path, os.O_RDONLY, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			info, err := r.Stat()
+			if err != nil || info.Size() != 8 {
+				t.Fatalf("Stat = %v, %v; want 8 bytes", info, err)
+			}
+			buf := make([]byte, 3)
+			if _, err := r.ReadAt(buf, 4); err != nil || string(buf) != "two" {
+				t.Errorf("ReadAt(4) = %q, %v; want two", buf, err)
+			}
+		})
+	}
+}
+
+func TestMutations(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			f, root := b.fs, b.root
+			f.MkdirAll(filepath.Join(root, "d", "e"), 0o755)
+			f.WriteFile(filepath.Join(root, "d", "b.txt"), []byte("b"), 0o644)
+			f.WriteFile(filepath.Join(root, "d", "a.txt"), []byte("a"), 0o644)
+
+			if names, _ := f.ListDir(filepath.Join(root, "d")); strings.Join(names, ",") != "a.txt,b.txt,e" {
+				t.Errorf("ListDir = %q, want sorted names", names)
+			}
+			if m, _ := f.Glob(filepath.Join(root, "d", "*.txt")); len(m) != 2 {
+				t.Errorf("Glob = %q, want both txt files", m)
+			}
+
+			if err := f.Rename(filepath.Join(root, "d", "a.txt"), filepath.Join(root, "d", "e", "a.txt")); err != nil {
+				t.Fatal(err)
+			}
+			if f.Exists(filepath.Join(root, "d", "a.txt")) || !f.Exists(filepath.Join(root, "d", "e", "a.txt")) {
+				t.Error("Rename did not move the file")
+			}
+			if err := f.Chmod(filepath.Join(root, "d", "b.txt"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if info, _ := f.Stat(filepath.Join(root, "d", "b.txt")); info.Mode().Perm() != 0o600 {
+				t.Errorf("mode after Chmod = %v", info.Mode().Perm())
+			}
+
+			if err := f.RemoveAll(filepath.Join(root, "d")); err != nil || f.Exists(filepath.Join(root, "d")) {
+				t.Errorf("RemoveAll = %v, dir still there: %v", err, f.Exists(filepath.Join(root, "d")))
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package logging
+
+import (
+	"avro_cli/internal/config"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// maxLogSize is the size at which avro.log is rotated.
+	maxLogSize = 5 << 20
+	// logBackups is how many rotated files (avro.log.1 ...) are kept.
+	logBackups = 3
+)
+
+// Dir returns the log directory (~/.avro/logs).
+func Dir() string {
+	return filepath.Join(config.Dir(), "logs")
+}
+
+// Logging owns the process logger: JSON records to a rotating file in Dir(),
+// optionally mirrored as text to a terminal stream.
+type Logging struct {
+	Logger *slog.Logger
+
+	level  *slog.LevelVar
+	mirror *switchWriter
+	file   *RotatingFile
+}
+
+// Setup creates the logger at the given level. If the log file cannot be
+// opened the returned Logging still works, mirroring only, along with the error.
+func Setup(level slog.Level) (*Logging, error) {
+	l := &Logging{level: new(slog.LevelVar), mirror: &switchWriter{}}
+	l.level.Set(level)
+
+	var out io.Writer = io.Discard
+	file, err := OpenRotating(filepath.Join(Dir(), "avro.log"), maxLogSize, logBackups)
+	if err == nil {
+		l.file, out = file, file
+	}
+
+	opts := &slog.HandlerOptions{Level: l.level}
+	l.Logger = slog.New(fanout{
+		slog.NewJSONHandler(out, opts),
+		slog.NewTextHandler(l.mirror, opts),
+	})
+	return l, err
+}
+
+// SetLevel changes the minimum level of every record from now on.
+func (l *Logging) SetLevel(level slog.Level) { l.level.Set(level) }
+
+// Mirror additionally writes records as text to w; nil stops mirroring.
+func (l *Logging) Mirror(w io.Writer) { l.mirror.set(w) }
+
+// Close closes the log file.
+func (l *Logging) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// ParseLevel maps a config log_level (debug, info, warn, error) to a slog level.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToLower(s))); err != nil {
+		return slog.LevelInfo, errors.New("unknown log level " + s)
+	}
+	return level, nil
+}
+
+// Discard returns l, or a logger that drops everything when l is nil, so
+// components can take an optional logger.
+func Discard(l *slog.Logger) *slog.Logger {
+	if l == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return l
+}
+
+// switchWriter forwards to a writer that can be swapped at any time.
+type switchWriter struct {
+	w atomic.Pointer[io.Writer]
+}
+
+func (s *switchWriter) set(w io.Writer) {
+	if w == nil {
+		s.w.Store(nil)
+		return
+	}
+	s.w.Store(&w)
+}
+
+func (s *switchWriter) Write(p []byte) (int, error) {
+	if w := s.w.Load(); w != nil {
+		return (*w).Write(p)
+	}
+	return len(p), nil
+}
+
+// fanout sends every record to each handler.
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range f {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RotatingFile is an append-only log file that is renamed to path.1 (shifting
+// older backups up to path.<Backups>) once it would grow past MaxSize.
+type RotatingFile struct {
+	Path    string
+	MaxSize int64
+	Backups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// OpenRotating opens or creates the file at path, creating its directory.
+func OpenRotating(path string, maxSize int64, backups int) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	r := &RotatingFile{Path: path, MaxSize: maxSize, Backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.MaxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	// Logs can mention hosts, paths and errors: owner only, which also
+	// tightens files created before the mode was.
+	f, err := os.OpenFile(r.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	_ = f.Chmod(0o600)
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+// rotate shifts path.N-1 to path.N, down to path to path.1, dropping the oldest.
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+	for i := r.Backups; i > 0; i-- {
+		from := r.Path
+		if i > 1 {
+			from = fmt.Sprintf("%s.%d", r.Path, i-1)
+		}
+		// Missing backups are expected until the log has rotated Backups times.
+		_ = os.Rename(from, fmt.Sprintf("%s.%d", r.Path, i))
+	}
+	if r.Backups == 0 {
+		_ = os.Remove(r.Path)
+	}
+	return r.open()
+}
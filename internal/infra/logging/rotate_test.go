@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "avro.log")
+	r, err := OpenRotating(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for _, line := range []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n", "eeee\n", "ffff\n", "gggg\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Each file holds two lines; the oldest pair was dropped.
+	want := map[string]string{
+		path:        "gggg\n",
+		path + ".1": "eeee\nffff\n",
+		path + ".2": "cccc\ndddd\n",
+		path + ".3": "",
+	}
+	for p, w := range want {
+		if got := readFile(t, p); got != w {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), got, w)
+		}
+	}
+}
+
+func TestRotatingFileAppendsAndTightens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "avro.log")
+	os.WriteFile(path, []byte("old\n"), 0o644)
+
+	r, err := OpenRotating(path, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Write([]byte("new\n"))
+	r.Close()
+
+	if got := readFile(t, path); got != "old\nnew\n" {
+		t.Errorf("log = %q", got)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if _, err := r.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write after Close = %v, want os.ErrClosed", err)
+	}
+}
+
+func TestRotatingFileOversizedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "avro.log")
+	r, err := OpenRotating(path, 4, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// A write larger than MaxSize still lands, in a file of its own.
+	r.Write([]byte("0123456789\n"))
+	r.Write([]byte("x\n"))
+	if got := readFile(t, path); got != "x\n" {
+		t.Errorf("log = %q, want only the last write", got)
+	}
+	if got := readFile(t, path+".1"); got != "" {
+		t.Errorf("backup written with Backups 0: %q", got)
+	}
+}
@@ -1,59 +1,154 @@
 package net
 
 import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/infra/logging"
 	"context"
+	"crypto/tls"
 	"io"
+	"log/slog"
 	"net/http"
-	"strings"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"time"
 )
 
 // Client implements domain.HTTPClient using net/http.
 type Client struct {
 	client *http.Client
+	log    *slog.Logger
 }
 
-// New creates a new HTTP client.
-func New() *Client {
-	return &Client{client: &http.Client{}}
+// New creates a new HTTP client. Every request is logged to log at debug
+// level; log may be nil.
+func New(log *slog.Logger) *Client {
+	return &Client{client: &http.Client{}, log: logging.Discard(log)}
 }
 
-func (c *Client) Get(ctx context.Context, url string, headers map[string]string) (int, string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// Do sends r. A body without a Content-Type header is sent as JSON.
+func (c *Client) Do(ctx context.Context, r domain.HTTPRequest) (domain.HTTPResponse, error) {
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	u, err := url.Parse(r.URL)
 	if err != nil {
-		return 0, "", err
+		return domain.HTTPResponse{}, err
+	}
+	if len(r.Query) > 0 {
+		// Append rather than re-encode: the query already in URL goes out as
+		// written, in its order and encoding, which signed URLs depend on.
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += r.Query.Encode()
 	}
-	setHeaders(req, headers)
-	return c.do(req)
-}
 
-func (c *Client) Post(ctx context.Context, url string, body string, headers map[string]string) (int, string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(body))
+	var t timer
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, t.trace()), method, u.String(), r.Body)
 	if err != nil {
-		return 0, "", err
+		return domain.HTTPResponse{}, err
 	}
-	if _, ok := headers["Content-Type"]; !ok {
+	for k, vs := range r.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if r.Body != nil && req.Header.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "application/json")
 	}
-	setHeaders(req, headers)
-	return c.do(req)
+	return c.do(req, &t)
+}
+
+func (c *Client) do(req *http.Request, t *timer) (domain.HTTPResponse, error) {
+	t.start = time.Now()
+	resp, err := c.roundTrip(req, t)
+
+	// Passwords and secret query parameters are redacted; headers are never logged.
+	attrs := []any{"method", req.Method, "url", domain.RedactURL(req.URL.String()), "status", resp.Status, "duration", resp.Timing.Total}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	c.log.Debug("http request", attrs...)
+	return resp, err
 }
 
-func (c *Client) do(req *http.Request) (int, string, error) {
+func (c *Client) roundTrip(req *http.Request, t *timer) (domain.HTTPResponse, error) {
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return 0, "", err
+		return domain.HTTPResponse{Timing: t.timing()}, err
 	}
 	defer resp.Body.Close()
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return resp.StatusCode, "", err
+	out := domain.HTTPResponse{
+		Status:     resp.StatusCode,
+		StatusText: resp.Status,
+		Proto:      resp.Proto,
+		Header:     resp.Header,
+		TLS:        tlsInfo(resp.TLS),
+	}
+	out.Body, err = io.ReadAll(resp.Body)
+	out.Timing = t.timing()
+	return out, err
+}
+
+// timer records the phases of one request through an httptrace.ClientTrace.
+// Dialing may report from several goroutines at once, hence the lock.
+type timer struct {
+	mu                                             sync.Mutex
+	start, dnsStart, connectStart, tlsStart, wrote time.Time
+	dns, connect, tls, firstByte                   time.Duration
+}
+
+func (t *timer) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.since(&t.dns, &t.dnsStart) },
+		ConnectStart:         func(string, string) { t.mark(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { t.since(&t.connect, &t.connectStart) },
+		TLSHandshakeStart:    func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.since(&t.tls, &t.tlsStart) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.mark(&t.wrote) },
+		GotFirstResponseByte: func() { t.since(&t.firstByte, &t.wrote) },
+	}
+}
+
+func (t *timer) mark(at *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*at = time.Now()
+}
+
+// since sets d to the time elapsed from start, if start was marked.
+func (t *timer) since(d *time.Duration, start *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !start.IsZero() {
+		*d = time.Since(*start)
 	}
-	return resp.StatusCode, string(data), nil
 }
 
-func setHeaders(req *http.Request, headers map[string]string) {
-	for k, v := range headers {
-		req.Header.Set(k, v)
+func (t *timer) timing() domain.HTTPTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return domain.HTTPTiming{DNS: t.dns, Connect: t.connect, TLS: t.tls, FirstByte: t.firstByte, Total: time.Since(t.start)}
+}
+
+func tlsInfo(state *tls.ConnectionState) *domain.TLSInfo {
+	if state == nil {
+		return nil
+	}
+	info := &domain.TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+	}
+	if len(state.PeerCertificates) > 0 {
+		leaf := state.PeerCertificates[0]
+		info.Subject = leaf.Subject.String()
+		info.Issuer = leaf.Issuer.String()
+		info.NotAfter = leaf.NotAfter
 	}
+	return info
 }
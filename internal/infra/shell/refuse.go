@@ -0,0 +1,30 @@
+package shell
+
+import (
+	"avro_cli/internal/domain"
+	"context"
+	"fmt"
+)
+
+// Refusing implements domain.ShellRunner by running nothing: every call
+// fails with Reason. It stands in for Runner when commands must not reach
+// the system, e.g. under a file system view that processes would bypass.
+type Refusing struct {
+	Reason error
+}
+
+func (r Refusing) Run(ctx context.Context, name string, args ...string) (string, error) {
+	return "", r.refuse(name)
+}
+
+func (r Refusing) RunDir(ctx context.Context, dir string, name string, args ...string) (string, error) {
+	return "", r.refuse(name)
+}
+
+func (r Refusing) RunStream(ctx context.Context, opts domain.StreamOptions, name string, args ...string) error {
+	return r.refuse(name)
+}
+
+func (r Refusing) refuse(name string) error {
+	return fmt.Errorf("not running %s: %w", name, r.Reason)
+}
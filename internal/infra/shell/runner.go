@@ -1,17 +1,26 @@
 package shell
 
 import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/infra/logging"
 	"bytes"
 	"context"
+	"io"
+	"log/slog"
+	"os"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 // Runner implements domain.ShellRunner using os/exec.
-type Runner struct{}
+type Runner struct {
+	log *slog.Logger
+}
 
-// New creates a new shell runner.
-func New() *Runner { return &Runner{} }
+// New creates a new shell runner. Every invocation is logged to log at debug
+// level; log may be nil.
+func New(log *slog.Logger) *Runner { return &Runner{log: logging.Discard(log)} }
 
 // Run executes a command and returns its combined output.
 func (r *Runner) Run(ctx context.Context, name string, args ...string) (string, error) {
@@ -20,7 +29,7 @@ func (r *Runner) Run(ctx context.Context, name string, args ...string) (string,
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := r.run(cmd); err != nil {
 		if stderr.Len() > 0 {
 			return "", &ShellError{Command: name, Output: strings.TrimSpace(stderr.String()), Cause: err}
 		}
@@ -37,7 +46,7 @@ func (r *Runner) RunDir(ctx context.Context, dir string, name string, args ...st
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := r.run(cmd); err != nil {
 		if stderr.Len() > 0 {
 			return "", &ShellError{Command: name, Output: strings.TrimSpace(stderr.String()), Cause: err}
 		}
@@ -46,6 +55,64 @@ func (r *Runner) RunDir(ctx context.Context, dir string, name string, args ...st
 	return strings.TrimRight(stdout.String(), "\n"), nil
 }
 
+// RunStream executes a command, forwarding its output to opts.Stdout/Stderr as it arrives.
+func (r *Runner) RunStream(ctx context.Context, opts domain.StreamOptions, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = opts.Dir
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
+	cmd.Stdin = opts.Stdin
+
+	var captured bytes.Buffer
+	cmd.Stdout = opts.Stdout
+	if cmd.Stdout == nil {
+		cmd.Stdout = io.Discard
+	}
+	cmd.Stderr = &captured
+	if opts.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(opts.Stderr, &captured)
+	}
+
+	if err := r.run(cmd); err != nil {
+		if captured.Len() > 0 {
+			return &ShellError{Command: name, Output: lastLine(captured.String()), Cause: err}
+		}
+		return err
+	}
+	return nil
+}
+
+// run runs cmd, logging its argv, exit code and duration.
+func (r *Runner) run(cmd *exec.Cmd) error {
+	start := time.Now()
+	r.log.Debug("shell exec", "argv", domain.RedactArgs(cmd.Args), "dir", cmd.Dir)
+	err := cmd.Run()
+
+	code := -1 // never started
+	if cmd.ProcessState != nil {
+		code = cmd.ProcessState.ExitCode()
+	}
+	attrs := []any{"argv0", cmd.Args[0], "exit_code", code, "duration", time.Since(start)}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	r.log.Debug("shell exit", attrs...)
+	return err
+}
+
+// lastLine returns the final non-empty line of s, which for streamed stderr
+// is usually the actual error after any progress noise.
+func lastLine(s string) string {
+	lines := strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == '\r' })
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
 // ShellError wraps a command execution failure with stderr output.
 type ShellError struct {
 	Command string
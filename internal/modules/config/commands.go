@@ -0,0 +1,225 @@
+package config
+
+import (
+	"avro_cli/internal/app/usercmds"
+	avroconfig "avro_cli/internal/config"
+	"avro_cli/internal/domain"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+)
+
+var keyArg = domain.ArgDef{Name: "key", Description: "Config key (see 'avro config list')", Required: true, Complete: completeKeys}
+
+var getCmd = domain.CommandDescriptor{
+	Category:    category,
+	Name:        "get",
+	Description: "Print the effective value of a config key",
+	Args:        []domain.ArgDef{keyArg},
+	Data: func(ctx domain.CommandContext) domain.Result[domain.Records] {
+		name := ctx.String("key")
+		cfg := avroconfig.Current()
+		value, err := cfg.Get(name)
+		if err != nil {
+			return domain.Fail[domain.Records](err)
+		}
+		return domain.Ok(domain.Records{
+			Items:  []domain.Record{{{Name: "key", Value: name}, {Name: "value", Value: value}}},
+			Single: true,
+			Human:  func(domain.Records) string { return value },
+		})
+	},
+}
+
+var setCmd = domain.CommandDescriptor{
+	Category:    category,
+	Name:        "set",
+	Description: "Write a config key to ~/.avro/config.yaml",
+	Args: []domain.ArgDef{
+		keyArg,
+		{Name: "value", Description: "New value", Required: true, Complete: completeValues},
+	},
+	Action: func(ctx domain.CommandContext) domain.Result[string] {
+		name, value := ctx.String("key"), ctx.String("value")
+		if name == "theme" {
+			if err := avroconfig.CheckTheme(ctx.FS, value); err != nil {
+				return domain.Fail[string](err)
+			}
+		}
+		if err := avroconfig.Set(ctx.FS, name, value); err != nil {
+			return domain.Fail[string](err)
+		}
+		return domain.Ok(fmt.Sprintf("%s = %s (saved to %s)", name, value, avroconfig.Path()))
+	},
+}
+
+var listCmd = domain.CommandDescriptor{
+	Category:    category,
+	Name:        "list",
+	Description: "List every config key with its effective value",
+	Data: func(ctx domain.CommandContext) domain.Result[domain.Records] {
+		cfg := avroconfig.Current()
+		items := make([]domain.Record, len(avroconfig.Keys))
+		for i, k := range avroconfig.Keys {
+			value, _ := cfg.Get(k.Name)
+			items[i] = domain.Record{
+				{Name: "key", Value: k.Name},
+				{Name: "value", Value: value},
+				{Name: "default", Value: k.Default},
+				{Name: "description", Value: k.Description},
+			}
+		}
+		return domain.Ok(domain.Records{
+			Items: items,
+			Human: func(r domain.Records) string {
+				width := 0
+				for _, item := range r.Items {
+					width = max(width, len(item.Get("key").(string)))
+				}
+				lines := make([]string, len(r.Items))
+				for i, item := range r.Items {
+					lines[i] = fmt.Sprintf("%-*s = %v\n%*s   %v", width, item.Get("key"), item.Get("value"), width, "", item.Get("description"))
+				}
+				return strings.Join(lines, "\n")
+			},
+		})
+	},
+}
+
+var editCmd = domain.CommandDescriptor{
+	Category:    category,
+	Name:        "edit",
+	Description: "Open the config file in $VISUAL or $EDITOR, then validate it",
+	Tags:        []string{"settings", "editor"},
+	Interactive: true,
+	Action: func(ctx domain.CommandContext) domain.Result[string] {
+		path := avroconfig.Path()
+		if _, err := ctx.FS.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			if _, err := avroconfig.EnsureDir(ctx.FS); err != nil {
+				return domain.Fail[string](err)
+			}
+			if err := ctx.FS.WriteFile(path, []byte(avroconfig.Template()), 0o644); err != nil {
+				return domain.Fail[string](err)
+			}
+		}
+
+		editor := strings.Fields(editorCommand())
+		opts := domain.StreamOptions{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+		if err := ctx.Shell.RunStream(ctx.Context, opts, editor[0], append(editor[1:], path)...); err != nil {
+			return domain.Fail[string](fmt.Errorf("editor %s: %w", editor[0], err))
+		}
+
+		if err := validationError(path, avroconfig.ValidateFile(ctx.FS, path)); err != nil {
+			return domain.Fail[string](err)
+		}
+		return domain.Ok("Saved " + path)
+	},
+}
+
+var pathCmd = domain.CommandDescriptor{
+	Category:    category,
+	Name:        "path",
+	Description: "Print the config file location",
+	Action: func(ctx domain.CommandContext) domain.Result[string] {
+		return domain.Ok(avroconfig.Path())
+	},
+}
+
+var validateCmd = domain.CommandDescriptor{
+	Category:    category,
+	Name:        "validate",
+	Description: "Check the config file for unknown keys and invalid values",
+	Action: func(ctx domain.CommandContext) domain.Result[string] {
+		path := avroconfig.Path()
+		if _, err := ctx.FS.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return domain.Ok(fmt.Sprintf("No config file at %s; defaults are in use", path))
+		}
+		if err := validationError(path, avroconfig.ValidateFile(ctx.FS, path)); err != nil {
+			return domain.Fail[string](err)
+		}
+		return domain.Ok(path + " is valid")
+	},
+}
+
+var trustCmd = domain.CommandDescriptor{
+	Category:    category,
+	Name:        "trust",
+	Description: "Load the commands of a project's .avro/commands.yaml as it is now",
+	Tags:        []string{"commands", "project", "allow"},
+	Args: []domain.ArgDef{
+		{Name: "file", Description: "Command file (defaults to the nearest .avro/commands.yaml)"},
+	},
+	Action: func(ctx domain.CommandContext) domain.Result[string] {
+		path := ctx.String("file")
+		if path == "" {
+			wd, err := os.Getwd()
+			if err != nil {
+				return domain.Fail[string](err)
+			}
+			if path = usercmds.ProjectFile(ctx.FS, wd); path == "" {
+				return domain.Fail[string](fmt.Errorf("no .avro/%s in %s or its parents", usercmds.FileName, wd))
+			}
+		}
+		if err := usercmds.Trust(ctx.FS, path); err != nil {
+			return domain.Fail[string](err)
+		}
+		return domain.Ok("Trusted " + path + "; edits to it need trusting again")
+	},
+}
+
+// editorCommand picks the user's editor, falling back to a platform default.
+func editorCommand() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if e := strings.TrimSpace(os.Getenv(env)); e != "" {
+			return e
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// validationError folds per-line problems into one error listing them all.
+func validationError(path string, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = "  " + err.Error()
+	}
+	return fmt.Errorf("%s has %d problem(s):\n%s", path, len(errs), strings.Join(lines, "\n"))
+}
+
+func completeKeys(ctx domain.CommandContext, toComplete string) []string {
+	var out []string
+	for _, name := range avroconfig.KeyNames() {
+		if strings.HasPrefix(name, toComplete) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// completeValues suggests the allowed values of the key typed before it.
+func completeValues(ctx domain.CommandContext, toComplete string) []string {
+	key, ok := avroconfig.LookupKey(ctx.String("key"))
+	if !ok {
+		return nil
+	}
+	values := key.Values
+	if key.Name == "theme" {
+		values = avroconfig.ThemeNames(ctx.FS)
+	}
+	var out []string
+	for _, v := range values {
+		if strings.HasPrefix(v, toComplete) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
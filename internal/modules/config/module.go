@@ -0,0 +1,16 @@
+package config
+
+import (
+	"avro_cli/internal/app/registry"
+	"avro_cli/internal/domain"
+)
+
+var category = domain.Category{
+	Name:        "config",
+	Description: "View and edit avro settings",
+	Icon:        "\u2699",
+}
+
+func init() {
+	registry.Global().Register(getCmd, setCmd, listCmd, editCmd, pathCmd, validateCmd, trustCmd)
+}
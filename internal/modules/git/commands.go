@@ -2,37 +2,41 @@ package git
 
 import (
 	"avro_cli/internal/domain"
-	"context"
 	"fmt"
+	"strings"
+	"time"
 )
 
 var cloneCmd = domain.CommandDescriptor{
 	Category:    category,
 	Name:        "clone",
 	Description: "Clone a git repository",
+	Tags:        []string{"download", "checkout"},
 	Args: []domain.ArgDef{
 		{Name: "url", Description: "Repository URL", Required: true},
 		{Name: "dir", Description: "Target directory (optional)", Required: false},
 	},
+	Timeout: 10 * time.Minute,
 	Action: func(ctx domain.CommandContext) domain.Result[string] {
-		url := ctx.Args["url"]
+		url := ctx.String("url")
 		if url == "" {
 			return domain.Fail[string](&domain.ValidationError{Field: "url", Message: "repository URL is required"})
 		}
 
-		args := []string{"clone", url}
-		if dir := ctx.Args["dir"]; dir != "" {
+		// --progress keeps git reporting transfer progress even without a TTY.
+		args := []string{"clone", "--progress", url}
+		if dir := ctx.String("dir"); dir != "" {
 			args = append(args, dir)
 		}
 
-		output, err := ctx.Shell.Run(context.Background(), "git", args...)
+		err := ctx.Shell.RunStream(ctx.Context, domain.StreamOptions{
+			Stdout: ctx.Output.Stdout,
+			Stderr: ctx.Output.Stderr,
+		}, "git", args...)
 		if err != nil {
 			return domain.Fail[string](err)
 		}
-		if output == "" {
-			return domain.Ok(fmt.Sprintf("Cloned %s successfully", url))
-		}
-		return domain.Ok(output)
+		return domain.Ok(fmt.Sprintf("Cloned %s successfully", url))
 	},
 }
 
@@ -41,8 +45,9 @@ var statusCmd = domain.CommandDescriptor{
 	Name:        "status",
 	Aliases:     []string{"st"},
 	Description: "Show git status",
+	Tags:        []string{"changes", "diff", "modified"},
 	Action: func(ctx domain.CommandContext) domain.Result[string] {
-		output, err := ctx.Shell.Run(context.Background(), "git", "status", "--short")
+		output, err := ctx.Shell.Run(ctx.Context, "git", "status", "--short")
 		if err != nil {
 			return domain.Fail[string](err)
 		}
@@ -57,16 +62,27 @@ var logCmd = domain.CommandDescriptor{
 	Category:    category,
 	Name:        "log",
 	Description: "Show recent git log",
+	Tags:        []string{"commits", "history"},
+	Args: []domain.ArgDef{
+		{Name: "ref", Description: "Branch or revision (defaults to HEAD)", Complete: completeBranches},
+	},
 	Flags: []domain.ArgDef{
-		{Name: "count", Short: "n", Description: "Number of commits", Default: "10"},
+		{Name: "count", Short: "n", Description: "Number of commits", Default: "10", Type: domain.ArgInt},
 	},
 	Action: func(ctx domain.CommandContext) domain.Result[string] {
-		count := ctx.Flags["count"]
-		if count == "" {
-			count = "10"
+		count := ctx.Int("count")
+		if count <= 0 {
+			return domain.Fail[string](&domain.ValidationError{Field: "count", Message: "must be a positive number"})
+		}
+		args := []string{"log", "--oneline", "--graph", "--decorate", fmt.Sprintf("-n%d", count)}
+		if ref := ctx.String("ref"); ref != "" {
+			if strings.HasPrefix(ref, "-") {
+				return domain.Fail[string](&domain.ValidationError{Field: "ref", Message: "must be a branch or revision, not an option"})
+			}
+			// Trailing "--" stops git from reading the ref as a path.
+			args = append(args, ref, "--")
 		}
-		output, err := ctx.Shell.Run(context.Background(), "git", "log",
-			"--oneline", "--graph", "--decorate", fmt.Sprintf("-n%s", count))
+		output, err := ctx.Shell.Run(ctx.Context, "git", args...)
 		if err != nil {
 			return domain.Fail[string](err)
 		}
@@ -80,17 +96,57 @@ var branchCmd = domain.CommandDescriptor{
 	Aliases:     []string{"br"},
 	Description: "List git branches",
 	Flags: []domain.ArgDef{
-		{Name: "all", Short: "a", Description: "Show all branches including remotes", Default: ""},
+		{Name: "all", Short: "a", Description: "Show all branches including remotes", Type: domain.ArgBool},
 	},
-	Action: func(ctx domain.CommandContext) domain.Result[string] {
+	Data: func(ctx domain.CommandContext) domain.Result[domain.Records] {
 		args := []string{"branch"}
-		if ctx.Flags["all"] != "" {
+		if ctx.Bool("all") {
 			args = append(args, "-a")
 		}
-		output, err := ctx.Shell.Run(context.Background(), "git", args...)
+		output, err := ctx.Shell.Run(ctx.Context, "git", args...)
 		if err != nil {
-			return domain.Fail[string](err)
+			return domain.Fail[domain.Records](err)
 		}
-		return domain.Ok(output)
+		return domain.Ok(domain.Records{
+			Items: parseBranches(output),
+			Human: func(domain.Records) string { return output },
+		})
 	},
 }
+
+// parseBranches turns `git branch` output into records. Symbolic refs such as
+// "remotes/origin/HEAD -> origin/main" keep only the ref name.
+func parseBranches(output string) []domain.Record {
+	var items []domain.Record
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		current := strings.HasPrefix(line, "* ")
+		name := strings.TrimSpace(strings.TrimPrefix(line, "* "))
+		if ref, _, ok := strings.Cut(name, " -> "); ok {
+			name = ref
+		}
+		items = append(items, domain.Record{
+			{Name: "name", Value: name},
+			{Name: "current", Value: current},
+			{Name: "remote", Value: strings.HasPrefix(name, "remotes/")},
+		})
+	}
+	return items
+}
+
+// completeBranches suggests local and remote branch names.
+func completeBranches(ctx domain.CommandContext, toComplete string) []string {
+	output, err := ctx.Shell.Run(ctx.Context, "git", "branch", "--all", "--format=%(refname:short)")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(output, "\n") {
+		if name != "" && strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/testkit"
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestStatus(t *testing.T) {
+	env := testkit.New(t)
+	env.Shell.Expect("git", "status", "--short").Stdout(" M main.go\n?? new.go\n")
+	env.Run(statusCmd, nil, nil).OK().Contains(" M main.go\n?? new.go")
+
+	env.Shell.Expect("git", "status", "--short")
+	env.Run(statusCmd, nil, nil).OK().Contains("Working tree clean")
+}
+
+func TestStatusOutsideRepo(t *testing.T) {
+	env := testkit.New(t)
+	env.Shell.Expect("git", "status", "--short").Stderr("fatal: not a git repository\n").ExitCode(128)
+	env.Run(statusCmd, nil, nil).Fails("not a git repository")
+}
+
+func TestLog(t *testing.T) {
+	env := testkit.New(t)
+	env.Shell.Expect("git", "log", "--oneline", "--graph", "--decorate", "-n10").Stdout("* abc first\n")
+	env.Run(logCmd, nil, nil).OK().Contains("abc first")
+
+	env.Shell.Expect("git", "log", "--oneline", "--graph", "--decorate", "-n3")
+	env.Run(logCmd, nil, map[string]string{"count": "3"}).OK()
+
+	env.Shell.Expect("git", "log", "--oneline", "--graph", "--decorate", "-n10", "origin/main", "--")
+	env.Run(logCmd, []string{"origin/main"}, nil).OK()
+
+	env.Run(logCmd, []string{"--output=x"}, nil).Fails("not an option")
+	env.Run(logCmd, nil, map[string]string{"count": "0"}).Fails("must be a positive number")
+	env.Run(logCmd, nil, map[string]string{"count": "many"}).Fails("expected an integer")
+}
+
+func TestCompleteBranches(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"", []string{"dev", "main", "origin/main"}},
+		{"ma", []string{"main"}},
+		{"origin/", []string{"origin/main"}},
+		{"x", nil},
+	}
+	for _, tt := range tests {
+		env := testkit.New(t)
+		env.Shell.Expect("git", "branch", "--all", "--format=%(refname:short)").Stdout("dev\nmain\norigin/main\n")
+		ctx := domain.CommandContext{Context: context.Background(), Shell: env.Shell}
+		if got := completeBranches(ctx, tt.prefix); !slices.Equal(got, tt.want) {
+			t.Errorf("completeBranches(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+
+	env := testkit.New(t)
+	env.Shell.Expect("git", "branch", "--all", "--format=%(refname:short)").ExitCode(128)
+	ctx := domain.CommandContext{Context: context.Background(), Shell: env.Shell}
+	if got := completeBranches(ctx, ""); got != nil {
+		t.Errorf("completeBranches outside a repo = %q, want none", got)
+	}
+}
+
+func TestBranch(t *testing.T) {
+	env := testkit.New(t)
+	env.Shell.Expect("git", "branch", "-a").Stdout("  dev\n* main\n  remotes/origin/HEAD -> origin/main\n")
+
+	out := env.Run(branchCmd, nil, map[string]string{"all": "true"}).OK()
+	items := out.Result.Value().Items
+	if len(items) != 3 {
+		t.Fatalf("got %d branches, want 3", len(items))
+	}
+	want := []struct {
+		name            string
+		current, remote bool
+	}{
+		{"dev", false, false},
+		{"main", true, false},
+		{"remotes/origin/HEAD", false, true},
+	}
+	for i, w := range want {
+		if items[i].Get("name") != w.name || items[i].Get("current") != w.current || items[i].Get("remote") != w.remote {
+			t.Errorf("branch %d = %v, want %+v", i, items[i], w)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	env := testkit.New(t)
+	env.Shell.Expect("git", "clone", "--progress", "https://example.test/repo.git", "work").
+		Stderr("Receiving objects:  50%\rReceiving objects: 100%\n")
+
+	out := env.Run(cloneCmd, []string{"https://example.test/repo.git", "work"}, nil).OK().Contains("Cloned https://example.test/repo.git")
+	var progress int
+	for _, l := range out.Output {
+		if l.Stream == domain.Stderr && l.Progress {
+			progress++
+		}
+	}
+	if progress != 1 {
+		t.Errorf("got %d progress lines, want 1: %+v", progress, out.Output)
+	}
+}
@@ -0,0 +1,270 @@
+package history
+
+import (
+	avrohistory "avro_cli/internal/app/history"
+	"avro_cli/internal/app/registry"
+	"avro_cli/internal/domain"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var idArg = domain.ArgDef{Name: "id", Description: "History entry ID (see 'avro history list')", Required: true, Type: domain.ArgInt, Complete: completeIDs}
+
+var listCmd = domain.CommandDescriptor{
+	Category:    category,
+	Name:        "list",
+	Aliases:     []string{"ls"},
+	Description: "List recent executions, newest first",
+	NoHistory:   true,
+	Flags: []domain.ArgDef{
+		{Name: "limit", Short: "n", Description: "Number of entries to show (0 for all)", Default: "20", Type: domain.ArgInt},
+		{Name: "command", Short: "c", Description: "Only show this command (e.g. \"git log\")"},
+		{Name: "failed", Description: "Only show failed executions", Type: domain.ArgBool},
+	},
+	Data: func(ctx domain.CommandContext) domain.Result[domain.Records] {
+		entries, err := list(ctx)
+		if err != nil {
+			return domain.Fail[domain.Records](err)
+		}
+		limit, filter := ctx.Int("limit"), ctx.String("command")
+		if limit < 0 {
+			return domain.Fail[domain.Records](&domain.ValidationError{Field: "limit", Message: "must not be negative"})
+		}
+
+		var items []domain.Record
+		for _, e := range entries {
+			if (filter != "" && e.Command != filter) || (ctx.Bool("failed") && e.Success) {
+				continue
+			}
+			if limit > 0 && len(items) == limit {
+				break
+			}
+			items = append(items, domain.Record{
+				{Name: "id", Value: e.ID},
+				{Name: "time", Value: e.Time.Format(time.RFC3339)},
+				{Name: "command", Value: e.Command},
+				{Name: "invocation", Value: e.CommandLine()},
+				{Name: "duration", Value: e.Duration.Round(time.Millisecond).String()},
+				{Name: "success", Value: e.Success},
+			})
+		}
+		return domain.Ok(domain.Records{
+			Items: items,
+			Human: func(r domain.Records) string {
+				if len(r.Items) == 0 {
+					return "No history yet"
+				}
+				lines := make([]string, len(r.Items))
+				for i, item := range r.Items {
+					t, _ := time.Parse(time.RFC3339, item.Get("time").(string))
+					lines[i] = fmt.Sprintf("#%-5d %s  %s %8s  %s",
+						item.Get("id"), t.Local().Format("2006-01-02 15:04"), mark(item.Get("success").(bool)),
+						item.Get("duration"), item.Get("invocation"))
+				}
+				return strings.Join(lines, "\n")
+			},
+		})
+	},
+}
+
+var showCmd = domain.CommandDescriptor{
+	Category:    category,
+	Name:        "show",
+	Description: "Show an execution with its recorded output",
+	Args:        []domain.ArgDef{idArg},
+	NoHistory:   true,
+	Data: func(ctx domain.CommandContext) domain.Result[domain.Records] {
+		e, err := get(ctx, ctx.Int("id"))
+		if err != nil {
+			return domain.Fail[domain.Records](err)
+		}
+		return domain.Ok(domain.Records{
+			Items: []domain.Record{{
+				{Name: "id", Value: e.ID},
+				{Name: "time", Value: e.Time.Format(time.RFC3339)},
+				{Name: "command", Value: e.Command},
+				{Name: "args", Value: e.Args},
+				{Name: "flags", Value: e.Flags},
+				{Name: "duration", Value: e.Duration.String()},
+				{Name: "success", Value: e.Success},
+				{Name: "error", Value: e.Error},
+				{Name: "output", Value: e.Output},
+				{Name: "truncated", Value: e.Truncated},
+			}},
+			Single: true,
+			Human: func(domain.Records) string {
+				var b strings.Builder
+				fmt.Fprintf(&b, "#%d  %s\n", e.ID, e.CommandLine())
+				fmt.Fprintf(&b, "Ran:      %s\n", e.Time.Local().Format("2006-01-02 15:04:05"))
+				fmt.Fprintf(&b, "Duration: %s\n", e.Duration.Round(time.Millisecond))
+				if e.Success {
+					b.WriteString("Result:   ok\n")
+				} else {
+					fmt.Fprintf(&b, "Result:   failed: %s\n", e.Error)
+				}
+				if e.Output != "" {
+					b.WriteString("\n" + e.Output)
+					if e.Truncated {
+						b.WriteString("\n… (output truncated)")
+					}
+				}
+				return strings.TrimRight(b.String(), "\n")
+			},
+		})
+	},
+}
+
+var rerunCmd = domain.CommandDescriptor{
+	Category:    category,
+	Name:        "rerun",
+	Description: "Run a past execution again with the same args and flags",
+	Args:        []domain.ArgDef{idArg},
+	NoHistory:   true,
+	Data: func(ctx domain.CommandContext) domain.Result[domain.Records] {
+		e, err := get(ctx, ctx.Int("id"))
+		if err != nil {
+			return domain.Fail[domain.Records](err)
+		}
+		if e.Redacted {
+			return domain.Fail[domain.Records](fmt.Errorf("#%d was recorded with secrets redacted; run it again by hand: %s", e.ID, e.CommandLine()))
+		}
+		cmd, ok := registry.Global().Find(e.Category(), e.Name())
+		if !ok {
+			return domain.Fail[domain.Records](fmt.Errorf("command %q is no longer available", e.Command))
+		}
+		// The re-run is recorded as a new entry of its own.
+		return ctx.Run(cmd, e.Args, e.Flags)
+	},
+}
+
+var clearCmd = domain.CommandDescriptor{
+	Category:    category,
+	Name:        "clear",
+	Description: "Delete all recorded executions",
+	NoHistory:   true,
+	Action: func(ctx domain.CommandContext) domain.Result[string] {
+		store, err := avrohistory.FromContext(ctx.Context)
+		if err != nil {
+			return domain.Fail[string](err)
+		}
+		if err := store.Clear(); err != nil {
+			return domain.Fail[string](err)
+		}
+		return domain.Ok("History cleared")
+	},
+}
+
+var statsCmd = domain.CommandDescriptor{
+	Category:    category,
+	Name:        "stats",
+	Description: "Summarize runs, failures and durations per command",
+	NoHistory:   true,
+	Data: func(ctx domain.CommandContext) domain.Result[domain.Records] {
+		entries, err := list(ctx)
+		if err != nil {
+			return domain.Fail[domain.Records](err)
+		}
+
+		type stat struct {
+			command  string
+			runs     int
+			failures int
+			total    time.Duration
+			last     time.Time
+		}
+		byCommand := make(map[string]*stat)
+		for _, e := range entries {
+			s, ok := byCommand[e.Command]
+			if !ok {
+				s = &stat{command: e.Command}
+				byCommand[e.Command] = s
+			}
+			s.runs++
+			s.total += e.Duration
+			if !e.Success {
+				s.failures++
+			}
+			if e.Time.After(s.last) {
+				s.last = e.Time
+			}
+		}
+		stats := make([]*stat, 0, len(byCommand))
+		for _, s := range byCommand {
+			stats = append(stats, s)
+		}
+		sort.Slice(stats, func(i, j int) bool {
+			if stats[i].runs != stats[j].runs {
+				return stats[i].runs > stats[j].runs
+			}
+			return stats[i].command < stats[j].command
+		})
+
+		items := make([]domain.Record, len(stats))
+		for i, s := range stats {
+			items[i] = domain.Record{
+				{Name: "command", Value: s.command},
+				{Name: "runs", Value: s.runs},
+				{Name: "failures", Value: s.failures},
+				{Name: "avg_duration", Value: (s.total / time.Duration(s.runs)).Round(time.Millisecond).String()},
+				{Name: "last_run", Value: s.last.Format(time.RFC3339)},
+			}
+		}
+		return domain.Ok(domain.Records{
+			Items: items,
+			Human: func(r domain.Records) string {
+				if len(r.Items) == 0 {
+					return "No history yet"
+				}
+				lines := []string{fmt.Sprintf("%-24s %5s %8s %10s", "COMMAND", "RUNS", "FAILED", "AVG")}
+				for _, item := range r.Items {
+					lines = append(lines, fmt.Sprintf("%-24s %5d %8d %10s",
+						item.Get("command"), item.Get("runs"), item.Get("failures"), item.Get("avg_duration")))
+				}
+				return strings.Join(lines, "\n")
+			},
+		})
+	},
+}
+
+// list returns the entries of the store the executor records to, newest first.
+func list(ctx domain.CommandContext) ([]avrohistory.Entry, error) {
+	store, err := avrohistory.FromContext(ctx.Context)
+	if err != nil {
+		return nil, err
+	}
+	return store.List()
+}
+
+func get(ctx domain.CommandContext, id int) (avrohistory.Entry, error) {
+	store, err := avrohistory.FromContext(ctx.Context)
+	if err != nil {
+		return avrohistory.Entry{}, err
+	}
+	return store.Get(id)
+}
+
+func mark(success bool) string {
+	if success {
+		return "✓"
+	}
+	return "✗"
+}
+
+// completeIDs suggests recent entry IDs, described by their invocation.
+func completeIDs(ctx domain.CommandContext, toComplete string) []string {
+	entries, _ := list(ctx)
+	var out []string
+	for _, e := range entries {
+		id := strconv.Itoa(e.ID)
+		if strings.HasPrefix(id, toComplete) {
+			out = append(out, id+"\t"+e.CommandLine())
+		}
+		if len(out) == 20 {
+			break
+		}
+	}
+	return out
+}
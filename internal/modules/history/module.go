@@ -0,0 +1,16 @@
+package history
+
+import (
+	"avro_cli/internal/app/registry"
+	"avro_cli/internal/domain"
+)
+
+var category = domain.Category{
+	Name:        "history",
+	Description: "Browse and re-run past command executions",
+	Icon:        "\U0001F552",
+}
+
+func init() {
+	registry.Global().Register(listCmd, showCmd, rerunCmd, clearCmd, statsCmd)
+}
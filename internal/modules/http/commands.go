@@ -2,80 +2,226 @@ package http
 
 import (
 	"avro_cli/internal/domain"
-	"context"
 	"fmt"
+	"io"
+	nethttp "net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
-var getCmd = domain.CommandDescriptor{
-	Category:    category,
-	Name:        "get",
-	Description: "Perform an HTTP GET request",
-	Args: []domain.ArgDef{
-		{Name: "url", Description: "Request URL", Required: true},
-	},
-	Flags: []domain.ArgDef{
-		{Name: "header", Short: "H", Description: "Header in key:value format"},
-	},
-	Action: func(ctx domain.CommandContext) domain.Result[string] {
-		url := ctx.Args["url"]
-		if url == "" {
-			return domain.Fail[string](&domain.ValidationError{Field: "url", Message: "URL is required"})
-		}
+// requestFlags are shared by every request command.
+var requestFlags = []domain.ArgDef{
+	{Name: "header", Short: "H", Description: "Header in key:value format", Repeatable: true},
+	{Name: "query", Short: "q", Description: "Query parameter in key=value format", Repeatable: true},
+	{Name: "header-file", Description: "File of key:value headers, one per line; -H overrides it"},
+	{Name: "include", Short: "i", Description: "Show response headers", Type: domain.ArgBool},
+	{Name: "timing", Description: "Show the timing breakdown and TLS details", Type: domain.ArgBool},
+}
+
+var (
+	getCmd     = requestCmd(nethttp.MethodGet, "Perform an HTTP GET request", false, "fetch", "download", "api", "curl")
+	postCmd    = requestCmd(nethttp.MethodPost, "Perform an HTTP POST request", true, "send", "upload", "api", "curl")
+	putCmd     = requestCmd(nethttp.MethodPut, "Perform an HTTP PUT request", true, "replace", "upload", "api", "curl")
+	patchCmd   = requestCmd(nethttp.MethodPatch, "Perform an HTTP PATCH request", true, "update", "api", "curl")
+	deleteCmd  = requestCmd(nethttp.MethodDelete, "Perform an HTTP DELETE request", false, "remove", "api", "curl")
+	headCmd    = requestCmd(nethttp.MethodHead, "Perform an HTTP HEAD request and show the headers", false, "headers", "api", "curl")
+	optionsCmd = requestCmd(nethttp.MethodOptions, "Perform an HTTP OPTIONS request", false, "cors", "allow", "api", "curl")
+)
+
+// requestCmd builds the command sending method requests. withBody adds an
+// optional body arg after the URL.
+func requestCmd(method, description string, withBody bool, tags ...string) domain.CommandDescriptor {
+	args := []domain.ArgDef{
+		{Name: "url", Description: "Request URL", Required: true, Complete: completeURLs},
+	}
+	if withBody {
+		args = append(args, domain.ArgDef{Name: "body", Description: "Request body"})
+	}
+	return domain.CommandDescriptor{
+		Category:    category,
+		Name:        strings.ToLower(method),
+		Description: description,
+		Tags:        tags,
+		Args:        args,
+		Flags:       requestFlags,
+		Timeout:     30 * time.Second,
+		Data: func(ctx domain.CommandContext) domain.Result[domain.Records] {
+			req, err := newRequest(ctx, method)
+			if err != nil {
+				return domain.Fail[domain.Records](err)
+			}
+			resp, err := ctx.HTTP.Do(ctx.Context, req)
+			if err != nil {
+				return domain.Fail[domain.Records](err)
+			}
+			rememberURL(ctx.FS, req.URL)
+
+			// HEAD and OPTIONS answer in their headers.
+			showHeaders := ctx.Bool("include") || method == nethttp.MethodHead || method == nethttp.MethodOptions
+			return domain.Ok(responseRecords(req, resp, showHeaders, ctx.Bool("timing")))
+		},
+		// Responses can carry tokens and personal data.
+		NoHistoryOutput: true,
+	}
+}
+
+// newRequest builds the request from the url and body args and the
+// --query, --header-file and -H flags.
+func newRequest(ctx domain.CommandContext, method string) (domain.HTTPRequest, error) {
+	url := ctx.String("url")
+	if url == "" {
+		return domain.HTTPRequest{}, &domain.ValidationError{Field: "url", Message: "URL is required"}
+	}
+	query, err := parseQuery(ctx.Strings("query"))
+	if err != nil {
+		return domain.HTTPRequest{}, err
+	}
 
-		headers := parseHeaders(ctx.Flags["header"])
-		status, body, err := ctx.HTTP.Get(context.Background(), url, headers)
+	var lines []string
+	if path := ctx.String("header-file"); path != "" {
+		data, err := ctx.FS.ReadFile(path)
 		if err != nil {
-			return domain.Fail[string](err)
+			return domain.HTTPRequest{}, fmt.Errorf("header file: %w", err)
 		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				lines = append(lines, line)
+			}
+		}
+	}
+	header, err := parseHeaders(lines, ctx.Strings("header"))
+	if err != nil {
+		return domain.HTTPRequest{}, err
+	}
 
-		return domain.Ok(fmt.Sprintf("HTTP %d\n\n%s", status, body))
-	},
+	var body io.Reader
+	if ctx.Has("body") {
+		body = strings.NewReader(ctx.String("body"))
+	}
+	return domain.HTTPRequest{Method: method, URL: url, Header: header, Query: query, Body: body}, nil
 }
 
-var postCmd = domain.CommandDescriptor{
-	Category:    category,
-	Name:        "post",
-	Description: "Perform an HTTP POST request",
-	Args: []domain.ArgDef{
-		{Name: "url", Description: "Request URL", Required: true},
-		{Name: "body", Description: "Request body", Required: false},
-	},
-	Flags: []domain.ArgDef{
-		{Name: "header", Short: "H", Description: "Header in key:value format"},
-	},
-	Action: func(ctx domain.CommandContext) domain.Result[string] {
-		url := ctx.Args["url"]
-		if url == "" {
-			return domain.Fail[string](&domain.ValidationError{Field: "url", Message: "URL is required"})
-		}
+// parseHeaders parses key:value lines from the header file and the -H
+// flags. A key may repeat to send several values; the -H values for a key
+// replace those from the file.
+func parseHeaders(file, flags []string) (nethttp.Header, error) {
+	header, err := headerLines("header-file", file)
+	if err != nil {
+		return nil, err
+	}
+	override, err := headerLines("header", flags)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range override {
+		header[key] = values
+	}
+	return header, nil
+}
 
-		body := ctx.Args["body"]
-		headers := parseHeaders(ctx.Flags["header"])
-		status, respBody, err := ctx.HTTP.Post(context.Background(), url, body, headers)
-		if err != nil {
-			return domain.Fail[string](err)
+// headerLines parses key:value lines given through the field named field.
+func headerLines(field string, raw []string) (nethttp.Header, error) {
+	header := make(nethttp.Header)
+	for _, h := range raw {
+		key, val, ok := strings.Cut(h, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, &domain.ValidationError{Field: field, Message: fmt.Sprintf("%q is not in key:value format", h)}
 		}
+		header.Add(key, strings.TrimSpace(val))
+	}
+	return header, nil
+}
 
-		return domain.Ok(fmt.Sprintf("HTTP %d\n\n%s", status, respBody))
-	},
+// parseQuery parses key=value params; a key may repeat.
+func parseQuery(raw []string) (neturl.Values, error) {
+	query := make(neturl.Values)
+	for _, p := range raw {
+		key, val, ok := strings.Cut(p, "=")
+		if !ok || key == "" {
+			return nil, &domain.ValidationError{Field: "query", Message: fmt.Sprintf("%q is not in key=value format", p)}
+		}
+		query.Add(key, val)
+	}
+	return query, nil
 }
 
-func parseHeaders(raw string) map[string]string {
-	headers := make(map[string]string)
-	if raw == "" {
-		return headers
-	}
-	// Simple k:v parsing for a single header
-	for i := 0; i < len(raw); i++ {
-		if raw[i] == ':' {
-			key := raw[:i]
-			val := raw[i+1:]
-			if len(val) > 0 && val[0] == ' ' {
-				val = val[1:]
+// responseRecords describes resp as a single record. A body that is not
+// UTF-8 text is kept as bytes and summarized in the text form.
+func responseRecords(req domain.HTTPRequest, resp domain.HTTPResponse, showHeaders, showTiming bool) domain.Records {
+	var body any = string(resp.Body)
+	if !utf8.Valid(resp.Body) {
+		body = resp.Body
+	}
+	t := resp.Timing
+	record := domain.Record{
+		{Name: "method", Value: req.Method},
+		{Name: "url", Value: req.URL},
+		{Name: "status", Value: resp.Status},
+		{Name: "headers", Value: resp.Header},
+		{Name: "body", Value: body},
+		{Name: "size", Value: len(resp.Body)},
+		{Name: "duration", Value: t.Total.Round(time.Millisecond).String()},
+		{Name: "timing", Value: map[string]string{
+			"dns":        t.DNS.String(),
+			"connect":    t.Connect.String(),
+			"tls":        t.TLS.String(),
+			"first_byte": t.FirstByte.String(),
+			"total":      t.Total.String(),
+		}},
+	}
+	if tls := resp.TLS; tls != nil {
+		record = append(record, domain.Field{Name: "tls", Value: map[string]string{
+			"version":      tls.Version,
+			"cipher_suite": tls.CipherSuite,
+			"server_name":  tls.ServerName,
+			"subject":      tls.Subject,
+			"issuer":       tls.Issuer,
+			"not_after":    tls.NotAfter.Format(time.RFC3339),
+		}})
+	}
+
+	return domain.Records{
+		Items:  []domain.Record{record},
+		Single: true,
+		Human: func(domain.Records) string {
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s %s  (%s)\n", resp.Proto, resp.StatusText, t.Total.Round(time.Millisecond))
+			if showHeaders {
+				keys := make([]string, 0, len(resp.Header))
+				for k := range resp.Header {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					for _, v := range resp.Header[k] {
+						fmt.Fprintf(&b, "%s: %s\n", k, v)
+					}
+				}
 			}
-			headers[key] = val
-			break
-		}
+			if showTiming {
+				fmt.Fprintf(&b, "\nDNS %s | connect %s | TLS %s | first byte %s | total %s\n",
+					round(t.DNS), round(t.Connect), round(t.TLS), round(t.FirstByte), round(t.Total))
+				if tls := resp.TLS; tls != nil {
+					fmt.Fprintf(&b, "%s %s, server %s\n", tls.Version, tls.CipherSuite, tls.ServerName)
+					fmt.Fprintf(&b, "Certificate %s, issued by %s, expires %s\n", tls.Subject, tls.Issuer, tls.NotAfter.Format("2006-01-02"))
+				}
+			}
+			switch {
+			case len(resp.Body) == 0:
+			case !utf8.Valid(resp.Body):
+				fmt.Fprintf(&b, "\n<%d bytes of binary data>", len(resp.Body))
+			default:
+				b.WriteString("\n" + string(resp.Body))
+			}
+			return strings.TrimRight(b.String(), "\n")
+		},
 	}
-	return headers
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(100 * time.Microsecond)
 }
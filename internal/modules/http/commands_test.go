@@ -0,0 +1,120 @@
+package http
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/testkit"
+	"errors"
+	nethttp "net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHeaders(t *testing.T) {
+	file := []string{"Accept: text/plain", "X-Trace: a", "X-Trace: b", "X-Env: file"}
+	flags := []string{"Accept:application/json", "X-Extra: 1", "x-extra: 2"}
+	got, err := parseHeaders(file, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := nethttp.Header{
+		"Accept":  {"application/json"},
+		"X-Trace": {"a", "b"},
+		"X-Env":   {"file"},
+		"X-Extra": {"1", "2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseHeaders = %v, want %v", got, want)
+	}
+
+	for _, tt := range []struct {
+		file, flags []string
+		field       string
+	}{
+		{nil, []string{"no colon"}, "header"},
+		{nil, []string{": empty key"}, "header"},
+		{[]string{"bad"}, nil, "header-file"},
+	} {
+		var verr *domain.ValidationError
+		if _, err := parseHeaders(tt.file, tt.flags); !errors.As(err, &verr) || verr.Field != tt.field {
+			t.Errorf("parseHeaders(%q, %q) = %v, want a ValidationError for %s", tt.file, tt.flags, err, tt.field)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	got, err := parseQuery([]string{"q=a b", "tag=x", "tag=y", "empty="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("q") != "a b" || !reflect.DeepEqual(got["tag"], []string{"x", "y"}) || !got.Has("empty") {
+		t.Errorf("parseQuery = %v", got)
+	}
+	for _, raw := range []string{"novalue", "=x"} {
+		if _, err := parseQuery([]string{raw}); err == nil {
+			t.Errorf("parseQuery(%q) succeeded", raw)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	env := testkit.New(t)
+	env.HTTP.On("GET", "https://api.test/items?page=2&tag=a&tag=b").
+		Respond(200, `{"items":[]}`).Header("Content-Type", "application/json")
+
+	flags := map[string]string{
+		"query":   domain.JoinValues([]string{"page=2", "tag=a", "tag=b"}),
+		"header":  domain.JoinValues([]string{"Accept: application/json"}),
+		"include": "true",
+	}
+	env.Run(getCmd, []string{"https://api.test/items"}, flags).OK().
+		Field("status", 200).
+		Field("body", `{"items":[]}`).
+		Contains("Content-Type: application/json")
+
+	req := env.HTTP.Requests()[0]
+	if req.Header.Get("Accept") != "application/json" {
+		t.Errorf("request headers = %v", req.Header)
+	}
+
+	// The URL is remembered for completion, without its query.
+	if got := loadURLs(env.FS); !reflect.DeepEqual(got, []string{"https://api.test/items"}) {
+		t.Errorf("remembered URLs = %q", got)
+	}
+}
+
+func TestPostWithHeaderFile(t *testing.T) {
+	env := testkit.New(t)
+	env.FS.WriteFile("/headers.txt", []byte("# defaults\nContent-Type: text/plain\nX-Team: core\n"), 0o600)
+	env.HTTP.On("POST", "https://api.test/notes").Respond(201, "")
+
+	flags := map[string]string{
+		"header-file": "/headers.txt",
+		"header":      domain.JoinValues([]string{"Content-Type: application/json"}),
+	}
+	env.Run(postCmd, []string{"https://api.test/notes", `{"text":"hi"}`}, flags).OK().Field("status", 201)
+
+	req := env.HTTP.Requests()[0]
+	if req.Body != `{"text":"hi"}` || req.Header.Get("Content-Type") != "application/json" || req.Header.Get("X-Team") != "core" {
+		t.Errorf("request = %+v", req)
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	env := testkit.New(t)
+	env.HTTP.On("GET", "https://down.test/").Fails(errors.New("connection refused"))
+
+	env.Run(getCmd, []string{"https://down.test/"}, nil).Fails("connection refused")
+	env.Run(getCmd, []string{"https://down.test/"}, map[string]string{"header-file": "/missing"}).Fails("header file")
+	env.Run(getCmd, []string{"https://down.test/"}, map[string]string{"query": "broken"}).Fails("key=value")
+}
+
+func TestBinaryBody(t *testing.T) {
+	env := testkit.New(t)
+	env.HTTP.On("GET", "https://api.test/logo").Respond(200, "\x89PNG\x00\xff")
+
+	text := env.Run(getCmd, []string{"https://api.test/logo"}, nil).OK().Text()
+	if !strings.Contains(text, "<6 bytes of binary data>") {
+		t.Errorf("text = %q", text)
+	}
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"avro_cli/internal/config"
+	"avro_cli/internal/domain"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// maxURLHistory caps how many requested URLs are kept for completion.
+const maxURLHistory = 100
+
+func urlHistoryPath() string {
+	return filepath.Join(config.Dir(), "http_history")
+}
+
+// loadURLs returns previously requested URLs, most recent first.
+func loadURLs(fs domain.FileSystem) []string {
+	data, err := fs.ReadFile(urlHistoryPath())
+	if err != nil {
+		return nil
+	}
+	var urls []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			urls = append(urls, line)
+		}
+	}
+	return urls
+}
+
+// rememberURL moves raw to the front of the history. Credentials, the query
+// and the fragment are stripped first, as they are where tokens travel.
+// Failures are ignored: history only feeds completion and must never fail a
+// request.
+func rememberURL(fs domain.FileSystem, raw string) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return
+	}
+	u.User, u.RawQuery, u.ForceQuery, u.Fragment, u.RawFragment = nil, "", false, "", ""
+	clean := u.String()
+
+	if err := fs.MkdirAll(config.Dir(), 0o700); err != nil {
+		return
+	}
+	urls := []string{clean}
+	for _, u := range loadURLs(fs) {
+		if u != clean && len(urls) < maxURLHistory {
+			urls = append(urls, u)
+		}
+	}
+	_ = fs.WriteFileAtomic(urlHistoryPath(), []byte(strings.Join(urls, "\n")+"\n"), 0o600)
+}
+
+// completeURLs suggests previously requested URLs.
+func completeURLs(ctx domain.CommandContext, toComplete string) []string {
+	var out []string
+	for _, u := range loadURLs(ctx.FS) {
+		if strings.HasPrefix(u, toComplete) {
+			out = append(out, u)
+		}
+	}
+	return out
+}
@@ -12,5 +12,5 @@ var category = domain.Category{
 }
 
 func init() {
-	registry.Global().Register(getCmd, postCmd)
+	registry.Global().Register(getCmd, postCmd, putCmd, patchCmd, deleteCmd, headCmd, optionsCmd)
 }
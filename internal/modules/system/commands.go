@@ -3,27 +3,43 @@ package system
 import (
 	"avro_cli/internal/cli"
 	"avro_cli/internal/domain"
-	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"runtime"
+	"sort"
 	"strings"
+	"time"
 )
 
 var infoCmd = domain.CommandDescriptor{
 	Category:    category,
 	Name:        "info",
 	Description: "Show system information (OS, arch, Go version)",
-	Action: func(ctx domain.CommandContext) domain.Result[string] {
+	Tags:        []string{"os", "platform", "version"},
+	Data: func(ctx domain.CommandContext) domain.Result[domain.Records] {
 		hostname, _ := os.Hostname()
 		home, _ := os.UserHomeDir()
-		info := fmt.Sprintf(
-			"OS:       %s\nArch:     %s\nCPUs:     %d\nGo:       %s\nHostname: %s\nHome:     %s",
-			runtime.GOOS, runtime.GOARCH, runtime.NumCPU(),
-			runtime.Version(), hostname, home,
-		)
-		return domain.Ok(info)
+		return domain.Ok(domain.Records{
+			Items: []domain.Record{{
+				{Name: "os", Value: runtime.GOOS},
+				{Name: "arch", Value: runtime.GOARCH},
+				{Name: "cpus", Value: runtime.NumCPU()},
+				{Name: "go", Value: runtime.Version()},
+				{Name: "hostname", Value: hostname},
+				{Name: "home", Value: home},
+			}},
+			Single: true,
+			Human: func(r domain.Records) string {
+				info := r.Items[0]
+				return fmt.Sprintf(
+					"OS:       %s\nArch:     %s\nCPUs:     %d\nGo:       %s\nHostname: %s\nHome:     %s",
+					info.Get("os"), info.Get("arch"), info.Get("cpus"),
+					info.Get("go"), info.Get("hostname"), info.Get("home"),
+				)
+			},
+		})
 	},
 }
 
@@ -31,40 +47,57 @@ var envCmd = domain.CommandDescriptor{
 	Category:    category,
 	Name:        "env",
 	Description: "List environment variables (optionally filtered by prefix)",
+	Tags:        []string{"variables", "vars"},
 	Args: []domain.ArgDef{
-		{Name: "filter", Description: "Filter prefix (optional)", Required: false},
+		{Name: "filter", Description: "Filter prefix (optional)", Required: false, Complete: completeEnvNames},
 	},
-	Action: func(ctx domain.CommandContext) domain.Result[string] {
-		filter := ctx.Args["filter"]
-		var lines []string
+	Data: func(ctx domain.CommandContext) domain.Result[domain.Records] {
+		filter := ctx.String("filter")
+		var items []domain.Record
 		for _, e := range os.Environ() {
 			if filter == "" || strings.HasPrefix(strings.ToUpper(e), strings.ToUpper(filter)) {
-				lines = append(lines, e)
+				name, value, _ := strings.Cut(e, "=")
+				items = append(items, domain.Record{
+					{Name: "name", Value: name},
+					{Name: "value", Value: value},
+				})
 			}
 		}
-		if len(lines) == 0 {
-			return domain.Ok("No matching environment variables found")
-		}
-		return domain.Ok(strings.Join(lines, "\n"))
+		return domain.Ok(domain.Records{
+			Items: items,
+			Human: func(r domain.Records) string {
+				if len(r.Items) == 0 {
+					return "No matching environment variables found"
+				}
+				lines := make([]string, len(r.Items))
+				for i, item := range r.Items {
+					lines[i] = fmt.Sprintf("%s=%s", item.Get("name"), item.Get("value"))
+				}
+				return strings.Join(lines, "\n")
+			},
+		})
 	},
+	// Variables often hold API keys and passwords.
+	NoHistoryOutput: true,
 }
 
 var updateCmd = domain.CommandDescriptor{
 	Category:    category,
 	Name:        "update",
 	Description: "Check for CLI updates from GitHub releases",
+	Tags:        []string{"upgrade", "version", "release"},
+	Timeout:     15 * time.Second,
 	Action: func(ctx domain.CommandContext) domain.Result[string] {
 		const url = "https://api.github.com/repos/606/avro_cli/releases/latest"
-		headers := map[string]string{
-			"Accept": "application/vnd.github+json",
-		}
-
-		status, body, err := ctx.HTTP.Get(context.Background(), url, headers)
+		resp, err := ctx.HTTP.Do(ctx.Context, domain.HTTPRequest{
+			URL:    url,
+			Header: http.Header{"Accept": {"application/vnd.github+json"}},
+		})
 		if err != nil {
 			return domain.Fail[string](fmt.Errorf("failed to check updates: %w", err))
 		}
-		if status != 200 {
-			return domain.Fail[string](fmt.Errorf("GitHub API returned HTTP %d", status))
+		if resp.Status != http.StatusOK {
+			return domain.Fail[string](fmt.Errorf("GitHub API returned HTTP %d", resp.Status))
 		}
 
 		var release struct {
@@ -72,7 +105,7 @@ var updateCmd = domain.CommandDescriptor{
 			HTMLURL     string `json:"html_url"`
 			PublishedAt string `json:"published_at"`
 		}
-		if err := json.Unmarshal([]byte(body), &release); err != nil {
+		if err := json.Unmarshal(resp.Body, &release); err != nil {
 			return domain.Fail[string](fmt.Errorf("failed to parse release info: %w", err))
 		}
 
@@ -108,3 +141,17 @@ var pathCmd = domain.CommandDescriptor{
 		return domain.Ok(strings.Join(entries, "\n"))
 	},
 }
+
+// completeEnvNames suggests environment variable names, matching the
+// case-insensitive prefix semantics of the env filter.
+func completeEnvNames(ctx domain.CommandContext, toComplete string) []string {
+	var names []string
+	for _, e := range os.Environ() {
+		name, _, _ := strings.Cut(e, "=")
+		if strings.HasPrefix(strings.ToUpper(name), strings.ToUpper(toComplete)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,78 @@
+package system
+
+import (
+	"avro_cli/internal/cli"
+	"avro_cli/internal/domain"
+	"avro_cli/internal/testkit"
+	"errors"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const releaseURL = "https://api.github.com/repos/606/avro_cli/releases/latest"
+
+func TestInfo(t *testing.T) {
+	env := testkit.New(t)
+	env.Run(infoCmd, nil, nil).OK().
+		Field("os", runtime.GOOS).
+		Field("arch", runtime.GOARCH).
+		Contains("Go:       " + runtime.Version())
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("AVRO_TEST_ONE", "1")
+	t.Setenv("AVRO_TEST_TWO", "a=b")
+
+	env := testkit.New(t)
+	out := env.Run(envCmd, []string{"avro_test_"}, nil).OK()
+	if n := len(out.Result.Value().Items); n != 2 {
+		t.Fatalf("got %d variables, want 2", n)
+	}
+	out.Contains("AVRO_TEST_ONE=1").Contains("AVRO_TEST_TWO=a=b")
+
+	env.Run(envCmd, []string{"AVRO_TEST_NONE_"}, nil).OK().Contains("No matching environment variables found")
+
+	if got := completeEnvNames(domain.CommandContext{}, "avro_test_"); !slices.Equal(got, []string{"AVRO_TEST_ONE", "AVRO_TEST_TWO"}) {
+		t.Errorf("completeEnvNames = %q", got)
+	}
+}
+
+func TestPath(t *testing.T) {
+	sep := ":"
+	if runtime.GOOS == "windows" {
+		sep = ";"
+	}
+	t.Setenv("PATH", strings.Join([]string{"/usr/bin", "/bin"}, sep))
+
+	env := testkit.New(t)
+	if got := env.Run(pathCmd, nil, nil).OK().Text(); got != "/usr/bin\n/bin" {
+		t.Errorf("path = %q", got)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	env := testkit.New(t)
+	env.HTTP.On("GET", releaseURL).Respond(200, `{"tag_name":"v`+cli.Version+`","published_at":"2026-01-02"}`)
+	env.Run(updateCmd, nil, nil).OK().Contains("You are up to date")
+
+	env.HTTP.On("GET", releaseURL).Respond(200, `{"tag_name":"v99.0.0","html_url":"https://example.test/r"}`)
+	env.Run(updateCmd, nil, nil).OK().Contains("Update available!\nhttps://example.test/r")
+
+	if h := env.HTTP.Requests()[0].Header.Get("Accept"); h != "application/vnd.github+json" {
+		t.Errorf("Accept = %q", h)
+	}
+}
+
+func TestUpdateErrors(t *testing.T) {
+	env := testkit.New(t)
+	env.HTTP.On("GET", releaseURL).Respond(403, `{"message":"rate limited"}`)
+	env.Run(updateCmd, nil, nil).Fails("HTTP 403")
+
+	env.HTTP.On("GET", releaseURL).Respond(200, `not json`)
+	env.Run(updateCmd, nil, nil).Fails("failed to parse release info")
+
+	env.HTTP.On("GET", releaseURL).Fails(errors.New("no route to host"))
+	env.Run(updateCmd, nil, nil).Fails("failed to check updates: no route to host")
+}
@@ -0,0 +1,35 @@
+package testkit
+
+import (
+	"avro_cli/internal/infra/fs"
+	iofs "io/fs"
+)
+
+// FS is an in-memory domain.FileSystem, the memory backend of package fs
+// with helpers for setting up and inspecting files in tests. Directories
+// exist implicitly wherever a file lies below them.
+type FS struct {
+	*fs.FS
+}
+
+// NewFS returns a file system holding files, keyed by absolute path.
+func NewFS(files map[string]string) *FS {
+	f := &FS{fs.NewMemory()}
+	for p, data := range files {
+		f.WriteFile(p, []byte(data), 0o644)
+	}
+	return f
+}
+
+// Files returns a snapshot of every file, for asserting on what was written.
+func (f *FS) Files() map[string]string {
+	out := make(map[string]string)
+	f.Walk("/", func(p string, info iofs.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			data, _ := f.ReadFile(p)
+			out[p] = string(data)
+		}
+		return nil
+	})
+	return out
+}
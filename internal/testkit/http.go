@@ -0,0 +1,142 @@
+package testkit
+
+import (
+	"avro_cli/internal/domain"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// Request is one request a fake HTTP client received, with the body read
+// and the query merged into URL.
+type Request struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   string
+}
+
+// Route scripts the response to requests for one method and URL. By default
+// it answers 200 with an empty body, any number of times.
+type Route struct {
+	method string
+	url    string
+	resp   domain.HTTPResponse
+	err    error
+}
+
+// Respond sets the status and body of the response.
+func (r *Route) Respond(status int, body string) *Route {
+	r.resp.Status = status
+	r.resp.StatusText = fmt.Sprintf("%d %s", status, http.StatusText(status))
+	r.resp.Body = []byte(body)
+	return r
+}
+
+// Header adds a response header.
+func (r *Route) Header(key, value string) *Route {
+	r.resp.Header.Add(key, value)
+	return r
+}
+
+// Fails makes the request fail with err instead of responding, e.g. to
+// simulate a network error.
+func (r *Route) Fails(err error) *Route {
+	r.err = err
+	return r
+}
+
+// HTTP is a scriptable domain.HTTPClient. Requests are answered by the
+// route registered with On for the same method and URL, ignoring the order
+// of query parameters; any other request fails the test.
+type HTTP struct {
+	t        testing.TB
+	mu       sync.Mutex
+	routes   []*Route
+	requests []Request
+}
+
+// NewHTTP returns a fake HTTP client reporting to t.
+func NewHTTP(t testing.TB) *HTTP {
+	return &HTTP{t: t}
+}
+
+// On registers the route for method requests to rawURL. A later route for
+// the same request replaces an earlier one.
+func (h *HTTP) On(method, rawURL string) *Route {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r := &Route{method: method, url: normalizeURL(rawURL)}
+	r.resp = domain.HTTPResponse{Proto: "HTTP/1.1", Header: make(http.Header)}
+	r.Respond(http.StatusOK, "")
+	h.routes = append(h.routes, r)
+	return r
+}
+
+// Requests returns the requests received so far, in order.
+func (h *HTTP) Requests() []Request {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.requests)
+}
+
+func (h *HTTP) Do(ctx context.Context, req domain.HTTPRequest) (domain.HTTPResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.HTTPResponse{}, err
+	}
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return domain.HTTPResponse{}, err
+	}
+	q := u.Query()
+	for k, vs := range req.Query {
+		q[k] = append(q[k], vs...)
+	}
+	u.RawQuery = q.Encode()
+	got := Request{Method: method, URL: u.String(), Header: req.Header}
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return domain.HTTPResponse{}, err
+		}
+		got.Body = string(data)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = append(h.requests, got)
+	for i := len(h.routes) - 1; i >= 0; i-- {
+		r := h.routes[i]
+		if r.method == got.Method && r.url == got.URL {
+			if r.err != nil {
+				return domain.HTTPResponse{}, r.err
+			}
+			resp := r.resp
+			resp.Header = r.resp.Header.Clone()
+			resp.Body = slices.Clone(r.resp.Body)
+			return resp, nil
+		}
+	}
+	err = fmt.Errorf("testkit: unexpected request %s %s", got.Method, got.URL)
+	h.t.Error(err)
+	return domain.HTTPResponse{}, err
+}
+
+// normalizeURL sorts the query parameters of rawURL.
+func normalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.RawQuery = u.Query().Encode()
+	return u.String()
+}
@@ -0,0 +1,197 @@
+package testkit
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/infra/shell"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Call is one command a fake Shell was asked to run.
+type Call struct {
+	Dir   string
+	Name  string
+	Args  []string
+	Env   []string // RunStream only
+	Stdin string   // RunStream only
+}
+
+func (c Call) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// Expectation scripts the outcome of a command on a fake Shell. By default
+// it matches once, in any directory, and succeeds with no output.
+type Expectation struct {
+	name     string
+	args     []string
+	dir      *string
+	stdout   string
+	stderr   string
+	exitCode int
+	err      error
+	times    int // -1 for any number
+	calls    int
+}
+
+// InDir restricts the expectation to calls made in dir.
+func (e *Expectation) InDir(dir string) *Expectation {
+	e.dir = &dir
+	return e
+}
+
+// Stdout sets what the command prints on stdout.
+func (e *Expectation) Stdout(s string) *Expectation {
+	e.stdout = s
+	return e
+}
+
+// Stderr sets what the command prints on stderr.
+func (e *Expectation) Stderr(s string) *Expectation {
+	e.stderr = s
+	return e
+}
+
+// ExitCode makes the command fail with code, reported like the real runner
+// does: a *shell.ShellError carrying stderr when there is any.
+func (e *Expectation) ExitCode(code int) *Expectation {
+	e.exitCode = code
+	return e
+}
+
+// Fails makes the command fail to start with err, e.g. exec.ErrNotFound.
+func (e *Expectation) Fails(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// Times sets how often the command must run; n < 0 allows any number, including none.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+func (e *Expectation) matches(c Call) bool {
+	return e.name == c.Name && slices.Equal(e.args, c.Args) && (e.dir == nil || *e.dir == c.Dir) &&
+		(e.times < 0 || e.calls < e.times)
+}
+
+// result returns the error the command ends with, mirroring shell.Runner.
+func (e *Expectation) result() error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.exitCode == 0 {
+		return nil
+	}
+	cause := fmt.Errorf("exit status %d", e.exitCode)
+	if e.stderr == "" {
+		return cause
+	}
+	return &shell.ShellError{Command: e.name, Output: strings.TrimSpace(e.stderr), Cause: cause}
+}
+
+// Shell is a scriptable domain.ShellRunner. Every command it runs must have
+// been declared with Expect; anything else fails the test. Expectations that
+// were not met by the end of the test fail it too.
+type Shell struct {
+	t     testing.TB
+	mu    sync.Mutex
+	exp   []*Expectation
+	calls []Call
+}
+
+// NewShell returns a fake shell reporting to t.
+func NewShell(t testing.TB) *Shell {
+	s := &Shell{t: t}
+	t.Cleanup(s.verify)
+	return s
+}
+
+// Expect declares that name will be run with exactly args. When several
+// expectations match a call, the earliest declared one that is not used up wins.
+func (s *Shell) Expect(name string, args ...string) *Expectation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := &Expectation{name: name, args: args, times: 1}
+	s.exp = append(s.exp, e)
+	return e
+}
+
+// Calls returns the commands run so far, in order.
+func (s *Shell) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.calls)
+}
+
+func (s *Shell) Run(ctx context.Context, name string, args ...string) (string, error) {
+	return s.RunDir(ctx, "", name, args...)
+}
+
+func (s *Shell) RunDir(ctx context.Context, dir string, name string, args ...string) (string, error) {
+	e, err := s.call(ctx, Call{Dir: dir, Name: name, Args: args})
+	if err != nil {
+		return "", err
+	}
+	if err := e.result(); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(e.stdout, "\n"), nil
+}
+
+func (s *Shell) RunStream(ctx context.Context, opts domain.StreamOptions, name string, args ...string) error {
+	var stdin string
+	if opts.Stdin != nil {
+		data, err := io.ReadAll(opts.Stdin)
+		if err != nil {
+			return err
+		}
+		stdin = string(data)
+	}
+	e, err := s.call(ctx, Call{Dir: opts.Dir, Name: name, Args: args, Env: opts.Env, Stdin: stdin})
+	if err != nil {
+		return err
+	}
+	if opts.Stdout != nil {
+		io.WriteString(opts.Stdout, e.stdout)
+	}
+	if opts.Stderr != nil {
+		io.WriteString(opts.Stderr, e.stderr)
+	}
+	return e.result()
+}
+
+// call records c and returns the expectation it uses up.
+func (s *Shell) call(ctx context.Context, c Call) (*Expectation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, c)
+	for _, e := range s.exp {
+		if e.matches(c) {
+			e.calls++
+			return e, nil
+		}
+	}
+	err := fmt.Errorf("testkit: unexpected command %q", c)
+	s.t.Error(err)
+	return nil, err
+}
+
+func (s *Shell) verify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.exp {
+		if e.times >= 0 && e.calls < e.times {
+			s.t.Errorf("testkit: command %q ran %d of %d expected times", Call{Name: e.name, Args: e.args}, e.calls, e.times)
+		}
+	}
+}
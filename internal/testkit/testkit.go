@@ -0,0 +1,134 @@
+// Package testkit provides fakes of the infrastructure commands depend on
+// and a harness running a CommandDescriptor through the real executor, so a
+// module can be unit-tested without git, a network or the user's home:
+//
+//	func TestStatus(t *testing.T) {
+//		env := testkit.New(t)
+//		env.Shell.Expect("git", "status", "--short").Stdout(" M main.go\n")
+//
+//		out := env.Run(statusCmd, nil, nil).OK()
+//		out.Contains("main.go")
+//	}
+package testkit
+
+import (
+	"avro_cli/internal/app/executor"
+	"avro_cli/internal/domain"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Env is a set of fakes wired into an Executor.
+type Env struct {
+	t        testing.TB
+	Shell    *Shell
+	FS       *FS
+	HTTP     *HTTP
+	Executor *executor.Executor
+}
+
+// New returns an Env with an empty file system and no expected commands or
+// routes. The executor records no history; set Executor.History to a store
+// on FS, e.g. history.Default(env.FS), to test commands that read it.
+func New(t testing.TB) *Env {
+	t.Helper()
+	e := &Env{t: t, Shell: NewShell(t), FS: NewFS(nil), HTTP: NewHTTP(t)}
+	e.Executor = executor.New(e.Shell, e.FS, e.HTTP)
+	return e
+}
+
+// Run executes cmd with args and flags as the CLI would, collecting its
+// streamed output.
+func (e *Env) Run(cmd domain.CommandDescriptor, args []string, flags map[string]string) *Outcome {
+	e.t.Helper()
+	var buf domain.OutputBuffer
+	result := e.Executor.RunRecords(context.Background(), cmd, args, flags, &buf)
+	return &Outcome{t: e.t, Result: result, Output: buf.Lines()}
+}
+
+// Outcome is the result of Env.Run with assertions on it. Assertions fail
+// the test with Errorf, except OK and Fails, which stop it.
+type Outcome struct {
+	t      testing.TB
+	Result domain.Result[domain.Records]
+	Output []domain.OutputLine
+}
+
+// OK asserts that the command succeeded.
+func (o *Outcome) OK() *Outcome {
+	o.t.Helper()
+	if !o.Result.IsOk() {
+		o.t.Fatalf("command failed: %v", o.Result.Err())
+	}
+	return o
+}
+
+// Fails asserts that the command failed with an error whose message
+// contains substr, and returns the error for further checks.
+func (o *Outcome) Fails(substr string) error {
+	o.t.Helper()
+	if o.Result.IsOk() {
+		o.t.Fatalf("command succeeded, want an error containing %q", substr)
+	}
+	if err := o.Result.Err(); !strings.Contains(err.Error(), substr) {
+		o.t.Fatalf("error %q does not contain %q", err, substr)
+	}
+	return o.Result.Err()
+}
+
+// FailsAs asserts that the command failed with an error matching target,
+// as errors.As does, e.g. a **domain.ValidationError.
+func (o *Outcome) FailsAs(target any) *Outcome {
+	o.t.Helper()
+	if o.Result.IsOk() {
+		o.t.Fatalf("command succeeded, want a %T error", target)
+	}
+	if !errors.As(o.Result.Err(), target) {
+		o.t.Fatalf("error %v (%T) is not a %T", o.Result.Err(), o.Result.Err(), target)
+	}
+	return o
+}
+
+// Text returns the result as the CLI prints it in text mode, or "" on failure.
+func (o *Outcome) Text() string {
+	if !o.Result.IsOk() {
+		return ""
+	}
+	return o.Result.Value().Text()
+}
+
+// Stdout returns the streamed stdout lines joined by newlines.
+func (o *Outcome) Stdout() string {
+	var lines []string
+	for _, l := range o.Output {
+		if l.Stream == domain.Stdout {
+			lines = append(lines, l.Text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Contains asserts that the text result contains substr.
+func (o *Outcome) Contains(substr string) *Outcome {
+	o.t.Helper()
+	if text := o.Text(); !strings.Contains(text, substr) {
+		o.t.Errorf("result does not contain %q:\n%s", substr, text)
+	}
+	return o
+}
+
+// Field asserts that the first record's field name has value want.
+func (o *Outcome) Field(name string, want any) *Outcome {
+	o.t.Helper()
+	if !o.Result.IsOk() || len(o.Result.Value().Items) == 0 {
+		o.t.Errorf("no record to read %q from", name)
+		return o
+	}
+	if got := o.Result.Value().Items[0].Get(name); !reflect.DeepEqual(got, want) {
+		o.t.Errorf("field %q = %#v, want %#v", name, got, want)
+	}
+	return o
+}
@@ -0,0 +1,218 @@
+package testkit_test
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/infra/shell"
+	"avro_cli/internal/testkit"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recorder is a testing.TB that collects failures instead of reporting
+// them, to check that the fakes fail tests when they should.
+type recorder struct {
+	testing.TB
+	mu       sync.Mutex
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Error(args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprint(args...))
+}
+
+func (r *recorder) Errorf(format string, args ...any) { r.Error(fmt.Sprintf(format, args...)) }
+
+func (r *recorder) Cleanup(f func()) { r.cleanups = append(r.cleanups, f) }
+
+// finish runs the cleanups, as the end of a test would, and returns the
+// failures reported.
+func (r *recorder) finish() []string {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+	return r.errors
+}
+
+func TestShellExpectations(t *testing.T) {
+	ctx := context.Background()
+	sh := testkit.NewShell(t)
+	sh.Expect("git", "rev-parse", "HEAD").Stdout("abc123\n")
+	sh.Expect("git", "status").InDir("/repo").Stdout("clean\n").Times(2)
+	sh.Expect("git", "push").Stderr("rejected\n").ExitCode(1)
+	sh.Expect("missing").Fails(exec.ErrNotFound)
+
+	if out, err := sh.Run(ctx, "git", "rev-parse", "HEAD"); err != nil || out != "abc123" {
+		t.Errorf("Run = %q, %v; want abc123", out, err)
+	}
+	for range 2 {
+		if out, err := sh.RunDir(ctx, "/repo", "git", "status"); err != nil || out != "clean" {
+			t.Errorf("RunDir = %q, %v; want clean", out, err)
+		}
+	}
+
+	var serr *shell.ShellError
+	if _, err := sh.Run(ctx, "git", "push"); !errors.As(err, &serr) || serr.Output != "rejected" {
+		t.Errorf("failing command error = %v, want a ShellError with its stderr", err)
+	}
+	if _, err := sh.Run(ctx, "missing"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("error = %v, want exec.ErrNotFound", err)
+	}
+
+	want := []string{"git rev-parse HEAD", "git status", "git status", "git push", "missing"}
+	var got []string
+	for _, c := range sh.Calls() {
+		got = append(got, c.String())
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Calls = %q, want %q", got, want)
+	}
+}
+
+func TestShellRunStream(t *testing.T) {
+	sh := testkit.NewShell(t)
+	sh.Expect("plugin").Stdout("out\n").Stderr("warn\n")
+
+	var stdout, stderr strings.Builder
+	opts := domain.StreamOptions{Dir: "/w", Env: []string{"A=1"}, Stdin: strings.NewReader("input"), Stdout: &stdout, Stderr: &stderr}
+	if err := sh.RunStream(context.Background(), opts, "plugin"); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "out\n" || stderr.String() != "warn\n" {
+		t.Errorf("stdout %q, stderr %q", stdout.String(), stderr.String())
+	}
+	call := sh.Calls()[0]
+	if call.Dir != "/w" || call.Stdin != "input" || !slices.Equal(call.Env, []string{"A=1"}) {
+		t.Errorf("call = %+v", call)
+	}
+}
+
+func TestShellFailsOnUnexpectedAndUnmet(t *testing.T) {
+	r := &recorder{TB: t}
+	sh := testkit.NewShell(r)
+	sh.Expect("git", "status")
+	sh.Expect("git", "fetch").Times(-1)
+
+	if _, err := sh.Run(context.Background(), "git", "push"); err == nil {
+		t.Error("unexpected command succeeded")
+	}
+	errs := r.finish()
+	if len(errs) != 2 || !strings.Contains(errs[0], `unexpected command "git push"`) ||
+		!strings.Contains(errs[1], `"git status" ran 0 of 1`) {
+		t.Errorf("failures = %q", errs)
+	}
+}
+
+func TestShellUsesUpExpectations(t *testing.T) {
+	r := &recorder{TB: t}
+	sh := testkit.NewShell(r)
+	sh.Expect("date").Stdout("first")
+	sh.Expect("date").Stdout("second")
+
+	for _, want := range []string{"first", "second"} {
+		if out, _ := sh.Run(context.Background(), "date"); out != want {
+			t.Errorf("Run = %q, want %q", out, want)
+		}
+	}
+	if _, err := sh.Run(context.Background(), "date"); err == nil {
+		t.Error("third run succeeded")
+	}
+	r.finish()
+}
+
+func TestHTTP(t *testing.T) {
+	h := testkit.NewHTTP(t)
+	h.On("GET", "https://api.test/items?b=2&a=1").Respond(200, `[]`).Header("Content-Type", "application/json")
+	h.On("POST", "https://api.test/items").Respond(201, `{"id":1}`)
+	h.On("GET", "https://down.test/").Fails(errors.New("connection refused"))
+
+	// Query order and parameters passed apart from the URL do not matter.
+	resp, err := h.Do(context.Background(), domain.HTTPRequest{URL: "https://api.test/items?a=1", Query: map[string][]string{"b": {"2"}}})
+	if err != nil || resp.Status != 200 || string(resp.Body) != "[]" || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("GET = %+v, %v", resp, err)
+	}
+	resp, err = h.Do(context.Background(), domain.HTTPRequest{Method: "POST", URL: "https://api.test/items", Body: strings.NewReader("new"), Header: http.Header{"X-Test": {"1"}}})
+	if err != nil || resp.Status != 201 || resp.StatusText != "201 Created" {
+		t.Errorf("POST = %+v, %v", resp, err)
+	}
+	if _, err := h.Do(context.Background(), domain.HTTPRequest{URL: "https://down.test/"}); err == nil {
+		t.Error("failing route succeeded")
+	}
+
+	reqs := h.Requests()
+	if len(reqs) != 3 || reqs[1].Body != "new" || reqs[1].Header.Get("X-Test") != "1" || reqs[0].Method != "GET" {
+		t.Errorf("Requests = %+v", reqs)
+	}
+}
+
+func TestHTTPFailsOnUnexpected(t *testing.T) {
+	r := &recorder{TB: t}
+	h := testkit.NewHTTP(r)
+	if _, err := h.Do(context.Background(), domain.HTTPRequest{URL: "https://nowhere.test/"}); err == nil {
+		t.Error("unrouted request succeeded")
+	}
+	if errs := r.finish(); len(errs) != 1 || !strings.Contains(errs[0], "unexpected request GET https://nowhere.test/") {
+		t.Errorf("failures = %q", errs)
+	}
+}
+
+func TestFS(t *testing.T) {
+	fs := testkit.NewFS(map[string]string{"/a/b.txt": "b"})
+	if err := fs.WriteFile("/a/c/d.txt", []byte("d"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"/a/b.txt": "b", "/a/c/d.txt": "d"}
+	got := fs.Files()
+	if len(got) != len(want) || got["/a/b.txt"] != "b" || got["/a/c/d.txt"] != "d" {
+		t.Errorf("Files = %v, want %v", got, want)
+	}
+}
+
+var greet = domain.CommandDescriptor{
+	Category: domain.Category{Name: "demo"},
+	Name:     "greet",
+	Args:     []domain.ArgDef{{Name: "name", Required: true}},
+	Flags:    []domain.ArgDef{{Name: "times", Type: domain.ArgInt, Default: "1", Min: "1"}},
+	Data: func(ctx domain.CommandContext) domain.Result[domain.Records] {
+		who, err := ctx.Shell.Run(ctx.Context, "whoami")
+		if err != nil {
+			return domain.Fail[domain.Records](err)
+		}
+		for range ctx.Int("times") {
+			ctx.Output.Printf("hello %s", ctx.String("name"))
+		}
+		return domain.Ok(domain.Records{
+			Items:  []domain.Record{{{Name: "by", Value: who}}},
+			Single: true,
+			Human:  func(domain.Records) string { return "greeted by " + who },
+		})
+	},
+}
+
+func TestRun(t *testing.T) {
+	env := testkit.New(t)
+	env.Shell.Expect("whoami").Stdout("alice\n")
+
+	out := env.Run(greet, []string{"bob"}, map[string]string{"times": "2"}).OK().Field("by", "alice").Contains("greeted by alice")
+	if got := out.Stdout(); got != "hello bob\nhello bob" {
+		t.Errorf("Stdout = %q", got)
+	}
+
+	var verr *domain.ValidationError
+	env.Run(greet, []string{"bob"}, map[string]string{"times": "0"}).FailsAs(&verr)
+	if verr.Field != "times" {
+		t.Errorf("validation error on %q, want times", verr.Field)
+	}
+	env.Run(greet, nil, nil).Fails("name")
+}
@@ -3,15 +3,14 @@ package tui
 import (
 	"avro_cli/internal/app/executor"
 	"avro_cli/internal/app/registry"
+	"avro_cli/internal/config"
 	"avro_cli/internal/domain"
-	"avro_cli/internal/infra/fs"
-	"avro_cli/internal/infra/net"
-	"avro_cli/internal/infra/shell"
 	"avro_cli/internal/tui/components"
 	"avro_cli/internal/tui/nav"
 	"avro_cli/internal/tui/screens"
 	"avro_cli/internal/tui/styles"
 	"fmt"
+	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -20,6 +19,7 @@ import (
 type appModel struct {
 	nav    *nav.Navigator
 	exec   *executor.Executor
+	theme  *styles.Theme
 	width  int
 	height int
 
@@ -28,15 +28,15 @@ type appModel struct {
 	category screens.CategoryModel
 	detail   screens.CommandDetailModel
 	search   screens.SearchModel
+	history  screens.HistoryModel
 }
 
-func newAppModel() appModel {
-	exec := executor.New(shell.New(), fs.New(), net.New())
-
+func newAppModel(exec *executor.Executor, theme *styles.Theme) appModel {
 	return appModel{
-		nav:  nav.New(),
-		exec: exec,
-		home: screens.NewHomeModel(),
+		nav:   nav.New(),
+		exec:  exec,
+		theme: theme,
+		home:  screens.NewHomeModel(theme),
 	}
 }
 
@@ -53,6 +53,8 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c":
+			m.detail.Stop()
+			m.search.Stop()
 			return m, tea.Quit
 		case "q":
 			current := m.nav.Current().Screen
@@ -66,9 +68,9 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "/":
 			current := m.nav.Current().Screen
 			if current != nav.SearchScreen && current != nav.CommandDetailScreen {
-				m.search = screens.NewSearchModel()
+				m.search = screens.NewSearchModel(m.exec, m.theme)
 				m.nav.Push(nav.Entry{Screen: nav.SearchScreen, Title: "Search"})
-				return m, nil
+				return m.delegate(tea.WindowSizeMsg{Width: m.width, Height: m.height})
 			}
 		case "esc":
 			current := m.nav.Current().Screen
@@ -76,6 +78,10 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if current == nav.SearchScreen {
 				break
 			}
+			// A running command or output search claims esc for itself
+			if current == nav.CommandDetailScreen && m.detail.HandlesEsc() {
+				break
+			}
 			if m.nav.Pop() {
 				return m, nil
 			}
@@ -86,21 +92,35 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.nav.Push(msg.Entry)
 		switch msg.Entry.Screen {
 		case nav.CategoryScreen:
-			m.category = screens.NewCategoryModel(msg.Entry.Data.(string))
+			m.category = screens.NewCategoryModel(msg.Entry.Data.(string), m.theme)
 		case nav.CommandDetailScreen:
-			cmd := msg.Entry.Data.(domain.CommandDescriptor)
-			m.detail = screens.NewCommandDetailModel(cmd, m.exec)
+			switch data := msg.Entry.Data.(type) {
+			case domain.CommandDescriptor:
+				m.detail = screens.NewCommandDetailModel(data, m.exec, m.theme)
+			case screens.Rerun:
+				m.detail = screens.NewCommandDetailModel(data.Command, m.exec, m.theme).Prefill(data.Args, data.Flags)
+			}
 		case nav.SearchScreen:
-			m.search = screens.NewSearchModel()
+			m.search = screens.NewSearchModel(m.exec, m.theme)
+		case nav.HistoryScreen:
+			m.history = screens.NewHistoryModel(m.exec.History, m.theme)
 		}
-		return m, nil
+		// New screens missed the last resize; replay it so they can lay out.
+		return m.delegate(tea.WindowSizeMsg{Width: m.width, Height: m.height})
 
 	case nav.PopScreenMsg:
+		if m.nav.Current().Screen == nav.CommandDetailScreen {
+			m.detail.Stop()
+		}
 		m.nav.Pop()
 		return m, nil
 	}
 
-	// Delegate to current screen
+	return m.delegate(msg)
+}
+
+// delegate forwards msg to the current screen.
+func (m appModel) delegate(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch m.nav.Current().Screen {
 	case nav.HomeScreen:
@@ -111,6 +131,8 @@ func (m appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.detail, cmd = m.detail.Update(msg)
 	case nav.SearchScreen:
 		m.search, cmd = m.search.Update(msg)
+	case nav.HistoryScreen:
+		m.history, cmd = m.history.Update(msg)
 	}
 
 	return m, cmd
@@ -128,37 +150,67 @@ func (m appModel) View() string {
 		content = m.detail.View()
 	case nav.SearchScreen:
 		content = m.search.View()
+	case nav.HistoryScreen:
+		content = m.history.View()
 	}
 
-	breadcrumb := styles.Breadcrumb.Render(m.nav.Breadcrumb())
-	statusBar := components.StatusBar(
-		fmt.Sprintf("%d commands", len(registry.Global().All())),
-		m.width,
-	)
+	breadcrumb := m.theme.Breadcrumb.Render(m.nav.Breadcrumb())
+	status := fmt.Sprintf("%d commands", len(registry.Global().All()))
+	switch m.nav.Current().Screen {
+	case nav.CommandDetailScreen:
+		if s := m.detail.Status(); s != "" {
+			status = s
+		}
+	case nav.SearchScreen:
+		if s := m.search.Status(); s != "" {
+			status = s
+		}
+	}
+	statusBar := components.StatusBar(m.theme, status, m.width)
 
 	return breadcrumb + "\n" + content + "\n\n" + statusBar
 }
 
 // Run starts the interactive TUI.
-func Run() error {
-	p := tea.NewProgram(newAppModel(), tea.WithAltScreen())
+func Run(exec *executor.Executor, cfg *config.Config) error {
+	p := tea.NewProgram(newAppModel(exec, loadTheme(exec.FS, cfg)), tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
 
 // RunPalette starts the TUI in palette mode (search screen only).
-func RunPalette() error {
-	p := tea.NewProgram(newPaletteModel(), tea.WithAltScreen())
+func RunPalette(exec *executor.Executor, cfg *config.Config) error {
+	p := tea.NewProgram(newPaletteModel(exec, loadTheme(exec.FS, cfg)), tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
 
-func newPaletteModel() appModel {
-	exec := executor.New(shell.New(), fs.New(), net.New())
+// Model returns the root model Run starts, for driving the TUI without a
+// terminal (see package tuitest).
+func Model(exec *executor.Executor, theme *styles.Theme) tea.Model {
+	return newAppModel(exec, theme)
+}
+
+// PaletteModel returns the root model RunPalette starts.
+func PaletteModel(exec *executor.Executor, theme *styles.Theme) tea.Model {
+	return newPaletteModel(exec, theme)
+}
 
+func newPaletteModel(exec *executor.Executor, theme *styles.Theme) appModel {
 	return appModel{
 		nav:    nav.NewWithInitial(nav.SearchScreen, "Palette"),
 		exec:   exec,
-		search: screens.NewPaletteSearchModel(exec),
+		theme:  theme,
+		search: screens.NewPaletteSearchModel(exec, theme),
+	}
+}
+
+// loadTheme resolves cfg.Theme against the themes on files, warning on stderr
+// (visible once the TUI exits) when it falls back to the default theme.
+func loadTheme(files domain.FileSystem, cfg *config.Config) *styles.Theme {
+	theme, err := styles.Load(files, cfg.Theme)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "avro: warning:", err)
 	}
+	return theme
 }
@@ -13,13 +13,14 @@ type ConfirmResult struct {
 
 // ConfirmModel is a yes/no confirmation dialog.
 type ConfirmModel struct {
+	theme   *styles.Theme
 	Message string
 	yes     bool
 }
 
 // NewConfirmModel creates a confirm dialog.
-func NewConfirmModel(message string) ConfirmModel {
-	return ConfirmModel{Message: message, yes: true}
+func NewConfirmModel(theme *styles.Theme, message string) ConfirmModel {
+	return ConfirmModel{theme: theme, Message: message, yes: true}
 }
 
 func (m ConfirmModel) Init() tea.Cmd { return nil }
@@ -48,11 +49,11 @@ func (m ConfirmModel) View() string {
 	noLabel := "  No  "
 
 	if m.yes {
-		yesLabel = styles.SelectedItem.Render("[Yes]")
-		noLabel = styles.NormalItem.Render(" No ")
+		yesLabel = m.theme.SelectedItem.Render("[Yes]")
+		noLabel = m.theme.NormalItem.Render(" No ")
 	} else {
-		yesLabel = styles.NormalItem.Render(" Yes ")
-		noLabel = styles.SelectedItem.Render("[No]")
+		yesLabel = m.theme.NormalItem.Render(" Yes ")
+		noLabel = m.theme.SelectedItem.Render("[No]")
 	}
 
 	return m.Message + "\n\n" + yesLabel + "    " + noLabel
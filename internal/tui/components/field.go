@@ -0,0 +1,295 @@
+package components
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/tui/styles"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fieldKind selects the widget a FieldModel edits its value with.
+type fieldKind int
+
+const (
+	fieldText   fieldKind = iota
+	fieldNumber           // text input accepting only an integer
+	fieldToggle           // on/off switch for ArgBool
+	fieldSelect           // one of ArgDef.Choices
+	fieldList             // values of a Repeatable def, added one at a time
+)
+
+// FieldModel edits the value of one arg or flag with a widget matching its
+// ArgDef: a toggle for bools, a list for repeatable defs, a select list when
+// the def has choices, a numeric input for ints and a text input otherwise.
+// An empty value means the def's default, which the widget shows as a placeholder.
+type FieldModel struct {
+	Def domain.ArgDef
+
+	theme   *styles.Theme
+	kind    fieldKind
+	input   textinput.Model // text and number
+	on      bool            // toggle
+	choice  int             // select; -1 leaves the default
+	values  []string        // list; the input holds the value being typed
+	focused bool
+	err     error
+}
+
+// NewField creates the widget for def.
+func NewField(def domain.ArgDef, theme *styles.Theme) FieldModel {
+	f := FieldModel{Def: def, theme: theme, choice: -1}
+	switch {
+	case def.Type == domain.ArgBool:
+		f.kind = fieldToggle
+		f.on = def.Default == "true"
+	case def.Repeatable:
+		f.kind = fieldList
+	case len(def.Choices) > 0:
+		f.kind = fieldSelect
+	case def.Type == domain.ArgInt:
+		f.kind = fieldNumber
+	}
+
+	in := textinput.New()
+	in.Prompt = ""
+	in.Placeholder = strings.Join(domain.SplitValues(def.Default), ", ")
+	in.PlaceholderStyle = theme.Description
+	in.Cursor.SetMode(cursor.CursorStatic)
+	f.input = in
+	return f
+}
+
+// Value returns the entered value, or "" to use the default.
+func (f FieldModel) Value() string {
+	switch f.kind {
+	case fieldToggle:
+		// A toggle always holds a value; report it only when it differs from the default.
+		if !f.Def.Required && f.on == (f.Def.Default == "true") {
+			return ""
+		}
+		return strconv.FormatBool(f.on)
+	case fieldSelect:
+		if f.choice < 0 {
+			return ""
+		}
+		return f.Def.Choices[f.choice]
+	case fieldList:
+		values := f.values
+		if v := strings.TrimSpace(f.input.Value()); v != "" {
+			values = append(slices.Clip(values), v)
+		}
+		return domain.JoinValues(values)
+	default:
+		return strings.TrimSpace(f.input.Value())
+	}
+}
+
+// SetValue replaces the value, e.g. to prefill the form from history.
+func (f *FieldModel) SetValue(v string) {
+	switch f.kind {
+	case fieldToggle:
+		if b, err := strconv.ParseBool(v); err == nil {
+			f.on = b
+		}
+	case fieldSelect:
+		f.choice = slices.Index(f.Def.Choices, v)
+	case fieldList:
+		f.values = domain.SplitValues(v)
+		f.input.SetValue("")
+	default:
+		f.input.SetValue(v)
+	}
+	f.err = nil
+}
+
+// HandlesEnter reports whether enter adds to the field rather than moving
+// on: it does in a list while a value is being typed.
+func (f FieldModel) HandlesEnter() bool {
+	return f.kind == fieldList && strings.TrimSpace(f.input.Value()) != ""
+}
+
+// Focus gives the field keyboard input.
+func (f *FieldModel) Focus() {
+	f.focused = true
+	f.input.Focus()
+}
+
+// Blur removes keyboard input from the field and validates what was entered.
+func (f *FieldModel) Blur() {
+	f.focused = false
+	f.input.Blur()
+	f.Validate()
+}
+
+// Validate checks the value against the def's type and constraints, keeping
+// the error to show beside the field.
+func (f *FieldModel) Validate() error {
+	f.err = nil
+	v := f.Value()
+	if v == "" {
+		if f.Def.Required && f.Def.Default == "" {
+			f.err = errors.New("required")
+		}
+		return f.err
+	}
+
+	values := []string{v}
+	if f.kind == fieldList {
+		values = domain.SplitValues(v)
+	}
+	for _, v := range values {
+		if f.Def.Type == domain.ArgInt {
+			if _, err := strconv.Atoi(v); err != nil {
+				f.err = errors.New("expected an integer")
+				return f.err
+			}
+		}
+		var verr *domain.ValidationError
+		if err := f.Def.Check(v); errors.As(err, &verr) {
+			f.err = errors.New(verr.Message)
+			return f.err
+		}
+	}
+	return nil
+}
+
+// Err returns the error from the last validation.
+func (f FieldModel) Err() error { return f.err }
+
+func (f FieldModel) Update(msg tea.Msg) (FieldModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || !f.focused {
+		return f, nil
+	}
+
+	switch f.kind {
+	case fieldToggle:
+		switch key.String() {
+		case " ", "left", "right":
+			f.on = !f.on
+		}
+		return f, nil
+
+	case fieldSelect:
+		n := len(f.Def.Choices)
+		current := f.choice
+		if current < 0 {
+			current = max(slices.Index(f.Def.Choices, f.Def.Default), 0)
+		}
+		switch key.String() {
+		case "right", " ":
+			f.choice = (current + 1) % n
+		case "left":
+			f.choice = (current + n - 1) % n
+		case "backspace", "delete":
+			f.choice = -1
+		}
+		return f, nil
+
+	case fieldList:
+		switch {
+		case key.Type == tea.KeyEnter:
+			if v := strings.TrimSpace(f.input.Value()); v != "" {
+				f.values = append(f.values, v)
+				f.input.SetValue("")
+			}
+			return f, nil
+		case key.Type == tea.KeyBackspace && f.input.Value() == "" && len(f.values) > 0:
+			f.values = f.values[:len(f.values)-1]
+			return f, nil
+		}
+
+	case fieldNumber:
+		if key.Type == tea.KeyRunes && !key.Paste && !numeric(insert(f.input.Value(), f.input.Position(), key.Runes)) {
+			return f, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	if f.err != nil {
+		f.Validate()
+	}
+	return f, cmd
+}
+
+// View renders the widget, followed by its validation error if any.
+func (f FieldModel) View() string {
+	var v string
+	switch f.kind {
+	case fieldToggle:
+		v = "[ ] off"
+		if f.on {
+			v = "[x] on"
+		}
+		if f.focused {
+			v = f.theme.Match.Render(v)
+		}
+	case fieldSelect:
+		selected := f.choice
+		if selected < 0 {
+			selected = slices.Index(f.Def.Choices, f.Def.Default)
+		}
+		opts := make([]string, len(f.Def.Choices))
+		for i, c := range f.Def.Choices {
+			switch {
+			case i == selected && f.focused:
+				opts[i] = f.theme.Match.Render(c)
+			case i == selected:
+				opts[i] = c
+			default:
+				opts[i] = f.theme.Description.Render(c)
+			}
+		}
+		v = strings.Join(opts, f.theme.Description.Render(" | "))
+	case fieldList:
+		for _, item := range f.values {
+			v += "[" + item + "] "
+		}
+		v += f.input.View()
+	default:
+		v = f.input.View()
+	}
+	if f.err != nil {
+		v += "  " + f.theme.ErrorText.Render(f.err.Error())
+	}
+	return v
+}
+
+// Help describes the keys the widget responds to.
+func (f FieldModel) Help() string {
+	switch f.kind {
+	case fieldToggle:
+		return "space: toggle"
+	case fieldSelect:
+		return "left/right: choose"
+	case fieldList:
+		return "enter: add value | backspace: remove last"
+	default:
+		return ""
+	}
+}
+
+// insert returns value with runes typed at the rune offset pos.
+func insert(value string, pos int, runes []rune) string {
+	v := []rune(value)
+	pos = min(max(pos, 0), len(v))
+	return string(v[:pos]) + string(runes) + string(v[pos:])
+}
+
+// numeric reports whether s can still become an integer: digits, optionally
+// after a leading '-'.
+func numeric(s string) bool {
+	for _, r := range strings.TrimPrefix(s, "-") {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,273 @@
+package components
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/tui/styles"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// OutputHelp lists the keys OutputModel handles, for screens to include in their help line.
+const OutputHelp = "j/k: scroll | g/G: top/bottom | /: find | n/N: next/prev | w: wrap"
+
+// OutputModel is a scrollable view over command output with in-output search,
+// match highlighting and a line-wrap toggle.
+type OutputModel struct {
+	theme    *styles.Theme
+	viewport viewport.Model
+	input    textinput.Model
+	lines    []domain.OutputLine
+
+	wrap      bool
+	searching bool   // the search prompt has focus
+	query     string // last confirmed query
+	matches   []int  // indexes into lines containing query
+	current   int    // index into matches
+	offsets   []int  // first rendered row of each line, accounting for wrap
+}
+
+// NewOutputModel creates an empty output view.
+func NewOutputModel(theme *styles.Theme) OutputModel {
+	vp := viewport.New(0, 0)
+	vp.SetHorizontalStep(8)
+
+	in := textinput.New()
+	in.Prompt = "/"
+	in.Placeholder = "search output"
+
+	return OutputModel{theme: theme, viewport: vp, input: in, wrap: true}
+}
+
+// SetSize sets the visible area in cells, excluding any surrounding box.
+func (m *OutputModel) SetSize(width, height int) {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	m.viewport.Width = width
+	m.viewport.Height = height
+	m.input.Width = width - 2
+	m.refresh()
+}
+
+// SetLines replaces the content. When the view was scrolled to the bottom it
+// stays there, so streamed output follows the newest line.
+func (m *OutputModel) SetLines(lines []domain.OutputLine) {
+	follow := m.viewport.AtBottom()
+	m.lines = lines
+	m.findMatches()
+	m.refresh()
+	if follow {
+		m.viewport.GotoBottom()
+	}
+}
+
+// SetText replaces the content with plain stdout text.
+func (m *OutputModel) SetText(text string) {
+	var lines []domain.OutputLine
+	for _, l := range strings.Split(text, "\n") {
+		lines = append(lines, domain.OutputLine{Stream: domain.Stdout, Text: l})
+	}
+	m.SetLines(lines)
+}
+
+// Searching reports whether the search prompt has focus and is consuming keys.
+func (m OutputModel) Searching() bool { return m.searching }
+
+func (m OutputModel) Update(msg tea.Msg) (OutputModel, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+
+	if m.searching {
+		switch key.String() {
+		case "enter":
+			m.searching = false
+			m.input.Blur()
+			m.query = m.input.Value()
+			m.findMatches()
+			m.refresh()
+			m.jumpToMatch()
+			return m, nil
+		case "esc":
+			m.searching = false
+			m.input.Blur()
+			m.input.SetValue(m.query)
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	switch key.String() {
+	case "/":
+		m.searching = true
+		m.input.SetValue("")
+		return m, m.input.Focus()
+	case "n":
+		if len(m.matches) > 0 {
+			m.current = (m.current + 1) % len(m.matches)
+			m.refresh()
+			m.jumpToMatch()
+		}
+		return m, nil
+	case "N":
+		if len(m.matches) > 0 {
+			m.current = (m.current - 1 + len(m.matches)) % len(m.matches)
+			m.refresh()
+			m.jumpToMatch()
+		}
+		return m, nil
+	case "g", "home":
+		m.viewport.GotoTop()
+		return m, nil
+	case "G", "end":
+		m.viewport.GotoBottom()
+		return m, nil
+	case "w":
+		m.wrap = !m.wrap
+		m.viewport.SetXOffset(0)
+		m.refresh()
+		m.jumpToMatch()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// GotoTop scrolls to the first line.
+func (m *OutputModel) GotoTop() { m.viewport.GotoTop() }
+
+// ClearSearch drops the active query and its highlights.
+func (m *OutputModel) ClearSearch() {
+	m.query = ""
+	m.input.SetValue("")
+	m.findMatches()
+	m.refresh()
+}
+
+// HasQuery reports whether a confirmed search query is highlighted.
+func (m OutputModel) HasQuery() bool { return m.query != "" }
+
+func (m OutputModel) View() string {
+	var b strings.Builder
+	b.WriteString(m.viewport.View())
+
+	switch {
+	case m.searching:
+		b.WriteString("\n" + m.input.View())
+	case m.query != "":
+		status := "no matches"
+		if len(m.matches) > 0 {
+			status = fmt.Sprintf("match %d/%d", m.current+1, len(m.matches))
+		}
+		b.WriteString("\n" + m.theme.Description.Render(fmt.Sprintf("/%s  %s", m.query, status)))
+	}
+	return b.String()
+}
+
+// findMatches records which lines contain the query, case-insensitively.
+func (m *OutputModel) findMatches() {
+	m.matches = nil
+	if m.query == "" {
+		m.current = 0
+		return
+	}
+	q := strings.ToLower(m.query)
+	for i, l := range m.lines {
+		if strings.Contains(strings.ToLower(l.Text), q) {
+			m.matches = append(m.matches, i)
+		}
+	}
+	if m.current >= len(m.matches) {
+		m.current = 0
+	}
+}
+
+// refresh re-renders content into the viewport, preserving the scroll position.
+func (m *OutputModel) refresh() {
+	currentLine := -1
+	if len(m.matches) > 0 {
+		currentLine = m.matches[m.current]
+	}
+
+	rows := make([]string, 0, len(m.lines))
+	m.offsets = make([]int, len(m.lines))
+	row := 0
+	for i, l := range m.lines {
+		rendered := m.renderLine(l, i == currentLine)
+		if m.wrap && m.viewport.Width > 0 {
+			rendered = ansi.Wrap(rendered, m.viewport.Width, "")
+		}
+		m.offsets[i] = row
+		row += strings.Count(rendered, "\n") + 1
+		rows = append(rows, rendered)
+	}
+
+	offset := m.viewport.YOffset
+	m.viewport.SetContent(strings.Join(rows, "\n"))
+	m.viewport.SetYOffset(offset)
+}
+
+// renderLine styles one line, highlighting every occurrence of the query.
+func (m OutputModel) renderLine(l domain.OutputLine, current bool) string {
+	base := lipgloss.NewStyle()
+	if l.Stream == domain.Stderr {
+		base = m.theme.Description
+	}
+	if m.query == "" {
+		return base.Render(l.Text)
+	}
+
+	lower := strings.ToLower(l.Text)
+	q := strings.ToLower(m.query)
+	// Case folding changed byte lengths; offsets would be wrong, skip highlighting.
+	if len(lower) != len(l.Text) {
+		return base.Render(l.Text)
+	}
+
+	hl := m.theme.Match
+	if current {
+		hl = m.theme.CurrentMatch
+	}
+
+	var b strings.Builder
+	rest := 0
+	for {
+		i := strings.Index(lower[rest:], q)
+		if i < 0 {
+			break
+		}
+		start, end := rest+i, rest+i+len(q)
+		b.WriteString(base.Render(l.Text[rest:start]))
+		b.WriteString(hl.Render(l.Text[start:end]))
+		rest = end
+	}
+	b.WriteString(base.Render(l.Text[rest:]))
+	return b.String()
+}
+
+// jumpToMatch scrolls so the current match is visible.
+func (m *OutputModel) jumpToMatch() {
+	if len(m.matches) == 0 {
+		return
+	}
+	row := m.offsets[m.matches[m.current]]
+	if row < m.viewport.YOffset || row >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(row - m.viewport.Height/2)
+	}
+}
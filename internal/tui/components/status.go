@@ -1,15 +1,19 @@
 package components
 
-import "avro_cli/internal/tui/styles"
+import (
+	"avro_cli/internal/tui/styles"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 // StatusBar renders a bottom status bar with breadcrumb and help text.
-func StatusBar(breadcrumb string, width int) string {
+func StatusBar(theme *styles.Theme, breadcrumb string, width int) string {
 	text := " " + breadcrumb
-	padding := width - len(text)
+	padding := width - lipgloss.Width(text)
 	if padding > 0 {
 		for i := 0; i < padding; i++ {
 			text += " "
 		}
 	}
-	return styles.StatusBar.Render(text)
+	return theme.StatusBar.Render(text)
 }
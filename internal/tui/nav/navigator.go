@@ -14,6 +14,7 @@ const (
 	CategoryScreen
 	CommandDetailScreen
 	SearchScreen
+	HistoryScreen
 )
 
 // Entry represents a screen on the navigation stack with context.
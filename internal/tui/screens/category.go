@@ -13,6 +13,7 @@ import (
 
 // CategoryModel displays commands within a category.
 type CategoryModel struct {
+	theme    *styles.Theme
 	category string
 	commands []domain.CommandDescriptor
 	cursor   int
@@ -21,8 +22,9 @@ type CategoryModel struct {
 }
 
 // NewCategoryModel creates a category screen for the given category name.
-func NewCategoryModel(category string) CategoryModel {
+func NewCategoryModel(category string, theme *styles.Theme) CategoryModel {
 	return CategoryModel{
+		theme:    theme,
 		category: category,
 		commands: registry.Global().ByCategory(category),
 	}
@@ -63,20 +65,20 @@ func (m CategoryModel) Update(msg tea.Msg) (CategoryModel, tea.Cmd) {
 func (m CategoryModel) View() string {
 	var b strings.Builder
 
-	b.WriteString(styles.Subtitle.Render(m.category) + "\n\n")
+	b.WriteString(m.theme.Subtitle.Render(m.category) + "\n\n")
 
 	for i, cmd := range m.commands {
-		line := fmt.Sprintf("%-16s %s", cmd.Name, styles.Description.Render(cmd.Description))
+		line := fmt.Sprintf("%-16s %s", cmd.Name, m.theme.Description.Render(cmd.Description))
 		if i == m.cursor {
-			b.WriteString(styles.SelectedItem.Render(line))
+			b.WriteString(m.theme.SelectedItem.Render(line))
 		} else {
-			b.WriteString(styles.NormalItem.Render(line))
+			b.WriteString(m.theme.NormalItem.Render(line))
 		}
 		b.WriteString("\n")
 	}
 
 	b.WriteString("\n")
-	b.WriteString(styles.HelpStyle.Render("j/k: navigate | enter: select | esc: back | /: search | q: quit"))
+	b.WriteString(m.theme.HelpStyle.Render("j/k: navigate | enter: select | esc: back | /: search | q: quit"))
 
 	return b.String()
 }
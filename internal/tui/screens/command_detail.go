@@ -3,15 +3,20 @@ package screens
 import (
 	"avro_cli/internal/app/executor"
 	"avro_cli/internal/domain"
+	"avro_cli/internal/tui/components"
 	"avro_cli/internal/tui/styles"
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 // CommandDetailModel shows a command form, executes it, and displays output.
 type CommandDetailModel struct {
+	theme    *styles.Theme
 	cmd      domain.CommandDescriptor
 	exec     *executor.Executor
 	fields   []fieldEntry
@@ -19,166 +24,457 @@ type CommandDetailModel struct {
 	output   string
 	hasError bool
 	executed bool
+	running  bool
+	cancel   context.CancelFunc
+	spinner  spinner.Model
+	started  time.Time
+	elapsed  time.Duration // final run time, set when the result arrives
+	stopping bool          // cancel requested, waiting for the action to return
+	streamed []domain.OutputLine
+	lines    chan domain.OutputLine
+	result   chan domain.Result[string] // holds the run's result once lines closes
+	out      components.OutputModel
 	width    int
 	height   int
 }
 
+// commandResultMsg delivers the outcome of an execution started by execute.
+type commandResultMsg struct {
+	ch     chan domain.OutputLine // the run's line channel; nil for interactive runs
+	result domain.Result[string]
+}
+
+// outputLineMsg delivers one streamed line from the channel of a running command.
+type outputLineMsg struct {
+	ch   chan domain.OutputLine
+	line domain.OutputLine
+}
+
+// waitForOutput blocks until the next streamed line. Once the channel closes,
+// every line has been delivered and it returns the run's result instead, so
+// the result can never overtake output still in flight.
+func waitForOutput(ch chan domain.OutputLine, result chan domain.Result[string]) tea.Cmd {
+	return func() tea.Msg {
+		line, ok := <-ch
+		if !ok {
+			return commandResultMsg{ch: ch, result: <-result}
+		}
+		return outputLineMsg{ch: ch, line: line}
+	}
+}
+
 type fieldEntry struct {
-	def   domain.ArgDef
-	value string
-	isArg bool // true for positional args, false for flags
+	field   components.FieldModel
+	isArg   bool // true for positional args, false for flags
+	isExtra bool // free-form trailing args, split on whitespace
 }
 
 // NewCommandDetailModel creates the command detail screen.
-func NewCommandDetailModel(cmd domain.CommandDescriptor, exec *executor.Executor) CommandDetailModel {
+func NewCommandDetailModel(cmd domain.CommandDescriptor, exec *executor.Executor, theme *styles.Theme) CommandDetailModel {
 	var fields []fieldEntry
 	for _, a := range cmd.Args {
-		fields = append(fields, fieldEntry{def: a, value: a.Default, isArg: true})
+		fields = append(fields, fieldEntry{field: components.NewField(a, theme), isArg: true})
+	}
+	if cmd.ExtraArgs {
+		extra := domain.ArgDef{Name: "args", Description: "Additional arguments, space separated"}
+		fields = append(fields, fieldEntry{field: components.NewField(extra, theme), isExtra: true})
 	}
 	for _, f := range cmd.Flags {
-		fields = append(fields, fieldEntry{def: f, value: f.Default, isArg: false})
+		fields = append(fields, fieldEntry{field: components.NewField(f, theme)})
+	}
+	if len(fields) > 0 {
+		fields[0].field.Focus()
 	}
 
 	return CommandDetailModel{
-		cmd:    cmd,
-		exec:   exec,
-		fields: fields,
+		theme:   theme,
+		cmd:     cmd,
+		exec:    exec,
+		fields:  fields,
+		spinner: spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(theme.Spinner)),
+		out:     components.NewOutputModel(theme),
 	}
 }
 
+// Prefill sets the form from a previous invocation: args fill the positional
+// fields in order, a repeatable last field taking all that remain, any beyond
+// them go to the extra-args field, and flags are matched by name.
+func (m CommandDetailModel) Prefill(args []string, flags map[string]string) CommandDetailModel {
+	fields := make([]fieldEntry, len(m.fields))
+	copy(fields, m.fields)
+	for i := range fields {
+		f := &fields[i]
+		switch {
+		case f.isExtra:
+			f.field.SetValue(strings.Join(args, " "))
+		case f.isArg && f.field.Def.Repeatable:
+			// Only the last arg may repeat; it takes every remaining value.
+			f.field.SetValue(domain.JoinValues(args))
+			args = nil
+		case f.isArg:
+			if len(args) > 0 {
+				f.field.SetValue(args[0])
+				args = args[1:]
+			}
+		default:
+			if v, ok := flags[f.field.Def.Name]; ok {
+				f.field.SetValue(v)
+			}
+		}
+	}
+	m.fields = fields
+	return m
+}
+
 func (m CommandDetailModel) Init() tea.Cmd { return nil }
 
+// Running reports whether a command is currently executing.
+func (m CommandDetailModel) Running() bool { return m.running }
+
+// HandlesEsc reports whether esc means something on this screen right now
+// (cancel a run, leave output search) rather than navigating back.
+func (m CommandDetailModel) HandlesEsc() bool {
+	return m.running || m.out.Searching() || m.out.HasQuery()
+}
+
+// Status summarizes execution progress for the status bar, or "" when idle.
+func (m CommandDetailModel) Status() string {
+	switch {
+	case m.running && m.stopping:
+		return fmt.Sprintf("%s · cancelling %s", m.cmd.FullName(), formatElapsed(time.Since(m.started)))
+	case m.running:
+		return fmt.Sprintf("%s · running %s", m.cmd.FullName(), formatElapsed(time.Since(m.started)))
+	case m.executed && m.hasError:
+		return fmt.Sprintf("%s · failed after %s", m.cmd.FullName(), formatElapsed(m.elapsed))
+	case m.executed:
+		return fmt.Sprintf("%s · finished in %s", m.cmd.FullName(), formatElapsed(m.elapsed))
+	}
+	return ""
+}
+
+func formatElapsed(d time.Duration) string {
+	return d.Round(100 * time.Millisecond).String()
+}
+
 func (m CommandDetailModel) Update(msg tea.Msg) (CommandDetailModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.resizeOutput()
+
+	case outputLineMsg:
+		// Ignore stragglers from a previous run
+		if msg.ch != m.lines {
+			return m, nil
+		}
+		m.streamed = appendLine(m.streamed, msg.line)
+		m.out.SetLines(m.streamed)
+		return m, waitForOutput(m.lines, m.result)
+
+	case spinner.TickMsg:
+		if !m.running {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case commandResultMsg:
+		if msg.ch != m.lines {
+			return m, nil
+		}
+		m.running = false
+		m.stopping = false
+		m.cancel = nil
+		m.executed = true
+		m.elapsed = time.Since(m.started)
+		if msg.result.IsOk() {
+			m.output = msg.result.Value()
+			m.hasError = false
+			if m.output == "" && len(m.streamed) == 0 {
+				m.output = "(no output)"
+			}
+			m.out.SetLines(resultLines(m.streamed, m.output))
+		} else {
+			m.output = msg.result.Err().Error()
+			m.hasError = true
+			m.out.SetLines(m.streamed)
+		}
+		// Output that arrived all at once reads from the top; streamed output stays where it was.
+		if len(m.streamed) == 0 {
+			m.out.GotoTop()
+		}
+		m.resizeOutput()
 
 	case tea.KeyMsg:
+		if m.running {
+			if msg.String() == "esc" && m.cancel != nil {
+				m.cancel()
+				m.stopping = true
+			}
+			return m, nil
+		}
+
 		if m.executed {
-			// After execution, any key goes back to form
+			if m.out.Searching() {
+				var cmd tea.Cmd
+				m.out, cmd = m.out.Update(msg)
+				return m, cmd
+			}
 			switch msg.String() {
 			case "r":
 				m.executed = false
 				m.output = ""
 				m.hasError = false
+				m.streamed = nil
+				m.out = components.NewOutputModel(m.theme)
+				m.resizeOutput()
+				m.focus(m.cursor)
+				return m, nil
+			case "esc":
+				m.out.ClearSearch()
+				return m, nil
 			}
-			return m, nil
+			var cmd tea.Cmd
+			m.out, cmd = m.out.Update(msg)
+			return m, cmd
 		}
 
 		switch msg.String() {
 		case "up", "shift+tab":
 			if m.cursor > 0 {
-				m.cursor--
+				m.focus(m.cursor - 1)
 			}
 		case "down", "tab":
 			if m.cursor < len(m.fields)-1 {
-				m.cursor++
+				m.focus(m.cursor + 1)
 			}
 		case "enter":
-			if len(m.fields) == 0 || m.cursor >= len(m.fields) {
-				m.execute()
-			} else if m.cursor == len(m.fields)-1 {
+			if m.cursor < len(m.fields) && m.fields[m.cursor].field.HandlesEnter() {
+				var cmd tea.Cmd
+				m.fields[m.cursor].field, cmd = m.fields[m.cursor].field.Update(msg)
+				return m, cmd
+			}
+			if len(m.fields) == 0 || m.cursor >= len(m.fields)-1 {
 				// On last field, enter executes
-				m.execute()
-			} else {
-				m.cursor++
+				return m, m.execute()
 			}
+			m.focus(m.cursor + 1)
 		case "ctrl+r":
-			m.execute()
-		case "backspace":
-			if len(m.fields) > 0 && m.cursor < len(m.fields) {
-				f := &m.fields[m.cursor]
-				if len(f.value) > 0 {
-					f.value = f.value[:len(f.value)-1]
-				}
-			}
+			return m, m.execute()
 		default:
-			if len(msg.String()) == 1 && len(m.fields) > 0 && m.cursor < len(m.fields) {
-				m.fields[m.cursor].value += msg.String()
+			if m.cursor < len(m.fields) {
+				var cmd tea.Cmd
+				m.fields[m.cursor].field, cmd = m.fields[m.cursor].field.Update(msg)
+				return m, cmd
 			}
 		}
 	}
 	return m, nil
 }
 
-func (m *CommandDetailModel) execute() {
+// focus moves keyboard input to field i, validating the one being left.
+func (m *CommandDetailModel) focus(i int) {
+	if m.cursor < len(m.fields) {
+		m.fields[m.cursor].field.Blur()
+	}
+	m.cursor = i
+	if i < len(m.fields) {
+		m.fields[i].field.Focus()
+	}
+}
+
+// validate checks every field, focusing the first invalid one. It reports
+// whether the form can run.
+func (m *CommandDetailModel) validate() bool {
+	first := -1
+	for i := range m.fields {
+		if m.fields[i].field.Validate() != nil && first < 0 {
+			first = i
+		}
+	}
+	if first >= 0 {
+		m.focus(first)
+		return false
+	}
+	return true
+}
+
+// execute starts the command in the background; esc cancels it via m.cancel.
+func (m *CommandDetailModel) execute() tea.Cmd {
+	if !m.validate() {
+		return nil
+	}
+	if m.cursor < len(m.fields) {
+		m.fields[m.cursor].field.Blur()
+	}
+
 	args := make([]string, 0)
 	flags := make(map[string]string)
 
+	var extra []string
 	for _, f := range m.fields {
-		if f.isArg {
-			args = append(args, f.value)
-		} else if f.value != "" {
-			flags[f.def.Name] = f.value
+		value := f.field.Value()
+		if f.isExtra {
+			extra = strings.Fields(value)
+		} else if f.isArg && f.field.Def.Repeatable {
+			args = append(args, domain.SplitValues(value)...)
+		} else if f.isArg {
+			args = append(args, value)
+		} else if value != "" {
+			flags[f.field.Def.Name] = value
 		}
 	}
+	args = append(args, extra...)
 
-	result := m.exec.Run(m.cmd, args, flags)
-	m.executed = true
-	if result.IsOk() {
-		m.output = result.Value()
-		m.hasError = false
-	} else {
-		m.output = result.Err().Error()
-		m.hasError = true
+	if m.cmd.Interactive {
+		// The command needs the terminal: suspend the TUI rather than stream.
+		m.running = true
+		m.started = time.Now()
+		m.streamed = nil
+		m.lines, m.result = nil, nil
+		m.out = components.NewOutputModel(m.theme)
+		m.resizeOutput()
+		run := &interactiveRun{exec: m.exec, cmd: m.cmd, args: args, flags: flags}
+		return tea.Exec(run, func(error) tea.Msg { return commandResultMsg{result: run.result} })
 	}
+
+	lines, result, cancel, run := startRun(m.exec, m.cmd, args, flags)
+	m.cancel = cancel
+	m.running = true
+	m.started = time.Now()
+	m.streamed = nil
+	m.lines, m.result = lines, result
+	m.out = components.NewOutputModel(m.theme)
+	m.resizeOutput()
+	return tea.Batch(run, m.spinner.Tick)
+}
+
+// startRun prepares a background run of cmd. The returned tea.Cmd runs it and
+// waits for its first line; further lines and then the result arrive as
+// outputLineMsg and commandResultMsg tagged with lines. cancel stops the run.
+func startRun(exec *executor.Executor, cmd domain.CommandDescriptor, args []string, flags map[string]string) (chan domain.OutputLine, chan domain.Result[string], context.CancelFunc, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := make(chan domain.OutputLine, 64)
+	result := make(chan domain.Result[string], 1)
+	// Once the run is cancelled, e.g. because the screen was left, nobody
+	// may be reading: drop the line rather than block the command.
+	sink := domain.OutputSinkFunc(func(line domain.OutputLine) {
+		select {
+		case lines <- line:
+		case <-ctx.Done():
+		}
+	})
+
+	run := func() tea.Msg {
+		defer cancel()
+		result <- exec.RunStream(ctx, cmd, args, flags, sink)
+		close(lines)
+		return nil
+	}
+	return lines, result, cancel, tea.Batch(run, waitForOutput(lines, result))
+}
+
+// Stop cancels a running command, for when the screen is left while it runs.
+func (m CommandDetailModel) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+}
+
+// appendLine adds line to the streamed output, replacing the previous line
+// of the same stream when that was a progress update.
+func appendLine(lines []domain.OutputLine, line domain.OutputLine) []domain.OutputLine {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i].Stream == line.Stream {
+			if lines[i].Progress {
+				out := make([]domain.OutputLine, 0, len(lines))
+				out = append(append(out, lines[:i]...), lines[i+1:]...)
+				return append(out, line)
+			}
+			break
+		}
+	}
+	return append(lines, line)
 }
 
 func (m CommandDetailModel) View() string {
 	var b strings.Builder
 
-	b.WriteString(styles.Subtitle.Render(m.cmd.FullName()) + "\n")
-	b.WriteString(styles.Description.Render(m.cmd.Description) + "\n\n")
+	b.WriteString(m.theme.Subtitle.Render(m.cmd.FullName()) + "\n")
+	b.WriteString(m.theme.Description.Render(m.cmd.Description) + "\n\n")
 
 	if len(m.fields) == 0 {
-		b.WriteString(styles.Description.Render("No arguments required") + "\n")
+		b.WriteString(m.theme.Description.Render("No arguments required") + "\n")
 	} else {
+		editing := !m.executed && !m.running
 		for i, f := range m.fields {
-			label := f.def.Name
-			if f.def.Required {
+			label := f.field.Def.Name
+			if f.field.Def.Required {
 				label += "*"
 			}
 			prefix := "  "
-			if i == m.cursor && !m.executed {
+			if i == m.cursor && editing {
 				prefix = "> "
 			}
 
-			val := f.value
-			if i == m.cursor && !m.executed {
-				val += "_"
-			}
-			if val == "" && f.def.Default != "" {
-				val = styles.Description.Render("(" + f.def.Default + ")")
-			}
-
-			line := fmt.Sprintf("%s%-16s %s", prefix, label+":", val)
-			if i == m.cursor && !m.executed {
-				b.WriteString(styles.SelectedItem.Render(line))
+			line := fmt.Sprintf("%s%-16s", prefix, label+":")
+			if i == m.cursor && editing {
+				b.WriteString(m.theme.SelectedItem.Render(line))
 			} else {
-				b.WriteString(styles.NormalItem.Render(line))
+				b.WriteString(m.theme.NormalItem.Render(line))
 			}
-			b.WriteString("\n")
+			b.WriteString(" " + f.field.View() + "\n")
 		}
 	}
 
-	if m.executed {
+	if m.running {
+		b.WriteString("\n")
+		if len(m.streamed) > 0 {
+			b.WriteString(m.theme.OutputBox.Render(m.out.View()))
+			b.WriteString("\n")
+		}
+		label := "Running..."
+		if m.stopping {
+			label = "Cancelling..."
+		}
+		b.WriteString(m.spinner.View() + " " + m.theme.Description.Render(label))
+		b.WriteString("\n\n")
+		b.WriteString(m.theme.HelpStyle.Render("esc: cancel"))
+	} else if m.executed {
 		b.WriteString("\n")
 		if m.hasError {
-			b.WriteString(styles.ErrorText.Render("Error: ") + m.output)
-		} else {
-			outputView := m.output
-			if outputView == "" {
-				outputView = "(no output)"
+			if len(m.streamed) > 0 {
+				b.WriteString(m.theme.OutputBox.Render(m.out.View()))
+				b.WriteString("\n")
 			}
-			b.WriteString(styles.OutputBox.Render(outputView))
+			b.WriteString(m.theme.ErrorText.Render("Error: ") + m.output)
+		} else {
+			b.WriteString(m.theme.OutputBox.Render(m.out.View()))
 		}
 		b.WriteString("\n\n")
-		b.WriteString(styles.HelpStyle.Render("r: run again | esc: back"))
+		b.WriteString(m.theme.HelpStyle.Render("r: run again | esc: back | " + components.OutputHelp))
 	} else {
+		help := "tab/shift+tab: navigate | ctrl+r: run | esc: back"
+		if m.cursor < len(m.fields) {
+			if h := m.fields[m.cursor].field.Help(); h != "" {
+				help = h + " | " + help
+			}
+		}
 		b.WriteString("\n")
-		b.WriteString(styles.HelpStyle.Render("tab/shift+tab: navigate | ctrl+r: run | esc: back"))
+		b.WriteString(m.theme.HelpStyle.Render(help))
 	}
 
 	return b.String()
 }
+
+// resizeOutput fits the output viewport below the form.
+func (m *CommandDetailModel) resizeOutput() {
+	// breadcrumb, title, description, form, error line, help and status bar
+	reserved := len(m.fields) + 13
+	if m.hasError {
+		reserved += 2
+	}
+	m.out.SetSize(outputSize(m.width, m.height, reserved))
+}
@@ -0,0 +1,126 @@
+package screens_test
+
+import (
+	"avro_cli/internal/domain"
+	"avro_cli/internal/testkit"
+	"avro_cli/internal/tui/screens"
+	"avro_cli/internal/tui/tuitest"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// echoCmd prints its args quoted, so tests can see how each value arrived.
+var echoCmd = domain.CommandDescriptor{
+	Category:    domain.Category{Name: "demo", Description: "Demo commands"},
+	Name:        "echo",
+	Description: "Print the args",
+	Args: []domain.ArgDef{
+		{Name: "prefix", Description: "First word"},
+		{Name: "words", Description: "Remaining words", Repeatable: true},
+	},
+	Flags: []domain.ArgDef{
+		{Name: "sep", Description: "Separator"},
+	},
+	Action: func(ctx domain.CommandContext) domain.Result[string] {
+		return domain.Ok(fmt.Sprintf("%q %q %q", ctx.String("prefix"), ctx.Strings("words"), ctx.String("sep")))
+	},
+}
+
+// runDetail starts m's command with ctrl+r and returns m once it finished.
+func runDetail(t *testing.T, m screens.CommandDetailModel) screens.CommandDetailModel {
+	t.Helper()
+	d := newDriver(t)
+	m, cmd := m.Update(tuitest.Key("ctrl+r"))
+	if !m.Running() {
+		t.Fatalf("ctrl+r did not start the command:\n%s", m.View())
+	}
+	d.run(cmd)
+	for m.Running() {
+		m, cmd = m.Update(d.next())
+		d.run(cmd)
+	}
+	return m
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func detailModel(t *testing.T, cmd domain.CommandDescriptor) screens.CommandDetailModel {
+	env := testkit.New(t)
+	m := screens.NewCommandDetailModel(cmd, env.Executor, tuitest.Theme())
+	m, _ = m.Update(tea.WindowSizeMsg{Width: tuitest.Width, Height: tuitest.Height})
+	return m
+}
+
+func TestDetailRepeatableArg(t *testing.T) {
+	tests := []struct {
+		name string
+		fill func(screens.CommandDetailModel) screens.CommandDetailModel
+		want string
+	}{
+		{
+			name: "typed",
+			fill: func(m screens.CommandDetailModel) screens.CommandDetailModel {
+				for _, k := range []tea.KeyMsg{runes("p"), tuitest.Key("tab"), runes("a b"), tuitest.Key("enter"), runes("c")} {
+					m, _ = m.Update(k)
+				}
+				return m
+			},
+			want: `"p" ["a b" "c"] ""`,
+		},
+		{
+			name: "prefilled",
+			fill: func(m screens.CommandDetailModel) screens.CommandDetailModel {
+				return m.Prefill([]string{"p", "a b", "c", "d"}, map[string]string{"sep": ","})
+			},
+			want: `"p" ["a b" "c" "d"] ","`,
+		},
+		{
+			name: "prefilled without repeats",
+			fill: func(m screens.CommandDetailModel) screens.CommandDetailModel {
+				return m.Prefill([]string{"p"}, nil)
+			},
+			want: `"p" [] ""`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := runDetail(t, tt.fill(detailModel(t, echoCmd)))
+			if !strings.Contains(m.View(), tt.want) {
+				t.Errorf("view lacks %s:\n%s", tt.want, m.View())
+			}
+		})
+	}
+}
+
+func TestDetailRunAgain(t *testing.T) {
+	m := runDetail(t, detailModel(t, echoCmd).Prefill([]string{"p", "x"}, nil))
+	if got := m.Status(); !strings.HasPrefix(got, "demo echo · finished in ") {
+		t.Errorf("Status() = %q", got)
+	}
+
+	m, _ = m.Update(tuitest.Key("r"))
+	if m.Status() != "" || strings.Contains(m.View(), `"p"`) {
+		t.Errorf("r did not return to the form:\n%s", m.View())
+	}
+	m = runDetail(t, m)
+	if !strings.Contains(m.View(), `"p" ["x"] ""`) {
+		t.Errorf("second run lost the form values:\n%s", m.View())
+	}
+}
+
+func TestDetailRequiredField(t *testing.T) {
+	cmd := echoCmd
+	cmd.Args = []domain.ArgDef{{Name: "prefix", Description: "First word", Required: true}}
+	m, run := detailModel(t, cmd).Update(tuitest.Key("ctrl+r"))
+	if run != nil || m.Running() {
+		t.Fatal("ran with a required field empty")
+	}
+	if !strings.Contains(m.View(), "required") {
+		t.Errorf("view lacks the required error:\n%s", m.View())
+	}
+}
@@ -0,0 +1,141 @@
+package screens
+
+import (
+	"avro_cli/internal/app/history"
+	"avro_cli/internal/app/registry"
+	"avro_cli/internal/domain"
+	"avro_cli/internal/tui/nav"
+	"avro_cli/internal/tui/styles"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Rerun is the nav data for a CommandDetailScreen reopened from history: the
+// form starts filled in with the recorded args and flags.
+type Rerun struct {
+	Command domain.CommandDescriptor
+	Args    []string
+	Flags   map[string]string
+}
+
+// HistoryModel lists past executions, newest first.
+type HistoryModel struct {
+	theme   *styles.Theme
+	entries []history.Entry
+	err     error
+	notice  string
+	cursor  int
+	width   int
+	height  int
+}
+
+// NewHistoryModel creates the history screen from store, which may be nil
+// when history is disabled.
+func NewHistoryModel(store *history.Store, theme *styles.Theme) HistoryModel {
+	if store == nil {
+		return HistoryModel{theme: theme, err: history.ErrDisabled}
+	}
+	entries, err := store.List()
+	return HistoryModel{theme: theme, entries: entries, err: err}
+}
+
+func (m HistoryModel) Init() tea.Cmd { return nil }
+
+func (m HistoryModel) Update(msg tea.Msg) (HistoryModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		m.notice = ""
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.entries)-1 {
+				m.cursor++
+			}
+		case "enter":
+			if len(m.entries) == 0 {
+				break
+			}
+			e := m.entries[m.cursor]
+			// Redacted values would fill the form with placeholders, not secrets.
+			if e.Redacted {
+				m.notice = "recorded with secrets redacted; open the command from the menu and enter them again"
+				break
+			}
+			cmd, ok := registry.Global().Find(e.Category(), e.Name())
+			if !ok {
+				m.notice = fmt.Sprintf("%q is no longer available", e.Command)
+				break
+			}
+			return m, nav.PushScreen(nav.Entry{
+				Screen: nav.CommandDetailScreen,
+				Title:  cmd.Name,
+				Data:   Rerun{Command: cmd, Args: e.Args, Flags: e.Flags},
+			})
+		}
+	}
+	return m, nil
+}
+
+func (m HistoryModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.theme.Subtitle.Render("History") + "\n\n")
+
+	switch {
+	case m.err != nil:
+		b.WriteString(m.theme.ErrorText.Render("  "+m.err.Error()) + "\n")
+	case len(m.entries) == 0:
+		b.WriteString(m.theme.Description.Render("  No commands run yet") + "\n")
+	}
+
+	maxVisible := 15
+	if m.height > 0 {
+		maxVisible = m.height - 9
+		if maxVisible < 5 {
+			maxVisible = 5
+		}
+	}
+	start := 0
+	if m.cursor >= maxVisible {
+		start = m.cursor - maxVisible + 1
+	}
+	end := min(start+maxVisible, len(m.entries))
+
+	for i := start; i < end; i++ {
+		e := m.entries[i]
+		status := "✓"
+		if !e.Success {
+			status = "✗"
+		}
+		line := fmt.Sprintf("%s %s  %s", status, e.Time.Local().Format("Jan 02 15:04"), e.CommandLine())
+		if i == m.cursor {
+			b.WriteString(m.theme.SelectedItem.Render(line))
+		} else {
+			b.WriteString(m.theme.NormalItem.Render(line))
+		}
+		b.WriteString("\n")
+	}
+
+	if m.cursor < len(m.entries) {
+		if e := m.entries[m.cursor]; !e.Success && e.Error != "" {
+			b.WriteString("\n" + m.theme.ErrorText.Render("  "+e.Error) + "\n")
+		}
+	}
+	if m.notice != "" {
+		b.WriteString("\n" + m.theme.ErrorText.Render("  "+m.notice) + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.theme.HelpStyle.Render("j/k: navigate | enter: open with these values | esc: back | q: quit"))
+
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package screens_test
+
+import (
+	"avro_cli/internal/app/history"
+	"avro_cli/internal/domain"
+	"avro_cli/internal/testkit"
+	"avro_cli/internal/tui/nav"
+	"avro_cli/internal/tui/screens"
+	"avro_cli/internal/tui/tuitest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// historyModel returns a history screen over entries, listed newest first.
+func historyModel(t *testing.T, entries ...history.Entry) screens.HistoryModel {
+	t.Helper()
+	store := history.NewStore(testkit.NewFS(nil), "/home/avro/history.jsonl")
+	for _, e := range entries {
+		e.Time = time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+		if _, err := store.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, _ := screens.NewHistoryModel(store, tuitest.Theme()).Update(tea.WindowSizeMsg{Width: tuitest.Width, Height: tuitest.Height})
+	return m
+}
+
+func TestHistoryEnterOpensForm(t *testing.T) {
+	m := historyModel(t, history.Entry{Command: "demo hello", Args: []string{"a b"}, Flags: map[string]string{"n": "2"}, Success: true})
+
+	_, cmd := m.Update(tuitest.Key("enter"))
+	if cmd == nil {
+		t.Fatal("enter returned no command")
+	}
+	push, ok := cmd().(nav.PushScreenMsg)
+	if !ok {
+		t.Fatalf("enter produced %T, want nav.PushScreenMsg", cmd())
+	}
+	rerun, ok := push.Entry.Data.(screens.Rerun)
+	if !ok || push.Entry.Screen != nav.CommandDetailScreen {
+		t.Fatalf("pushed %+v, want the detail screen with a Rerun", push.Entry)
+	}
+	if rerun.Command.FullName() != "demo hello" || !slices.Equal(rerun.Args, []string{"a b"}) || rerun.Flags["n"] != "2" {
+		t.Errorf("Rerun = %+v", rerun)
+	}
+}
+
+func TestHistoryEnterRefuses(t *testing.T) {
+	tests := []struct {
+		name   string
+		entry  history.Entry
+		notice string
+	}{
+		{
+			name:   "redacted",
+			entry:  history.Entry{Command: "demo hello", Flags: map[string]string{"token": domain.Redacted}, Redacted: true},
+			notice: "recorded with secrets redacted",
+		},
+		{
+			name:   "unknown command",
+			entry:  history.Entry{Command: "demo gone"},
+			notice: `"demo gone" is no longer available`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, cmd := historyModel(t, tt.entry).Update(tuitest.Key("enter"))
+			if cmd != nil {
+				t.Errorf("enter returned %T, want no command", cmd())
+			}
+			if !strings.Contains(m.View(), tt.notice) {
+				t.Errorf("view lacks %q:\n%s", tt.notice, m.View())
+			}
+		})
+	}
+}
+
+func TestHistoryNavigation(t *testing.T) {
+	m := historyModel(t,
+		history.Entry{Command: "demo hello", Args: []string{"first"}, Success: true},
+		history.Entry{Command: "demo hello", Args: []string{"second"}, Error: "boom"},
+	)
+	if !strings.Contains(m.View(), "✗ ") || !strings.Contains(m.View(), "boom") {
+		t.Errorf("newest entry not selected with its error shown:\n%s", m.View())
+	}
+
+	m, _ = m.Update(tuitest.Key("down"))
+	m, _ = m.Update(tuitest.Key("down"))
+	_, cmd := m.Update(tuitest.Key("enter"))
+	if rerun := cmd().(nav.PushScreenMsg).Entry.Data.(screens.Rerun); !slices.Equal(rerun.Args, []string{"first"}) {
+		t.Errorf("opened args %q, want the oldest entry's", rerun.Args)
+	}
+}
+
+func TestHistoryDisabled(t *testing.T) {
+	m := screens.NewHistoryModel(nil, tuitest.Theme())
+	if !strings.Contains(m.View(), history.ErrDisabled.Error()) {
+		t.Errorf("view lacks the disabled error:\n%s", m.View())
+	}
+}
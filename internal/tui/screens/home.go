@@ -13,6 +13,7 @@ import (
 
 // HomeModel displays the list of command categories.
 type HomeModel struct {
+	theme      *styles.Theme
 	categories []domain.Category
 	cursor     int
 	width      int
@@ -20,8 +21,9 @@ type HomeModel struct {
 }
 
 // NewHomeModel creates the home screen.
-func NewHomeModel() HomeModel {
+func NewHomeModel(theme *styles.Theme) HomeModel {
 	return HomeModel{
+		theme:      theme,
 		categories: registry.Global().Categories(),
 	}
 }
@@ -44,6 +46,8 @@ func (m HomeModel) Update(msg tea.Msg) (HomeModel, tea.Cmd) {
 			if m.cursor < len(m.categories)-1 {
 				m.cursor++
 			}
+		case "h":
+			return m, nav.PushScreen(nav.Entry{Screen: nav.HistoryScreen, Title: "History"})
 		case "enter":
 			if len(m.categories) > 0 {
 				cat := m.categories[m.cursor]
@@ -61,8 +65,8 @@ func (m HomeModel) Update(msg tea.Msg) (HomeModel, tea.Cmd) {
 func (m HomeModel) View() string {
 	var b strings.Builder
 
-	b.WriteString(styles.Title.Render("avro") + "\n")
-	b.WriteString(styles.Description.Render("Select a category") + "\n\n")
+	b.WriteString(m.theme.Title.Render("avro") + "\n")
+	b.WriteString(m.theme.Description.Render("Select a category") + "\n\n")
 
 	for i, cat := range m.categories {
 		icon := cat.Icon
@@ -70,17 +74,17 @@ func (m HomeModel) View() string {
 			icon = ">"
 		}
 
-		line := fmt.Sprintf("%s %s  %s", icon, cat.Name, styles.Description.Render(cat.Description))
+		line := fmt.Sprintf("%s %s  %s", icon, cat.Name, m.theme.Description.Render(cat.Description))
 		if i == m.cursor {
-			b.WriteString(styles.SelectedItem.Render(line))
+			b.WriteString(m.theme.SelectedItem.Render(line))
 		} else {
-			b.WriteString(styles.NormalItem.Render(line))
+			b.WriteString(m.theme.NormalItem.Render(line))
 		}
 		b.WriteString("\n")
 	}
 
 	b.WriteString("\n")
-	b.WriteString(styles.HelpStyle.Render("j/k: navigate | enter: select | /: search | q: quit"))
+	b.WriteString(m.theme.HelpStyle.Render("j/k: navigate | enter: select | /: search | h: history | q: quit"))
 
 	return b.String()
 }
@@ -0,0 +1,66 @@
+package screens
+
+import (
+	"avro_cli/internal/app/executor"
+	"avro_cli/internal/domain"
+	"context"
+	"io"
+	"strings"
+)
+
+// resultLines combines streamed lines with the final result summary, separated
+// by a blank line when both are present.
+func resultLines(streamed []domain.OutputLine, summary string) []domain.OutputLine {
+	lines := append([]domain.OutputLine(nil), streamed...)
+	if summary == "" {
+		return lines
+	}
+	if len(lines) > 0 {
+		lines = append(lines, domain.OutputLine{})
+	}
+	for _, l := range strings.Split(summary, "\n") {
+		lines = append(lines, domain.OutputLine{Stream: domain.Stdout, Text: l})
+	}
+	return lines
+}
+
+// outputSize returns the viewport size for an output box on a screen of the
+// given size, leaving reserved rows for the surrounding chrome.
+func outputSize(width, height, reserved int) (int, int) {
+	if width <= 0 {
+		width = 80
+	}
+	if height <= 0 {
+		height = 24
+	}
+	// OutputBox: border (2) + horizontal padding (4); vertical border, padding and margin (5)
+	w := width - 6
+	h := height - reserved - 5
+	if w < 20 {
+		w = 20
+	}
+	if h < 3 {
+		h = 3
+	}
+	return w, h
+}
+
+// interactiveRun adapts an Interactive command to tea.ExecCommand so the TUI
+// releases the terminal while it runs. The command talks to the terminal
+// directly; the result is kept for the completion message.
+type interactiveRun struct {
+	exec   *executor.Executor
+	cmd    domain.CommandDescriptor
+	args   []string
+	flags  map[string]string
+	result domain.Result[string]
+}
+
+func (r *interactiveRun) Run() error {
+	r.result = r.exec.Run(context.Background(), r.cmd, r.args, r.flags)
+	return nil
+}
+
+func (r *interactiveRun) SetStdin(io.Reader)  {}
+func (r *interactiveRun) SetStdout(io.Writer) {}
+func (r *interactiveRun) SetStderr(io.Writer) {}
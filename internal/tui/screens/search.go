@@ -2,21 +2,29 @@ package screens
 
 import (
 	"avro_cli/internal/app/executor"
+	"avro_cli/internal/app/history"
 	"avro_cli/internal/app/registry"
 	"avro_cli/internal/domain"
+	"avro_cli/internal/tui/components"
 	"avro_cli/internal/tui/nav"
 	"avro_cli/internal/tui/styles"
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 // SearchModel provides a fuzzy search command palette.
 type SearchModel struct {
+	theme      *styles.Theme
 	query      string
-	results    []registry.FuzzyResult
+	results    []registry.SearchResult
+	usage      registry.Usage
+	notice     string // pinning failure, shown until the next key
 	cursor     int
 	width      int
 	height     int
@@ -24,41 +32,135 @@ type SearchModel struct {
 	exec       *executor.Executor
 
 	// inline execution state (standalone only)
+	running  bool
 	executed bool
 	output   string
 	hasError bool
+	inline   domain.CommandDescriptor
+	cancel   context.CancelFunc
+	spinner  spinner.Model
+	started  time.Time
+	elapsed  time.Duration // final run time, set when the result arrives
+	stopping bool          // cancel requested, waiting for the action to return
+	streamed []domain.OutputLine
+	lines    chan domain.OutputLine
+	result   chan domain.Result[string] // holds the run's result once lines closes
+	out      components.OutputModel
 }
 
-// NewSearchModel creates the search screen (embedded in TUI).
-func NewSearchModel() SearchModel {
+// NewSearchModel creates the search screen (embedded in TUI). Results are
+// ranked by the history and favorites of exec.
+func NewSearchModel(exec *executor.Executor, theme *styles.Theme) SearchModel {
+	usage := loadUsage(exec)
 	return SearchModel{
-		results: registry.Global().FuzzySearch(""),
+		theme:   theme,
+		results: registry.Global().RankedSearch("", usage),
+		usage:   usage,
+		exec:    exec,
 	}
 }
 
 // NewPaletteSearchModel creates the search screen in standalone palette mode.
-func NewPaletteSearchModel(exec *executor.Executor) SearchModel {
+func NewPaletteSearchModel(exec *executor.Executor, theme *styles.Theme) SearchModel {
+	usage := loadUsage(exec)
 	return SearchModel{
-		results:    registry.Global().FuzzySearch(""),
+		theme:      theme,
+		results:    registry.Global().RankedSearch("", usage),
+		usage:      usage,
 		standalone: true,
 		exec:       exec,
+		spinner:    spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(theme.Spinner)),
 	}
 }
 
 func (m SearchModel) Init() tea.Cmd { return nil }
 
+// Status summarizes inline execution progress for the status bar, or "" when
+// nothing has run.
+func (m SearchModel) Status() string {
+	name := m.inline.FullName()
+	switch {
+	case m.running && m.stopping:
+		return fmt.Sprintf("%s · cancelling %s", name, formatElapsed(time.Since(m.started)))
+	case m.running:
+		return fmt.Sprintf("%s · running %s", name, formatElapsed(time.Since(m.started)))
+	case m.executed && m.hasError:
+		return fmt.Sprintf("%s · failed after %s", name, formatElapsed(m.elapsed))
+	case m.executed:
+		return fmt.Sprintf("%s · finished in %s", name, formatElapsed(m.elapsed))
+	}
+	return ""
+}
+
+// Stop cancels a command running inline.
+func (m SearchModel) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+}
+
 func (m SearchModel) Update(msg tea.Msg) (SearchModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.out.SetSize(outputSize(m.width, m.height, 8))
+
+	case outputLineMsg:
+		// Ignore stragglers from a previous run
+		if msg.ch != m.lines {
+			return m, nil
+		}
+		m.streamed = appendLine(m.streamed, msg.line)
+		m.out.SetLines(m.streamed)
+		return m, waitForOutput(m.lines, m.result)
+
+	case spinner.TickMsg:
+		if !m.running {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case commandResultMsg:
+		if msg.ch != m.lines {
+			return m, nil
+		}
+		m.finishInline(msg.result)
 
 	case tea.KeyMsg:
-		// After inline execution, any key quits
+		if m.running {
+			if msg.String() == "esc" && m.cancel != nil {
+				m.cancel()
+				m.stopping = true
+			}
+			return m, nil
+		}
+
+		// After inline execution, scroll the output; q, enter or esc quits
 		if m.executed {
-			return m, tea.Quit
+			if m.out.Searching() {
+				var cmd tea.Cmd
+				m.out, cmd = m.out.Update(msg)
+				return m, cmd
+			}
+			switch msg.String() {
+			case "esc":
+				if m.out.HasQuery() {
+					m.out.ClearSearch()
+					return m, nil
+				}
+				return m, tea.Quit
+			case "q", "enter":
+				return m, tea.Quit
+			}
+			var cmd tea.Cmd
+			m.out, cmd = m.out.Update(msg)
+			return m, cmd
 		}
 
+		m.notice = ""
 		switch msg.String() {
 		case "esc":
 			if m.standalone {
@@ -77,9 +179,8 @@ func (m SearchModel) Update(msg tea.Msg) (SearchModel, tea.Cmd) {
 			if len(m.results) > 0 {
 				cmd := m.results[m.cursor].Command
 				// Standalone + no args = execute inline
-				if m.standalone && len(cmd.Args) == 0 && len(cmd.Flags) == 0 {
-					m.executeInline(cmd)
-					return m, nil
+				if m.standalone && len(cmd.Args) == 0 && len(cmd.Flags) == 0 && !cmd.Interactive {
+					return m, m.executeInline(cmd)
 				}
 				return m, nav.PushScreen(nav.Entry{
 					Screen: nav.CommandDetailScreen,
@@ -87,6 +188,10 @@ func (m SearchModel) Update(msg tea.Msg) (SearchModel, tea.Cmd) {
 					Data:   cmd,
 				})
 			}
+		case "ctrl+p":
+			if len(m.results) > 0 {
+				m.togglePin(m.results[m.cursor].Command.FullName())
+			}
 		case "backspace":
 			if len(m.query) > 0 {
 				m.query = m.query[:len(m.query)-1]
@@ -102,62 +207,193 @@ func (m SearchModel) Update(msg tea.Msg) (SearchModel, tea.Cmd) {
 	return m, nil
 }
 
-func (m *SearchModel) executeInline(cmd domain.CommandDescriptor) {
-	result := m.exec.Run(cmd, nil, nil)
+// executeInline starts cmd in the background, showing its output in place of
+// the results; esc cancels it via m.cancel.
+func (m *SearchModel) executeInline(cmd domain.CommandDescriptor) tea.Cmd {
+	lines, result, cancel, run := startRun(m.exec, cmd, nil, nil)
+	m.inline = cmd
+	m.cancel = cancel
+	m.running = true
+	m.started = time.Now()
+	m.streamed = nil
+	m.lines, m.result = lines, result
+	m.out = components.NewOutputModel(m.theme)
+	m.out.SetSize(outputSize(m.width, m.height, 8))
+	return tea.Batch(run, m.spinner.Tick)
+}
+
+// finishInline shows the result of the inline run.
+func (m *SearchModel) finishInline(result domain.Result[string]) {
+	m.running = false
+	m.stopping = false
+	m.cancel = nil
 	m.executed = true
+	m.elapsed = time.Since(m.started)
 	if result.IsOk() {
 		m.output = result.Value()
 		m.hasError = false
+		if m.output == "" && len(m.streamed) == 0 {
+			m.output = "(no output)"
+		}
+		m.out.SetLines(resultLines(m.streamed, m.output))
 	} else {
 		m.output = result.Err().Error()
 		m.hasError = true
+		m.out.SetLines(m.streamed)
+	}
+	// Output that arrived all at once reads from the top; streamed output stays where it was.
+	if len(m.streamed) == 0 {
+		m.out.GotoTop()
 	}
 }
 
 func (m *SearchModel) updateResults() {
-	m.results = registry.Global().FuzzySearch(m.query)
+	m.results = registry.Global().RankedSearch(m.query, m.usage)
 	m.cursor = 0
 }
 
-// highlight renders a command name with matched characters highlighted.
-var matchStyle = lipgloss.NewStyle().Foreground(styles.Secondary).Bold(true)
+// togglePin pins or unpins name and re-ranks, keeping the cursor on it.
+func (m *SearchModel) togglePin(name string) {
+	store := m.exec.Favorites
+	if store == nil {
+		m.notice = "favorites are disabled"
+		return
+	}
+	if _, err := store.Toggle(name); err != nil {
+		m.notice = err.Error()
+		return
+	}
+	m.usage.Pinned, _ = store.List()
+	m.results = registry.Global().RankedSearch(m.query, m.usage)
+	for i, r := range m.results {
+		if r.Command.FullName() == name {
+			m.cursor = i
+		}
+	}
+}
+
+// loadUsage reads the history and favorites of exec to rank results. Both
+// are best effort: a missing store or unreadable file just leaves the
+// ranking unbiased.
+func loadUsage(exec *executor.Executor) registry.Usage {
+	var (
+		entries []history.Entry
+		pinned  []string
+	)
+	if exec.History != nil {
+		entries, _ = exec.History.List()
+	}
+	if exec.Favorites != nil {
+		pinned, _ = exec.Favorites.List()
+	}
+	return registry.Usage{
+		Frecency: history.Frecency(entries, time.Now()),
+		Recent:   history.Recent(entries, 10),
+		Pinned:   pinned,
+	}
+}
 
-func highlight(text string, matchedIndexes []int) string {
+// section labels where r belongs in the empty-query listing.
+func section(r registry.SearchResult) string {
+	switch {
+	case r.Pinned:
+		return "Pinned"
+	case r.Recent:
+		return "Recent"
+	default:
+		return "All commands"
+	}
+}
+
+// highlight renders text in base with the characters at matchedIndexes (byte
+// offsets) in match.
+func highlight(text string, matchedIndexes []int, base, match lipgloss.Style) string {
 	if len(matchedIndexes) == 0 {
-		return text
+		return base.Render(text)
 	}
 	matched := make(map[int]bool, len(matchedIndexes))
 	for _, idx := range matchedIndexes {
 		matched[idx] = true
 	}
-	var b strings.Builder
+	var b, run strings.Builder
 	for i, ch := range text {
 		if matched[i] {
-			b.WriteString(matchStyle.Render(string(ch)))
+			b.WriteString(base.Render(run.String()))
+			run.Reset()
+			b.WriteString(match.Render(string(ch)))
 		} else {
-			b.WriteRune(ch)
+			run.WriteRune(ch)
 		}
 	}
+	b.WriteString(base.Render(run.String()))
 	return b.String()
 }
 
+// matchLabel names the fields that are not otherwise shown in a result line.
+var matchLabel = map[registry.Field]string{
+	registry.FieldAlias: "alias ",
+	registry.FieldTag:   "tag ",
+	registry.FieldArg:   "",
+}
+
+// resultLine renders a result as name and description, highlighting the
+// matched field and appending it when it is an alias, tag, arg or flag.
+func (m SearchModel) resultLine(r registry.SearchResult) string {
+	var nameIdx, descIdx []int
+	switch r.Field {
+	case registry.FieldName:
+		nameIdx = r.MatchedIndexes
+	case registry.FieldDescription:
+		descIdx = r.MatchedIndexes
+	}
+	fullName := r.Command.FullName()
+	line := highlight(fullName, nameIdx, lipgloss.NewStyle(), m.theme.Match)
+	// Pad highlighted name to fixed width
+	if n := len(fullName); n < 24 {
+		line += strings.Repeat(" ", 24-n)
+	}
+	line += " " + highlight(r.Command.Description, descIdx, m.theme.Description, m.theme.Match)
+	if label, ok := matchLabel[r.Field]; ok {
+		line += m.theme.Description.Render("  · "+label) + highlight(r.Text, r.MatchedIndexes, m.theme.Description, m.theme.Match)
+	}
+	return line
+}
+
 func (m SearchModel) View() string {
 	var b strings.Builder
 
+	if m.running {
+		b.WriteString(m.theme.Subtitle.Render("Command Palette") + "\n\n")
+		if len(m.streamed) > 0 {
+			b.WriteString(m.theme.OutputBox.Render(m.out.View()))
+			b.WriteString("\n")
+		}
+		label := "Running " + m.inline.FullName() + "..."
+		if m.stopping {
+			label = "Cancelling..."
+		}
+		b.WriteString(m.spinner.View() + " " + m.theme.Description.Render(label))
+		b.WriteString("\n\n")
+		b.WriteString(m.theme.HelpStyle.Render("esc: cancel"))
+		return b.String()
+	}
+
 	// Show inline execution result
 	if m.executed {
-		b.WriteString(styles.Subtitle.Render("Command Palette") + "\n\n")
+		b.WriteString(m.theme.Subtitle.Render("Command Palette") + "\n\n")
 		if m.hasError {
-			b.WriteString(styles.ErrorText.Render("Error: ") + m.output)
-		} else {
-			outputView := m.output
-			if outputView == "" {
-				outputView = "(no output)"
+			if len(m.streamed) > 0 {
+				b.WriteString(m.theme.OutputBox.Render(m.out.View()))
+				b.WriteString("\n")
 			}
-			b.WriteString(styles.OutputBox.Render(outputView))
+			b.WriteString(m.theme.ErrorText.Render("Error: ") + m.output)
+			b.WriteString("\n\n")
+			b.WriteString(m.theme.HelpStyle.Render("press q to exit"))
+			return b.String()
 		}
+		b.WriteString(m.theme.OutputBox.Render(m.out.View()))
 		b.WriteString("\n\n")
-		b.WriteString(styles.HelpStyle.Render("press any key to exit"))
+		b.WriteString(m.theme.HelpStyle.Render("q: exit | " + components.OutputHelp))
 		return b.String()
 	}
 
@@ -165,12 +401,17 @@ func (m SearchModel) View() string {
 	if m.standalone {
 		title = "Command Palette"
 	}
-	b.WriteString(styles.Subtitle.Render(title) + "\n\n")
+	b.WriteString(m.theme.Subtitle.Render(title) + "\n\n")
 	b.WriteString(fmt.Sprintf("  > %s_\n\n", m.query))
 
+	// An empty query groups results under up to three section headings.
+	sections := m.query == ""
 	maxVisible := 15
 	if m.height > 0 {
 		maxVisible = m.height - 8
+		if sections {
+			maxVisible -= 3
+		}
 		if maxVisible < 5 {
 			maxVisible = 5
 		}
@@ -188,32 +429,36 @@ func (m SearchModel) View() string {
 
 	for i := start; i < end; i++ {
 		r := m.results[i]
-		name := highlight(r.Command.FullName(), r.MatchedIndexes)
-		// Pad highlighted name to fixed width
-		nameLen := len(r.Command.FullName())
-		padding := ""
-		if nameLen < 24 {
-			padding = strings.Repeat(" ", 24-nameLen)
-		}
-		line := name + padding + " " + styles.Description.Render(r.Command.Description)
+		if sections && (i == start || section(r) != section(m.results[i-1])) {
+			b.WriteString(m.theme.Description.Render("  "+section(r)) + "\n")
+		}
+		pin := "  "
+		if r.Pinned {
+			pin = "★ "
+		}
+		line := pin + m.resultLine(r)
 		if i == m.cursor {
-			b.WriteString(styles.SelectedItem.Render("") + line)
+			b.WriteString(m.theme.SelectedItem.Render("") + line)
 		} else {
-			b.WriteString(styles.NormalItem.Render("") + line)
+			b.WriteString(m.theme.NormalItem.Render("") + line)
 		}
 		b.WriteString("\n")
 	}
 
 	if len(m.results) == 0 {
-		b.WriteString(styles.Description.Render("  No commands found") + "\n")
+		b.WriteString(m.theme.Description.Render("  No commands found") + "\n")
+	}
+
+	if m.notice != "" {
+		b.WriteString("\n" + m.theme.ErrorText.Render("  "+m.notice) + "\n")
 	}
 
 	b.WriteString("\n")
-	helpText := "type to search | up/down: navigate | enter: select | esc: back"
+	helpText := "type to search | up/down: navigate | enter: select | ctrl+p: pin | esc: back"
 	if m.standalone {
-		helpText = "type to search | up/down: navigate | enter: select | esc: quit"
+		helpText = "type to search | up/down: navigate | enter: select | ctrl+p: pin | esc: quit"
 	}
-	b.WriteString(styles.HelpStyle.Render(helpText))
+	b.WriteString(m.theme.HelpStyle.Render(helpText))
 
 	return b.String()
 }
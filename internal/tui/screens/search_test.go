@@ -0,0 +1,197 @@
+package screens_test
+
+import (
+	"avro_cli/internal/app/favorites"
+	"avro_cli/internal/app/registry"
+	"avro_cli/internal/domain"
+	"avro_cli/internal/testkit"
+	"avro_cli/internal/tui/screens"
+	"avro_cli/internal/tui/tuitest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func init() {
+	demo := domain.Category{Name: "demo", Description: "Demo commands"}
+	registry.Global().Register(
+		domain.CommandDescriptor{
+			Category:    demo,
+			Name:        "hello",
+			Description: "Print a greeting",
+			Action: func(ctx domain.CommandContext) domain.Result[string] {
+				ctx.Output.Stdout.Write([]byte("hello, screens\n"))
+				return domain.Ok("")
+			},
+		},
+		domain.CommandDescriptor{
+			Category:    demo,
+			Name:        "wait",
+			Description: "Block until cancelled",
+			Action: func(ctx domain.CommandContext) domain.Result[string] {
+				<-ctx.Context.Done()
+				return domain.Fail[string](ctx.Context.Err())
+			},
+		},
+	)
+}
+
+// driver runs the commands a screen returns, as a program would, and hands
+// their messages back one at a time. Spinner ticks are dropped.
+type driver struct {
+	t    *testing.T
+	msgs chan tea.Msg
+}
+
+func newDriver(t *testing.T) *driver {
+	return &driver{t: t, msgs: make(chan tea.Msg, 16)}
+}
+
+func (d *driver) run(cmd tea.Cmd) {
+	if cmd != nil {
+		go d.deliver(cmd())
+	}
+}
+
+func (d *driver) deliver(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case nil, spinner.TickMsg:
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			d.run(cmd)
+		}
+	default:
+		d.msgs <- msg
+	}
+}
+
+func (d *driver) next() tea.Msg {
+	d.t.Helper()
+	select {
+	case msg := <-d.msgs:
+		return msg
+	case <-time.After(5 * time.Second):
+		d.t.Fatal("no message from the running command")
+		return nil
+	}
+}
+
+func typeText(m screens.SearchModel, s string) screens.SearchModel {
+	for _, r := range s {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+// finish feeds messages to m until its inline run has a result.
+func finish(d *driver, m screens.SearchModel) screens.SearchModel {
+	d.t.Helper()
+	for strings.Contains(m.Status(), "ing ") {
+		var cmd tea.Cmd
+		m, cmd = m.Update(d.next())
+		d.run(cmd)
+	}
+	return m
+}
+
+func TestPaletteRunsInBackground(t *testing.T) {
+	env := testkit.New(t)
+	d := newDriver(t)
+	m := screens.NewPaletteSearchModel(env.Executor, tuitest.Theme())
+
+	m = typeText(m, "hello")
+	m, cmd := m.Update(tuitest.Key("enter"))
+	if cmd == nil {
+		t.Fatal("enter returned no command to run")
+	}
+	if !strings.Contains(m.View(), "Running demo hello...") {
+		t.Errorf("view while running:\n%s", m.View())
+	}
+	d.run(cmd)
+
+	m = finish(d, m)
+	if !strings.Contains(m.View(), "hello, screens") {
+		t.Errorf("output missing after run:\n%s", m.View())
+	}
+	if got := m.Status(); !strings.HasPrefix(got, "demo hello · finished in ") {
+		t.Errorf("Status() = %q", got)
+	}
+}
+
+func TestPaletteEscCancelsRun(t *testing.T) {
+	env := testkit.New(t)
+	d := newDriver(t)
+	m := screens.NewPaletteSearchModel(env.Executor, tuitest.Theme())
+
+	m = typeText(m, "wait")
+	m, cmd := m.Update(tuitest.Key("enter"))
+	d.run(cmd)
+
+	m, cmd = m.Update(tuitest.Key("esc"))
+	if cmd != nil {
+		t.Error("esc while running returned a command; want it to cancel only")
+	}
+	if !strings.Contains(m.View(), "Cancelling...") {
+		t.Errorf("view after esc:\n%s", m.View())
+	}
+
+	m = finish(d, m)
+	if view := m.View(); !strings.Contains(view, "Error:") || !strings.Contains(view, "canceled") {
+		t.Errorf("view after cancel:\n%s", view)
+	}
+	if got := m.Status(); !strings.HasPrefix(got, "demo wait · failed after ") {
+		t.Errorf("Status() = %q", got)
+	}
+}
+
+func TestSearchPin(t *testing.T) {
+	env := testkit.New(t)
+	store := favorites.NewStore(env.FS, "/home/avro/favorites.yaml")
+	env.Executor.Favorites = store
+	m := screens.NewSearchModel(env.Executor, tuitest.Theme())
+
+	m = typeText(m, "wait")
+	m, _ = m.Update(tuitest.Key("ctrl+p"))
+	if names, _ := store.List(); !slices.Equal(names, []string{"demo wait"}) {
+		t.Errorf("pinned %q, want demo wait", names)
+	}
+	if !strings.Contains(m.View(), "★ demo wait") {
+		t.Errorf("pinned result not starred:\n%s", m.View())
+	}
+
+	// Reopened with an empty query, the pin leads under its own heading.
+	m = screens.NewSearchModel(env.Executor, tuitest.Theme())
+	view := m.View()
+	pinned, star, all := strings.Index(view, "Pinned"), strings.Index(view, "★ demo wait"), strings.Index(view, "All commands")
+	if pinned < 0 || star < pinned || all < star {
+		t.Errorf("pin not listed first under Pinned:\n%s", view)
+	}
+	if _, cmd := m.Update(tuitest.Key("enter")); cmd == nil {
+		t.Error("enter on the pinned command did nothing")
+	}
+
+	m, _ = m.Update(tuitest.Key("ctrl+p"))
+	if names, _ := store.List(); len(names) != 0 {
+		t.Errorf("still pinned after a second ctrl+p: %q", names)
+	}
+	if strings.Contains(m.View(), "★") {
+		t.Errorf("unpinned result still starred:\n%s", m.View())
+	}
+}
+
+func TestSearchPinDisabled(t *testing.T) {
+	env := testkit.New(t)
+	m := screens.NewSearchModel(env.Executor, tuitest.Theme())
+	m, _ = m.Update(tuitest.Key("ctrl+p"))
+	if !strings.Contains(m.View(), "favorites are disabled") {
+		t.Errorf("no notice without a favorites store:\n%s", m.View())
+	}
+	m = typeText(m, "h")
+	if strings.Contains(m.View(), "favorites are disabled") {
+		t.Errorf("notice kept after the next key:\n%s", m.View())
+	}
+}
@@ -0,0 +1,100 @@
+package styles
+
+import "github.com/charmbracelet/lipgloss"
+
+// builtin pairs a dark-background palette with an optional light-background
+// variant, chosen by the detected terminal background.
+type builtin struct {
+	dark  Palette
+	light *Palette
+}
+
+var lightPalette = Palette{
+	Primary:   lipgloss.Color("#6D28D9"),
+	Secondary: lipgloss.Color("#0E7490"),
+	Success:   lipgloss.Color("#047857"),
+	Warning:   lipgloss.Color("#B45309"),
+	Error:     lipgloss.Color("#B91C1C"),
+	Muted:     lipgloss.Color("#6B7280"),
+	StatusFg:  lipgloss.Color("#111827"),
+	StatusBg:  lipgloss.Color("#E5E7EB"),
+	MatchFg:   lipgloss.Color("#FFFFFF"),
+}
+
+var solarizedLight = Palette{
+	Primary:   lipgloss.Color("#6C71C4"),
+	Secondary: lipgloss.Color("#2AA198"),
+	Success:   lipgloss.Color("#859900"),
+	Warning:   lipgloss.Color("#B58900"),
+	Error:     lipgloss.Color("#DC322F"),
+	Muted:     lipgloss.Color("#93A1A1"),
+	StatusFg:  lipgloss.Color("#586E75"),
+	StatusBg:  lipgloss.Color("#EEE8D5"),
+	MatchFg:   lipgloss.Color("#FDF6E3"),
+}
+
+// builtins holds the shipped palettes; "monochrome" is built by Monochrome.
+var builtins = map[string]builtin{
+	"default": {
+		dark: Palette{
+			Primary:   lipgloss.Color("#7C3AED"),
+			Secondary: lipgloss.Color("#06B6D4"),
+			Success:   lipgloss.Color("#10B981"),
+			Warning:   lipgloss.Color("#F59E0B"),
+			Error:     lipgloss.Color("#EF4444"),
+			Muted:     lipgloss.Color("#6B7280"),
+			StatusFg:  lipgloss.Color("#FFFFFF"),
+			StatusBg:  lipgloss.Color("#1F2937"),
+			MatchFg:   lipgloss.Color("#000000"),
+		},
+		light: &lightPalette,
+	},
+	"light": {dark: lightPalette},
+	"high-contrast": {
+		// ANSI colors so the terminal's own high-contrast scheme applies.
+		dark: Palette{
+			Primary:   lipgloss.Color("13"),
+			Secondary: lipgloss.Color("14"),
+			Success:   lipgloss.Color("10"),
+			Warning:   lipgloss.Color("11"),
+			Error:     lipgloss.Color("9"),
+			Muted:     lipgloss.Color("15"),
+			StatusFg:  lipgloss.Color("0"),
+			StatusBg:  lipgloss.Color("15"),
+			MatchFg:   lipgloss.Color("0"),
+		},
+		light: &Palette{
+			Primary:   lipgloss.Color("5"),
+			Secondary: lipgloss.Color("4"),
+			Success:   lipgloss.Color("2"),
+			Warning:   lipgloss.Color("3"),
+			Error:     lipgloss.Color("1"),
+			Muted:     lipgloss.Color("0"),
+			StatusFg:  lipgloss.Color("15"),
+			StatusBg:  lipgloss.Color("0"),
+			MatchFg:   lipgloss.Color("15"),
+		},
+	},
+	"solarized": {
+		dark: Palette{
+			Primary:   lipgloss.Color("#6C71C4"),
+			Secondary: lipgloss.Color("#2AA198"),
+			Success:   lipgloss.Color("#859900"),
+			Warning:   lipgloss.Color("#B58900"),
+			Error:     lipgloss.Color("#DC322F"),
+			Muted:     lipgloss.Color("#586E75"),
+			StatusFg:  lipgloss.Color("#93A1A1"),
+			StatusBg:  lipgloss.Color("#073642"),
+			MatchFg:   lipgloss.Color("#002B36"),
+		},
+		light: &solarizedLight,
+	},
+}
+
+// palette returns the variant of b for the terminal background.
+func (b builtin) palette(dark bool) Palette {
+	if !dark && b.light != nil {
+		return *b.light
+	}
+	return b.dark
+}
@@ -0,0 +1,174 @@
+package styles
+
+import (
+	"avro_cli/internal/config"
+	"avro_cli/internal/domain"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"go.yaml.in/yaml/v3"
+)
+
+// ThemeFile is the schema of a user theme, ~/.avro/themes/<name>.yaml. The
+// theme is named after the file and starts from a built-in:
+//
+//	base: solarized     # built-in to start from (default: default)
+//	colors:             # overrides on any background
+//	  primary: "#D33682"
+//	  muted: "245"      # ANSI 0-255 or #RGB/#RRGGBB
+//	light:              # further overrides when the terminal background is light
+//	  muted: "240"
+//
+// Color keys: primary, secondary, success, warning, error, muted, status_fg,
+// status_bg, match_fg.
+type ThemeFile struct {
+	Base   string            `yaml:"base"`
+	Colors map[string]string `yaml:"colors"`
+	Light  map[string]string `yaml:"light"`
+}
+
+// colorKeys maps ThemeFile color keys to palette fields.
+var colorKeys = map[string]func(*Palette) *lipgloss.TerminalColor{
+	"primary":   func(p *Palette) *lipgloss.TerminalColor { return &p.Primary },
+	"secondary": func(p *Palette) *lipgloss.TerminalColor { return &p.Secondary },
+	"success":   func(p *Palette) *lipgloss.TerminalColor { return &p.Success },
+	"warning":   func(p *Palette) *lipgloss.TerminalColor { return &p.Warning },
+	"error":     func(p *Palette) *lipgloss.TerminalColor { return &p.Error },
+	"muted":     func(p *Palette) *lipgloss.TerminalColor { return &p.Muted },
+	"status_fg": func(p *Palette) *lipgloss.TerminalColor { return &p.StatusFg },
+	"status_bg": func(p *Palette) *lipgloss.TerminalColor { return &p.StatusBg },
+	"match_fg":  func(p *Palette) *lipgloss.TerminalColor { return &p.MatchFg },
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Dir returns the directory searched for user themes (~/.avro/themes).
+func Dir() string {
+	return config.ThemesDir()
+}
+
+// Names lists the built-in themes followed by user themes found in Dir()
+// on files.
+func Names(files domain.FileSystem) []string {
+	return config.ThemeNames(files)
+}
+
+// Load returns the theme to render with. NO_COLOR forces Monochrome;
+// otherwise name is a built-in or a user theme in Dir() on files, with light
+// or dark variants picked from the terminal background. An unknown or invalid
+// theme yields the default theme together with the error.
+func Load(files domain.FileSystem, name string) (*Theme, error) {
+	if termenv.EnvNoColor() {
+		return Monochrome(), nil
+	}
+	dark := termenv.HasDarkBackground()
+	t, err := Lookup(files, name, dark)
+	if err != nil {
+		fallback, _ := Lookup(files, "default", dark)
+		return fallback, err
+	}
+	return t, nil
+}
+
+// Lookup builds the named theme for a dark or light terminal background,
+// reading user themes from files. Built-in themes never touch files.
+func Lookup(files domain.FileSystem, name string, dark bool) (*Theme, error) {
+	if name == "monochrome" {
+		return Monochrome(), nil
+	}
+	if b, ok := builtins[name]; ok {
+		return New(name, b.palette(dark)), nil
+	}
+
+	path, err := config.ThemeFile(name)
+	if err != nil {
+		return nil, err
+	}
+	p, err := loadFile(files, path, dark)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &domain.ValidationError{
+			Field:   "theme",
+			Message: fmt.Sprintf("unknown theme %q (expected %s, or a file in %s)", name, strings.Join(Names(files), ", "), Dir()),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("theme %s: %w", path, err)
+	}
+	return New(name, p), nil
+}
+
+// loadFile reads a user theme and resolves its palette.
+func loadFile(files domain.FileSystem, path string, dark bool) (Palette, error) {
+	data, err := files.ReadFile(path)
+	if err != nil {
+		return Palette{}, err
+	}
+	var f ThemeFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return Palette{}, err
+	}
+
+	base := f.Base
+	if base == "" {
+		base = "default"
+	}
+	b, ok := builtins[base]
+	if !ok {
+		return Palette{}, &domain.ValidationError{
+			Field:   "base",
+			Message: fmt.Sprintf("unknown base %q (expected default, light, high-contrast or solarized)", base),
+		}
+	}
+
+	p := b.palette(dark)
+	if err := applyColors(&p, f.Colors); err != nil {
+		return Palette{}, err
+	}
+	if !dark {
+		if err := applyColors(&p, f.Light); err != nil {
+			return Palette{}, err
+		}
+	}
+	return p, nil
+}
+
+// applyColors overrides palette fields, validating keys and color values.
+func applyColors(p *Palette, colors map[string]string) error {
+	for key, value := range colors {
+		field, ok := colorKeys[key]
+		if !ok {
+			keys := make([]string, 0, len(colorKeys))
+			for k := range colorKeys {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			return &domain.ValidationError{
+				Field:   key,
+				Message: fmt.Sprintf("unknown color (expected %s)", strings.Join(keys, ", ")),
+			}
+		}
+		if !validColor(value) {
+			return &domain.ValidationError{
+				Field:   key,
+				Message: fmt.Sprintf("%q is not a color (use #RRGGBB, #RGB or an ANSI number 0-255)", value),
+			}
+		}
+		*field(p) = lipgloss.Color(value)
+	}
+	return nil
+}
+
+func validColor(s string) bool {
+	if hexColor.MatchString(s) {
+		return true
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
@@ -2,63 +2,129 @@ package styles
 
 import "github.com/charmbracelet/lipgloss"
 
-var (
-	// Colors
-	Primary   = lipgloss.Color("#7C3AED")
-	Secondary = lipgloss.Color("#06B6D4")
-	Success   = lipgloss.Color("#10B981")
-	Warning   = lipgloss.Color("#F59E0B")
-	Error     = lipgloss.Color("#EF4444")
-	Muted     = lipgloss.Color("#6B7280")
-	BgDark    = lipgloss.Color("#1F2937")
+// Palette is the set of colors a theme is built from.
+type Palette struct {
+	Primary   lipgloss.TerminalColor
+	Secondary lipgloss.TerminalColor
+	Success   lipgloss.TerminalColor
+	Warning   lipgloss.TerminalColor
+	Error     lipgloss.TerminalColor
+	Muted     lipgloss.TerminalColor
+
+	StatusFg lipgloss.TerminalColor // status bar text
+	StatusBg lipgloss.TerminalColor // status bar background
+	MatchFg  lipgloss.TerminalColor // text on the highlighted current match
+}
+
+// Theme holds every style the TUI renders with. Screens and components
+// receive the active theme rather than reading package state.
+type Theme struct {
+	Name    string
+	Palette Palette
 
 	// Text styles
-	Title = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(Primary).
-		MarginBottom(1)
+	Title       lipgloss.Style
+	Subtitle    lipgloss.Style
+	Description lipgloss.Style
+	ErrorText   lipgloss.Style
+	SuccessText lipgloss.Style
 
-	Subtitle = lipgloss.NewStyle().
-			Foreground(Secondary).
-			Bold(true)
+	// Layout
+	Breadcrumb lipgloss.Style
+	StatusBar  lipgloss.Style
 
-	Description = lipgloss.NewStyle().
-			Foreground(Muted)
+	// List items
+	SelectedItem lipgloss.Style
+	NormalItem   lipgloss.Style
 
-	ErrorText = lipgloss.NewStyle().
-			Foreground(Error).
-			Bold(true)
+	// Containers
+	OutputBox lipgloss.Style
 
-	SuccessText = lipgloss.NewStyle().
-			Foreground(Success)
+	// Search matches, in command output and palette results
+	Match        lipgloss.Style
+	CurrentMatch lipgloss.Style
 
-	// Layout
-	Breadcrumb = lipgloss.NewStyle().
-			Foreground(Muted).
-			MarginBottom(1)
+	Spinner   lipgloss.Style
+	HelpStyle lipgloss.Style
+}
 
-	StatusBar = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(BgDark).
-			Padding(0, 1)
+// New builds a theme from a palette.
+func New(name string, p Palette) *Theme {
+	return &Theme{
+		Name:    name,
+		Palette: p,
 
-	// List items
-	SelectedItem = lipgloss.NewStyle().
-			Foreground(Primary).
+		Title: lipgloss.NewStyle().
 			Bold(true).
-			PaddingLeft(2)
+			Foreground(p.Primary).
+			MarginBottom(1),
 
-	NormalItem = lipgloss.NewStyle().
-			PaddingLeft(2)
+		Subtitle: lipgloss.NewStyle().
+			Foreground(p.Secondary).
+			Bold(true),
 
-	// Containers
-	OutputBox = lipgloss.NewStyle().
+		Description: lipgloss.NewStyle().
+			Foreground(p.Muted),
+
+		ErrorText: lipgloss.NewStyle().
+			Foreground(p.Error).
+			Bold(true),
+
+		SuccessText: lipgloss.NewStyle().
+			Foreground(p.Success),
+
+		Breadcrumb: lipgloss.NewStyle().
+			Foreground(p.Muted).
+			MarginBottom(1),
+
+		StatusBar: lipgloss.NewStyle().
+			Foreground(p.StatusFg).
+			Background(p.StatusBg).
+			Padding(0, 1),
+
+		SelectedItem: lipgloss.NewStyle().
+			Foreground(p.Primary).
+			Bold(true).
+			PaddingLeft(2),
+
+		NormalItem: lipgloss.NewStyle().
+			PaddingLeft(2),
+
+		OutputBox: lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(Muted).
+			BorderForeground(p.Muted).
 			Padding(1, 2).
-			MarginTop(1)
+			MarginTop(1),
+
+		Match: lipgloss.NewStyle().
+			Foreground(p.Secondary).
+			Bold(true),
+
+		CurrentMatch: lipgloss.NewStyle().
+			Foreground(p.MatchFg).
+			Background(p.Warning).
+			Bold(true),
+
+		Spinner: lipgloss.NewStyle().
+			Foreground(p.Secondary),
+
+		HelpStyle: lipgloss.NewStyle().
+			Foreground(p.Muted).
+			MarginTop(1),
+	}
+}
 
-	HelpStyle = lipgloss.NewStyle().
-			Foreground(Muted).
-			MarginTop(1)
-)
+// Monochrome builds a colorless theme that marks selection and matches with
+// reverse video and underlines. It is forced when NO_COLOR is set.
+func Monochrome() *Theme {
+	none := lipgloss.NoColor{}
+	t := New("monochrome", Palette{
+		Primary: none, Secondary: none, Success: none, Warning: none, Error: none,
+		Muted: none, StatusFg: none, StatusBg: none, MatchFg: none,
+	})
+	t.SelectedItem = t.SelectedItem.Reverse(true)
+	t.StatusBar = t.StatusBar.Reverse(true)
+	t.Match = t.Match.Underline(true)
+	t.CurrentMatch = t.CurrentMatch.Reverse(true)
+	return t
+}
@@ -0,0 +1,342 @@
+// Package tuitest drives Bubble Tea models without a terminal and compares
+// what they render against golden files, so TUI regressions show up as diffs:
+//
+//	func TestSearch(t *testing.T) {
+//		h := tuitest.App(t, exec)
+//		h.Press("/").Type("stat").Golden("search_stat")
+//		h.Press("enter").Golden("status_detail")
+//	}
+//
+// Golden files live in testdata/<name>.golden next to the test. Set Update
+// to write them after an intended change, e.g. from a flag of the test
+// package:
+//
+//	func init() {
+//		flag.BoolVar(&tuitest.Update, "update", false, "rewrite golden files")
+//	}
+package tuitest
+
+import (
+	"avro_cli/internal/app/executor"
+	"avro_cli/internal/tui"
+	"avro_cli/internal/tui/styles"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+// Update makes Golden and GoldenANSI rewrite golden files with the current
+// view instead of comparing against them.
+var Update bool
+
+// Default window size for a new harness.
+const (
+	Width  = 80
+	Height = 24
+)
+
+// maxMessages bounds the messages handled per input, so a model that keeps
+// producing messages cannot hang a test.
+const maxMessages = 1000
+
+// Harness feeds input to a model and captures its View.
+//
+// After each input it runs the commands the model returns, as a program
+// would: batch members concurrently, sequence members in order, feeding
+// every message back to the model until no command is left running. The
+// ticks of spinners and blinking cursors are dropped rather than waited for,
+// so animations stay on their first frame and views do not depend on timing.
+type Harness struct {
+	// CmdTimeout bounds how long the commands started by one input may run;
+	// the test fails if any is still running after it.
+	CmdTimeout time.Duration
+
+	t       testing.TB
+	model   tea.Model
+	quit    bool
+	results chan result
+	pending int // commands started and not yet finished
+}
+
+// result is a message produced by a running command. A sequence produces
+// several; only the last (done) marks the command finished.
+type result struct {
+	msg  tea.Msg
+	done bool
+}
+
+// New returns a harness driving m, initialized and sized to Width x Height.
+// History and favorites come from the executor the model was built with;
+// leave them unset, or keep them on the executor's in-memory file system,
+// to start empty.
+func New(t testing.TB, m tea.Model) *Harness {
+	t.Helper()
+	h := &Harness{CmdTimeout: 5 * time.Second, t: t, model: m, results: make(chan result)}
+	h.start(m.Init())
+	h.settle()
+	return h.Resize(Width, Height)
+}
+
+// App returns a harness driving the full TUI with the default dark theme.
+func App(t testing.TB, exec *executor.Executor) *Harness {
+	t.Helper()
+	return New(t, tui.Model(exec, Theme()))
+}
+
+// Palette returns a harness driving the TUI in palette mode.
+func Palette(t testing.TB, exec *executor.Executor) *Harness {
+	t.Helper()
+	return New(t, tui.PaletteModel(exec, Theme()))
+}
+
+// Theme returns the default dark theme, independent of the terminal and of
+// any user configuration.
+func Theme() *styles.Theme {
+	theme, _ := styles.Lookup(nil, "default", true)
+	return theme
+}
+
+// ForceColor makes lipgloss emit true-color escape sequences for the rest of
+// the test, as it does not when output is not a terminal. Use it before
+// building models whose colors GoldenANSI should capture.
+func ForceColor(t testing.TB) {
+	prev := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(func() { lipgloss.SetColorProfile(prev) })
+}
+
+// Model returns the current model, e.g. to inspect its state.
+func (h *Harness) Model() tea.Model { return h.model }
+
+// Quit reports whether the model has asked the program to quit.
+func (h *Harness) Quit() bool { return h.quit }
+
+// Send delivers msg to the model, then every message the commands it
+// returns produce.
+func (h *Harness) Send(msg tea.Msg) *Harness {
+	h.t.Helper()
+	h.handle(msg)
+	h.settle()
+	return h
+}
+
+// Resize sends a window size.
+func (h *Harness) Resize(width, height int) *Harness {
+	h.t.Helper()
+	return h.Send(tea.WindowSizeMsg{Width: width, Height: height})
+}
+
+// Press sends keys by name, as tea.KeyMsg.String reports them: "enter",
+// "esc", "down", "ctrl+p", "alt+x", or a single character such as "q".
+func (h *Harness) Press(keys ...string) *Harness {
+	h.t.Helper()
+	for _, k := range keys {
+		h.Send(Key(k))
+	}
+	return h
+}
+
+// Type sends each rune of s as a key press.
+func (h *Harness) Type(s string) *Harness {
+	h.t.Helper()
+	for _, r := range s {
+		h.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return h
+}
+
+// View returns the rendered view with escape sequences and trailing spaces
+// removed, the form most golden files compare against.
+func (h *Harness) View() string {
+	lines := strings.Split(ansi.Strip(h.model.View()), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RawView returns the rendered view as the terminal would receive it.
+func (h *Harness) RawView() string { return h.model.View() }
+
+// Golden compares View with testdata/<name>.golden.
+func (h *Harness) Golden(name string) *Harness {
+	h.t.Helper()
+	compare(h.t, name, h.View())
+	return h
+}
+
+// GoldenANSI compares RawView with testdata/<name>.golden, so changes to
+// colors and styles fail too. See ForceColor.
+func (h *Harness) GoldenANSI(name string) *Harness {
+	h.t.Helper()
+	compare(h.t, name, h.RawView())
+	return h
+}
+
+// Key returns the tea.KeyMsg for a key name; see Harness.Press.
+func Key(name string) tea.KeyMsg {
+	alt := false
+	if rest, ok := strings.CutPrefix(name, "alt+"); ok && rest != "" {
+		alt, name = true, rest
+	}
+	if t, ok := keyTypes[name]; ok {
+		return tea.KeyMsg{Type: t, Alt: alt}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name), Alt: alt}
+}
+
+// keyTypes maps key names to their tea.KeyType.
+var keyTypes = func() map[string]tea.KeyType {
+	m := make(map[string]tea.KeyType)
+	for k := tea.KeyType(-100); k <= 127; k++ {
+		if s := k.String(); s != "" && k != tea.KeyRunes {
+			if _, dup := m[s]; !dup {
+				m[s] = k
+			}
+		}
+	}
+	return m
+}()
+
+var cmdType = reflect.TypeOf(tea.Cmd(nil))
+
+// handle delivers msg to the model and starts the command it returns.
+// Batches and sequences are run rather than delivered, as a program does.
+func (h *Harness) handle(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case nil, spinner.TickMsg, cursor.BlinkMsg:
+		return
+	case tea.QuitMsg:
+		h.quit = true
+		return
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			h.start(cmd)
+		}
+		return
+	}
+	// tea.Sequence's message type is unexported; like tea.BatchMsg it is a
+	// slice of commands.
+	if v := reflect.ValueOf(msg); v.Kind() == reflect.Slice && v.Type().Elem() == cmdType {
+		cmds := make([]tea.Cmd, v.Len())
+		for i := range cmds {
+			cmds[i] = v.Index(i).Interface().(tea.Cmd)
+		}
+		h.sequence(cmds)
+		return
+	}
+
+	var cmd tea.Cmd
+	h.model, cmd = h.model.Update(msg)
+	h.start(cmd)
+}
+
+// start runs cmd in the background.
+func (h *Harness) start(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	h.pending++
+	go func() { h.results <- result{msg: cmd(), done: true} }()
+}
+
+// sequence runs cmds one after another in the background, passing on each
+// message before starting the next command.
+func (h *Harness) sequence(cmds []tea.Cmd) {
+	h.pending++
+	go func() {
+		for _, cmd := range cmds {
+			if cmd != nil {
+				h.results <- result{msg: cmd()}
+			}
+		}
+		h.results <- result{done: true}
+	}()
+}
+
+// settle handles the messages of running commands until none is left.
+func (h *Harness) settle() {
+	h.t.Helper()
+	timeout := time.After(h.CmdTimeout)
+	for n := 0; h.pending > 0; n++ {
+		if n == maxMessages {
+			h.t.Fatalf("tuitest: model still producing messages after %d", maxMessages)
+		}
+		select {
+		case r := <-h.results:
+			if r.done {
+				h.pending--
+			}
+			h.handle(r.msg)
+		case <-timeout:
+			h.t.Fatalf("tuitest: %d command(s) still running after %v", h.pending, h.CmdTimeout)
+		}
+	}
+}
+
+// compare fails t when got differs from the golden file name, or rewrites
+// the file when Update is set.
+func compare(t testing.TB, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if Update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (set tuitest.Update to create it)", err)
+	}
+	if d := diff(string(want), got); d != "" {
+		t.Errorf("%s differs from the view (-want +got):\n%s", path, d)
+	}
+}
+
+// diff returns the differing lines of want and got, or "" if they are equal.
+func diff(want, got string) string {
+	if want == got {
+		return ""
+	}
+	wl, gl := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var b strings.Builder
+	for i := 0; i < max(len(wl), len(gl)); i++ {
+		var w, g string
+		hasW, hasG := i < len(wl), i < len(gl)
+		if hasW {
+			w = wl[i]
+		}
+		if hasG {
+			g = gl[i]
+		}
+		if hasW && hasG && w == g {
+			continue
+		}
+		if hasW {
+			b.WriteString(lineLabel(i) + "-" + w + "\n")
+		}
+		if hasG {
+			b.WriteString(lineLabel(i) + "+" + g + "\n")
+		}
+	}
+	return b.String()
+}
+
+func lineLabel(i int) string {
+	return fmt.Sprintf("%4d ", i+1)
+}
@@ -0,0 +1,150 @@
+package tuitest_test
+
+import (
+	"avro_cli/internal/app/registry"
+	"avro_cli/internal/domain"
+	"avro_cli/internal/testkit"
+	"avro_cli/internal/tui/tuitest"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func init() {
+	flag.BoolVar(&tuitest.Update, "update", false, "rewrite golden files")
+
+	// The TUI lists the global registry; give it one command to show.
+	registry.Global().Register(domain.CommandDescriptor{
+		Category:    domain.Category{Name: "demo", Description: "Demo commands"},
+		Name:        "hello",
+		Description: "Print a greeting",
+		Action: func(ctx domain.CommandContext) domain.Result[string] {
+			ctx.Output.Stdout.Write([]byte("hello, tuitest\n"))
+			return domain.Ok("")
+		},
+	})
+}
+
+// logModel appends every string message it receives to its view and runs
+// the command returned by next for it, if any.
+type logModel struct {
+	log  []string
+	init tea.Cmd
+	next func(msg string) tea.Cmd
+}
+
+func (m logModel) Init() tea.Cmd { return m.init }
+
+func (m logModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case string:
+		m.log = append(m.log, msg)
+		if m.next != nil {
+			return m, m.next(msg)
+		}
+	case tea.KeyMsg:
+		m.log = append(m.log, "key "+msg.String())
+		if msg.String() == "q" {
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m logModel) View() string { return strings.Join(m.log, "\n") }
+
+func say(s string) tea.Cmd { return func() tea.Msg { return s } }
+
+func TestBatchMembersRunConcurrently(t *testing.T) {
+	// Run sequentially, the first member would wait forever for the second.
+	ready := make(chan struct{})
+	wait := func() tea.Msg { <-ready; return "waited" }
+	signal := func() tea.Msg { close(ready); return "signalled" }
+
+	h := tuitest.New(t, logModel{init: tea.Batch(wait, signal)})
+	for _, want := range []string{"waited", "signalled"} {
+		if !strings.Contains(h.View(), want) {
+			t.Errorf("view lacks %q:\n%s", want, h.View())
+		}
+	}
+}
+
+func TestSequenceKeepsOrder(t *testing.T) {
+	h := tuitest.New(t, logModel{init: tea.Sequence(say("one"), say("two"), say("three"))})
+	if got, want := h.View(), "one\ntwo\nthree"; got != want {
+		t.Errorf("view = %q, want %q", got, want)
+	}
+}
+
+func TestFollowUpCommandsRun(t *testing.T) {
+	next := func(msg string) tea.Cmd {
+		if msg == "ping" {
+			return say("pong")
+		}
+		return nil
+	}
+	h := tuitest.New(t, logModel{next: next})
+	h.Send("ping")
+	if got, want := h.View(), "ping\npong"; got != want {
+		t.Errorf("view = %q, want %q", got, want)
+	}
+}
+
+func TestKeysAndQuit(t *testing.T) {
+	h := tuitest.New(t, logModel{})
+	h.Press("ctrl+p", "alt+x", "enter").Type("ab")
+	if h.Quit() {
+		t.Fatal("quit before q")
+	}
+	h.Press("q")
+	if !h.Quit() {
+		t.Error("q did not quit")
+	}
+	want := "key ctrl+p\nkey alt+x\nkey enter\nkey a\nkey b\nkey q"
+	if got := h.View(); got != want {
+		t.Errorf("view = %q, want %q", got, want)
+	}
+}
+
+// spinnerModel shows a spinner, which keeps scheduling ticks while running.
+type spinnerModel struct{ s spinner.Model }
+
+func (m spinnerModel) Init() tea.Cmd { return m.s.Tick }
+
+func (m spinnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.s, cmd = m.s.Update(msg)
+	return m, cmd
+}
+
+func (m spinnerModel) View() string { return m.s.View() + " working" }
+
+func TestTicksAreDropped(t *testing.T) {
+	h := tuitest.New(t, spinnerModel{spinner.New(spinner.WithSpinner(spinner.Line))})
+	first := h.View()
+	h.Press("x")
+	if h.View() != first {
+		t.Errorf("spinner moved without input: %q, then %q", first, h.View())
+	}
+	h.Golden("spinner")
+}
+
+func TestAppGolden(t *testing.T) {
+	env := testkit.New(t)
+
+	h := tuitest.App(t, env.Executor)
+	h.Golden("home")
+	h.Press("/").Type("hel").Golden("search_hel")
+	h.Press("esc", "esc")
+
+	h.Press("enter").Golden("category_demo")
+	h.Press("enter").Golden("detail_hello")
+	h.Press("enter")
+	if !strings.Contains(h.View(), "hello, tuitest") {
+		t.Errorf("command output missing after run:\n%s", h.View())
+	}
+	h.Golden("output_hello")
+}